@@ -0,0 +1,259 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/invine/portfolio/internal/adapters"
+	"github.com/invine/portfolio/internal/app/command"
+	"github.com/invine/portfolio/internal/app/query"
+	"github.com/invine/portfolio/internal/domain/portfolio"
+)
+
+func runCommand(db *sql.DB, name string, args []string) error {
+	switch name {
+	case "import-prices":
+		return importPrices(db, args)
+	case "import-fx-rates":
+		return importRates(db, args)
+	case "import-transactions":
+		return importTransactions(db, args)
+	}
+	return fmt.Errorf("unknown command %s", name)
+}
+
+func importPrices(db *sql.DB, args []string) error {
+	fs := flag.NewFlagSet("import-prices", flag.ContinueOnError)
+	source := fs.String("source", "", "source of the prices, file name by default")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: %s import-prices [-source name] file.csv|dir ...\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return fmt.Errorf("import prices: no files given")
+	}
+
+	repo, err := adapters.NewSQLitePriceRepository(db)
+	if err != nil {
+		return fmt.Errorf("import prices: %w", err)
+	}
+	h, err := command.NewImportPricesHandler(repo)
+	if err != nil {
+		return fmt.Errorf("import prices: %w", err)
+	}
+
+	files, err := csvFiles(fs.Args())
+	if err != nil {
+		return fmt.Errorf("import prices: %w", err)
+	}
+	for _, path := range files {
+		src := *source
+		if src == "" {
+			src = filepath.Base(path)
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("import prices: %w", err)
+		}
+		prices, err := adapters.ReadPricesCSV(f, src)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("import prices from %s: %w", path, err)
+		}
+		if err := h.Handle(context.Background(), command.ImportPrices{Prices: prices}); err != nil {
+			return fmt.Errorf("import prices from %s: %w", path, err)
+		}
+		log.Printf("imported %d prices from %s", len(prices), path)
+	}
+	return nil
+}
+
+func importRates(db *sql.DB, args []string) error {
+	fs := flag.NewFlagSet("import-fx-rates", flag.ContinueOnError)
+	source := fs.String("source", "", "source of the rates, file name by default")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: %s import-fx-rates [-source name] file.csv|dir ...\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return fmt.Errorf("import exchange rates: no files given")
+	}
+
+	repo, err := adapters.NewSQLiteRateRepository(db)
+	if err != nil {
+		return fmt.Errorf("import exchange rates: %w", err)
+	}
+	h, err := command.NewImportRatesHandler(repo)
+	if err != nil {
+		return fmt.Errorf("import exchange rates: %w", err)
+	}
+
+	files, err := csvFiles(fs.Args())
+	if err != nil {
+		return fmt.Errorf("import exchange rates: %w", err)
+	}
+	for _, path := range files {
+		src := *source
+		if src == "" {
+			src = filepath.Base(path)
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("import exchange rates: %w", err)
+		}
+		rates, err := adapters.ReadRatesCSV(f, src)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("import exchange rates from %s: %w", path, err)
+		}
+		if err := h.Handle(context.Background(), command.ImportRates{Rates: rates}); err != nil {
+			return fmt.Errorf("import exchange rates from %s: %w", path, err)
+		}
+		log.Printf("imported %d exchange rates from %s", len(rates), path)
+	}
+	return nil
+}
+
+func importTransactions(db *sql.DB, args []string) error {
+	fs := flag.NewFlagSet("import-transactions", flag.ContinueOnError)
+	login := fs.String("user", "", "login or email of the user")
+	portfolioID := fs.String("portfolio", "", "id of the portfolio")
+	format := fs.String("format", "", fmt.Sprintf("format of the file (%s), by its extension by default", strings.Join(adapters.ImportFormats(), ", ")))
+	mappingPath := fs.String("mapping", "", "JSON file with the column mapping of a CSV file")
+	dateFormat := fs.String("date-format", "", "date format of a QIF file, MM/DD/YYYY by default")
+	dryRun := fs.Bool("dry-run", false, "preview the import without recording anything")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: %s import-transactions -user login -portfolio id [-format name] [-mapping file.json] [-date-format format] [-dry-run] file\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 || *login == "" || *portfolioID == "" {
+		fs.Usage()
+		return fmt.Errorf("import transactions: user, portfolio and one file required")
+	}
+	path := fs.Arg(0)
+	id, err := uuid.Parse(*portfolioID)
+	if err != nil {
+		return fmt.Errorf("import transactions: %w", err)
+	}
+
+	options := adapters.ImportOptions{DateFormat: *dateFormat}
+	if *mappingPath != "" {
+		bytes, err := os.ReadFile(*mappingPath)
+		if err != nil {
+			return fmt.Errorf("import transactions: %w", err)
+		}
+		if err := json.Unmarshal(bytes, &options.Mapping); err != nil {
+			return fmt.Errorf("import transactions: incorrect mapping: %w", err)
+		}
+	}
+	if *format == "" {
+		*format = adapters.ImportFormatOf(path)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("import transactions: %w", err)
+	}
+	st, err := adapters.ReadStatement(f, *format, options)
+	f.Close()
+	if err != nil {
+		return fmt.Errorf("import transactions from %s: %w", path, err)
+	}
+
+	userRepo, err := adapters.NewSQLiteUsersRepository(db)
+	if err != nil {
+		return fmt.Errorf("import transactions: %w", err)
+	}
+	portfolioRepo, err := adapters.NewSQLitePortfolioRepository(db)
+	if err != nil {
+		return fmt.Errorf("import transactions: %w", err)
+	}
+	instrumentRepo, err := adapters.NewSQLiteInstrumentRepository(db)
+	if err != nil {
+		return fmt.Errorf("import transactions: %w", err)
+	}
+	preview, err := query.NewImportPreviewHandler(portfolioRepo, instrumentRepo)
+	if err != nil {
+		return fmt.Errorf("import transactions: %w", err)
+	}
+	h, err := command.NewImportTransactionsHandler(portfolioRepo, instrumentRepo)
+	if err != nil {
+		return fmt.Errorf("import transactions: %w", err)
+	}
+
+	ctx := context.Background()
+	u, err := userRepo.GetUserByLoginOrEmail(ctx, *login)
+	if err != nil {
+		return fmt.Errorf("import transactions: %w", err)
+	}
+	ip, err := preview.Handle(ctx, query.ImportPreview{UserID: u.ID(), ID: id, Rows: st.Rows, Positions: st.Positions})
+	if err != nil {
+		return fmt.Errorf("import transactions from %s: %w", path, err)
+	}
+	for _, r := range ip.Rows {
+		switch r.Status {
+		case portfolio.ImportDuplicate:
+			log.Printf("line %d: duplicate of %s", r.Line, r.DuplicateOf.String())
+		case portfolio.ImportRejected:
+			log.Printf("line %d: %v", r.Line, r.Err)
+		}
+	}
+	for _, pc := range ip.Positions {
+		if !pc.Matches() {
+			log.Printf("position of %s on %s: %s in the file, %s in the portfolio", pc.Asset, pc.Date.Format("2006-01-02"), pc.Quantity, pc.Holds)
+		}
+	}
+	if *dryRun {
+		log.Printf("%d rows of %s would be imported, %d duplicates and %d rejected", ip.Accepted, path, ip.Duplicates, ip.Rejected)
+		return nil
+	}
+
+	cmd := command.ImportTransactions{UserID: u.ID(), PortfolioID: id, Transactions: ip.Transactions(), Actions: ip.Actions()}
+	if err := h.Handle(ctx, cmd); err != nil {
+		return fmt.Errorf("import transactions from %s: %w", path, err)
+	}
+	log.Printf("imported %d rows from %s, %d duplicates and %d rejected", ip.Accepted, path, ip.Duplicates, ip.Rejected)
+	return nil
+}
+
+func csvFiles(paths []string) ([]string, error) {
+	files := []string{}
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			if !e.IsDir() && strings.EqualFold(filepath.Ext(e.Name()), ".csv") {
+				files = append(files, filepath.Join(path, e.Name()))
+			}
+		}
+	}
+	return files, nil
+}
@@ -8,5 +8,6 @@ require (
 	github.com/go-chi/cors v1.2.0
 	github.com/google/uuid v1.2.0
 	github.com/mattn/go-sqlite3 v1.14.7
+	github.com/shopspring/decimal v1.3.1
 	golang.org/x/crypto v0.0.0-20210711020723-a769d52b0f97
 )
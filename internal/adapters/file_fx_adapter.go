@@ -0,0 +1,65 @@
+package adapters
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/invine/portfolio/internal/domain/fx"
+	"github.com/shopspring/decimal"
+)
+
+func ReadRatesCSV(reader io.Reader, source string) ([]*fx.Rate, error) {
+	r := csv.NewReader(reader)
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	header, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("can't read exchange rates: can't read header: %w", err)
+	}
+	columns := map[string]int{}
+	for i, h := range header {
+		columns[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	for _, c := range []string{"base", "quote", "date", "rate"} {
+		if _, ok := columns[c]; !ok {
+			return nil, fmt.Errorf("can't read exchange rates: column %s is missing", c)
+		}
+	}
+
+	rates := []*fx.Rate{}
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("can't read exchange rates: %w", err)
+		}
+		field := func(name string) string {
+			i := columns[name]
+			if i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+		pair := field("base") + "/" + field("quote")
+		date, err := time.Parse("2006-01-02", field("date"))
+		if err != nil {
+			return nil, fmt.Errorf("incorrect date of %s: %w", pair, err)
+		}
+		value, err := decimal.NewFromString(field("rate"))
+		if err != nil {
+			return nil, fmt.Errorf("incorrect rate of %s: %w", pair, err)
+		}
+		rate, err := fx.NewRate(field("base"), field("quote"), date, value, source)
+		if err != nil {
+			return nil, fmt.Errorf("incorrect exchange rate of %s: %w", pair, err)
+		}
+		rates = append(rates, rate)
+	}
+	return rates, nil
+}
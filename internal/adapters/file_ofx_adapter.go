@@ -0,0 +1,366 @@
+package adapters
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/invine/portfolio/internal/domain/portfolio"
+	"github.com/shopspring/decimal"
+)
+
+type ofxElement struct {
+	name     string
+	value    string
+	line     int
+	children []*ofxElement
+}
+
+func (e *ofxElement) find(name string) *ofxElement {
+	for _, c := range e.children {
+		if c.name == name {
+			return c
+		}
+		if f := c.find(name); f != nil {
+			return f
+		}
+	}
+	return nil
+}
+
+func (e *ofxElement) findAll(name string) []*ofxElement {
+	res := []*ofxElement{}
+	for _, c := range e.children {
+		if c.name == name {
+			res = append(res, c)
+			continue
+		}
+		res = append(res, c.findAll(name)...)
+	}
+	return res
+}
+
+func (e *ofxElement) text(name string) string {
+	if f := e.find(name); f != nil {
+		return f.value
+	}
+	return ""
+}
+
+func (e *ofxElement) number(name string) (decimal.Decimal, error) {
+	s := e.text(name)
+	if s == "" {
+		return decimal.Zero, nil
+	}
+	// some banks write decimal commas
+	if !strings.Contains(s, ".") {
+		s = strings.Replace(s, ",", ".", 1)
+	}
+	d, err := decimal.NewFromString(s)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("incorrect %s %s", strings.ToLower(name), e.text(name))
+	}
+	return d, nil
+}
+
+func (e *ofxElement) date(name string) (time.Time, error) {
+	s := e.text(name)
+	if len(s) < 8 {
+		return time.Time{}, fmt.Errorf("incorrect %s %s", strings.ToLower(name), s)
+	}
+	d, err := time.Parse("20060102", s[:8])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("incorrect %s %s", strings.ToLower(name), s)
+	}
+	return d, nil
+}
+
+func parseOFX(r io.Reader) (*ofxElement, error) {
+	bytes, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	s := string(bytes)
+	root := &ofxElement{}
+	stack := []*ofxElement{root}
+	line := 1
+	for {
+		i := strings.IndexByte(s, '<')
+		if i < 0 {
+			break
+		}
+		text := strings.TrimSpace(s[:i])
+		line += strings.Count(s[:i], "\n")
+		top := stack[len(stack)-1]
+		if text != "" && top != root && len(top.children) == 0 && top.value == "" {
+			top.value = ofxUnescape(text)
+			stack = stack[:len(stack)-1]
+		}
+		j := strings.IndexByte(s[i:], '>')
+		if j < 0 {
+			return nil, fmt.Errorf("line %d: unclosed tag", line)
+		}
+		tag := strings.TrimSpace(s[i+1 : i+j])
+		line += strings.Count(s[i:i+j], "\n")
+		s = s[i+j+1:]
+		switch {
+		case tag == "" || tag[0] == '?' || tag[0] == '!':
+		case tag[0] == '/':
+			name := strings.ToUpper(strings.TrimSpace(tag[1:]))
+			for k := len(stack) - 1; k > 0; k-- {
+				if stack[k].name == name {
+					stack = stack[:k]
+					break
+				}
+			}
+		default:
+			e := &ofxElement{name: strings.ToUpper(strings.Fields(tag)[0]), line: line}
+			top := stack[len(stack)-1]
+			top.children = append(top.children, e)
+			stack = append(stack, e)
+		}
+	}
+	if root.find("OFX") == nil {
+		return nil, fmt.Errorf("OFX element is missing")
+	}
+	return root, nil
+}
+
+var ofxEntities = strings.NewReplacer("&lt;", "<", "&gt;", ">", "&quot;", "\"", "&apos;", "'", "&nbsp;", " ", "&amp;", "&")
+
+func ofxUnescape(s string) string {
+	return ofxEntities.Replace(s)
+}
+
+type ofxStatement struct {
+	securities map[string]string
+	currency   string
+}
+
+func ReadOFX(r io.Reader, options ImportOptions) (*portfolio.Statement, error) {
+	root, err := parseOFX(r)
+	if err != nil {
+		return nil, fmt.Errorf("can't read OFX: %w", err)
+	}
+	securities := map[string]string{}
+	for _, info := range root.findAll("SECINFO") {
+		id := info.text("UNIQUEID")
+		if ticker := info.text("TICKER"); id != "" && ticker != "" {
+			securities[id] = ticker
+		}
+	}
+
+	res := &portfolio.Statement{Rows: []portfolio.ImportRow{}, Positions: []portfolio.StatementPosition{}}
+	for _, name := range []string{"INVSTMTRS", "STMTRS", "CCSTMTRS"} {
+		for _, stmt := range root.findAll(name) {
+			st := ofxStatement{securities: securities, currency: strings.ToUpper(stmt.text("CURDEF"))}
+			if list := stmt.find("INVTRANLIST"); list != nil {
+				for _, e := range list.children {
+					if len(e.children) > 0 {
+						res.Rows = append(res.Rows, st.investmentRows(e)...)
+					}
+				}
+			}
+			if list := stmt.find("BANKTRANLIST"); list != nil {
+				for _, e := range list.findAll("STMTTRN") {
+					res.Rows = append(res.Rows, st.cashRow(e))
+				}
+			}
+			if list := stmt.find("INVPOSLIST"); list != nil {
+				positions, err := st.positions(stmt, list)
+				if err != nil {
+					return nil, fmt.Errorf("can't read OFX: %w", err)
+				}
+				res.Positions = append(res.Positions, positions...)
+			}
+		}
+	}
+	return res, nil
+}
+
+func (st ofxStatement) asset(e *ofxElement) string {
+	id := e.text("UNIQUEID")
+	if ticker, ok := st.securities[id]; ok {
+		return ticker
+	}
+	return id
+}
+
+func (st ofxStatement) currencyOf(e *ofxElement) string {
+	if c := e.find("CURRENCY"); c != nil && c.text("CURSYM") != "" {
+		return strings.ToUpper(c.text("CURSYM"))
+	}
+	if c := e.find("ORIGCURRENCY"); c != nil && c.text("CURSYM") != "" {
+		return strings.ToUpper(c.text("CURSYM"))
+	}
+	return st.currency
+}
+
+func (st ofxStatement) investmentRows(e *ofxElement) []portfolio.ImportRow {
+	recs, action, err := st.investmentRecords(e)
+	if err != nil {
+		return []portfolio.ImportRow{{Line: e.line, Err: err}}
+	}
+	if action != nil {
+		return []portfolio.ImportRow{{Line: e.line, Action: action}}
+	}
+	rows := []portfolio.ImportRow{}
+	for _, rec := range recs {
+		t, err := rec.Transaction(uuid.New())
+		rows = append(rows, portfolio.ImportRow{Line: e.line, Transaction: t, Err: err})
+	}
+	return rows
+}
+
+func (st ofxStatement) investmentRecords(e *ofxElement) ([]portfolio.ImportRecord, *portfolio.CorporateAction, error) {
+	// cash transactions of investment accounts are dated the way the ones of bank accounts are
+	if e.name == "INVBANKTRAN" {
+		rec, err := st.cashRecord(e)
+		if err != nil {
+			return nil, nil, err
+		}
+		return []portfolio.ImportRecord{rec}, nil, nil
+	}
+	date, err := e.date("DTTRADE")
+	if err != nil {
+		return nil, nil, err
+	}
+	rec := portfolio.ImportRecord{Asset: st.asset(e), Date: date, Currency: st.currencyOf(e)}
+	numbers := map[string]*decimal.Decimal{}
+	read := func(names ...string) error {
+		for _, n := range names {
+			d, err := e.number(n)
+			if err != nil {
+				return err
+			}
+			numbers[n] = &d
+		}
+		return nil
+	}
+	if err := read("UNITS", "UNITPRICE", "COMMISSION", "FEES", "TAXES", "WITHHOLDING", "TOTAL", "ACCRDINT", "NUMERATOR", "DENOMINATOR"); err != nil {
+		return nil, nil, err
+	}
+	num := func(n string) decimal.Decimal { return *numbers[n] }
+
+	name := e.name
+	switch {
+	case strings.HasPrefix(name, "BUY") || strings.HasPrefix(name, "SELL"):
+		rec.Kind = portfolio.Buy
+		if strings.HasPrefix(name, "SELL") {
+			rec.Kind = portfolio.Sell
+		}
+		rec.Quantity, rec.Price = num("UNITS"), num("UNITPRICE")
+		rec.Fee, rec.Accrued = num("COMMISSION"), num("ACCRDINT")
+		rec.Fees = []portfolio.Fee{{Type: portfolio.OtherFee, Amount: num("FEES")}, {Type: portfolio.TransactionTax, Amount: num("TAXES")}}
+		return []portfolio.ImportRecord{rec}, nil, nil
+	case name == "INCOME":
+		kind, err := ofxIncomeKind(e.text("INCOMETYPE"))
+		if err != nil {
+			return nil, nil, err
+		}
+		rec.Kind, rec.Amount = kind, num("TOTAL")
+		rec.Fees = []portfolio.Fee{{Type: portfolio.WithholdingTax, Amount: num("WITHHOLDING").Add(num("TAXES"))}}
+		return []portfolio.ImportRecord{rec}, nil, nil
+	case name == "REINVEST":
+		kind, err := ofxIncomeKind(e.text("INCOMETYPE"))
+		if err != nil {
+			return nil, nil, err
+		}
+		income := rec
+		income.Kind, income.Amount = kind, num("TOTAL")
+		income.Fees = []portfolio.Fee{{Type: portfolio.WithholdingTax, Amount: num("TAXES")}}
+		buy := rec
+		buy.Kind, buy.Quantity, buy.Price = portfolio.Buy, num("UNITS"), num("UNITPRICE")
+		buy.Fee = num("COMMISSION")
+		buy.Fees = []portfolio.Fee{{Type: portfolio.OtherFee, Amount: num("FEES")}}
+		return []portfolio.ImportRecord{income, buy}, nil, nil
+	case name == "INVEXPENSE":
+		rec.Kind, rec.Amount = portfolio.AccountFee, num("TOTAL")
+		return []portfolio.ImportRecord{rec}, nil, nil
+	case name == "MARGININTEREST":
+		rec.Kind, rec.Asset, rec.Amount = portfolio.AccountFee, "", num("TOTAL")
+		return []portfolio.ImportRecord{rec}, nil, nil
+	case name == "TRANSFER":
+		rec.Kind, rec.Quantity = portfolio.TransferIn, num("UNITS")
+		if strings.EqualFold(e.text("TFERACTION"), "OUT") {
+			rec.Kind = portfolio.TransferOut
+		}
+		return []portfolio.ImportRecord{rec}, nil, nil
+	case name == "SPLIT":
+		from, to, err := splitRatio(num("NUMERATOR"), num("DENOMINATOR"))
+		if err != nil {
+			return nil, nil, err
+		}
+		a, err := portfolio.NewSplit(uuid.New(), date, rec.Asset, from, to, decimal.Zero)
+		if err != nil {
+			return nil, nil, err
+		}
+		return nil, a, nil
+	}
+	return nil, nil, fmt.Errorf("can't import transaction: %s can't be imported", strings.ToLower(name))
+}
+
+func ofxIncomeKind(incomeType string) (portfolio.Kind, error) {
+	switch strings.ToUpper(incomeType) {
+	case "DIV":
+		return portfolio.Dividend, nil
+	case "INTEREST":
+		return portfolio.Interest, nil
+	case "CGLONG", "CGSHORT":
+		return portfolio.CapitalGain, nil
+	}
+	return "", fmt.Errorf("can't import transaction: %s income can't be imported", strings.ToLower(incomeType))
+}
+
+func (st ofxStatement) cashRow(e *ofxElement) portfolio.ImportRow {
+	rec, err := st.cashRecord(e)
+	if err != nil {
+		return portfolio.ImportRow{Line: e.line, Err: err}
+	}
+	t, err := rec.Transaction(uuid.New())
+	return portfolio.ImportRow{Line: e.line, Transaction: t, Err: err}
+}
+
+func (st ofxStatement) cashRecord(e *ofxElement) (portfolio.ImportRecord, error) {
+	date, err := e.date("DTPOSTED")
+	if err != nil {
+		return portfolio.ImportRecord{}, err
+	}
+	amount, err := e.number("TRNAMT")
+	if err != nil {
+		return portfolio.ImportRecord{}, err
+	}
+	rec := portfolio.ImportRecord{Date: date, Amount: amount, Currency: st.currencyOf(e), Kind: portfolio.Deposit}
+	switch {
+	case strings.EqualFold(e.text("TRNTYPE"), "INT") && amount.IsPositive():
+		rec.Kind = portfolio.Interest
+	case strings.EqualFold(e.text("TRNTYPE"), "FEE") || strings.EqualFold(e.text("TRNTYPE"), "SRVCHG") || strings.EqualFold(e.text("TRNTYPE"), "INT"):
+		rec.Kind = portfolio.AccountFee
+	case amount.IsNegative():
+		rec.Kind = portfolio.Withdrawal
+	}
+	return rec, nil
+}
+
+func (st ofxStatement) positions(stmt, list *ofxElement) ([]portfolio.StatementPosition, error) {
+	res := []portfolio.StatementPosition{}
+	for _, pos := range list.findAll("INVPOS") {
+		date, err := stmt.date("DTASOF")
+		if err != nil {
+			if date, err = pos.date("DTPRICEASOF"); err != nil {
+				return nil, err
+			}
+		}
+		units, err := pos.number("UNITS")
+		if err != nil {
+			return nil, err
+		}
+		if strings.EqualFold(pos.text("POSTYPE"), "SHORT") && units.IsPositive() {
+			units = units.Neg()
+		}
+		res = append(res, portfolio.StatementPosition{Asset: st.asset(pos), Date: date, Quantity: units})
+	}
+	return res, nil
+}
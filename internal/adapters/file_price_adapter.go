@@ -0,0 +1,206 @@
+package adapters
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/invine/portfolio/internal/domain/price"
+	"github.com/shopspring/decimal"
+)
+
+type FilePriceProvider struct {
+	*MemoryPriceProvider
+	dir string
+}
+
+type filePriceModel struct {
+	Asset         string          `json:"asset"`
+	Date          string          `json:"date"`
+	Close         decimal.Decimal `json:"close"`
+	PreviousClose decimal.Decimal `json:"previousClose"`
+	Currency      string          `json:"currency"`
+	Source        string          `json:"-"`
+}
+
+func NewFilePriceProvider(dir string) (*FilePriceProvider, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("prices directory required")
+	}
+	f := &FilePriceProvider{
+		MemoryPriceProvider: NewMemoryPriceProvider(nil),
+		dir:                 dir,
+	}
+	if err := f.Reload(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *FilePriceProvider) Reload() error {
+	entries, err := os.ReadDir(f.dir)
+	if err != nil {
+		return fmt.Errorf("can't read prices directory %s: %w", f.dir, err)
+	}
+
+	pms := []filePriceModel{}
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		path := filepath.Join(f.dir, e.Name())
+		var ms []filePriceModel
+		switch strings.ToLower(filepath.Ext(e.Name())) {
+		case ".csv":
+			ms, err = readCSVPrices(path)
+		case ".json":
+			ms, err = readJSONPrices(path)
+		default:
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("can't read prices file %s: %w", path, err)
+		}
+		for i := range ms {
+			ms[i].Source = e.Name()
+		}
+		pms = append(pms, ms...)
+	}
+
+	prices, err := filePriceModelsToPrices(pms)
+	if err != nil {
+		return fmt.Errorf("can't read prices directory %s: %w", f.dir, err)
+	}
+
+	f.reset(prices)
+	return nil
+}
+
+func ReadPricesCSV(r io.Reader, source string) ([]*price.Price, error) {
+	pms, err := readCSVPriceModels(r)
+	if err != nil {
+		return nil, fmt.Errorf("can't read prices: %w", err)
+	}
+	prices := []*price.Price{}
+	for _, pm := range pms {
+		date, err := time.Parse("2006-01-02", pm.Date)
+		if err != nil {
+			return nil, fmt.Errorf("incorrect date of %s: %w", pm.Asset, err)
+		}
+		p, err := price.NewPrice(pm.Asset, date, pm.Close, pm.PreviousClose, pm.Currency, source)
+		if err != nil {
+			return nil, fmt.Errorf("incorrect price of %s: %w", pm.Asset, err)
+		}
+		prices = append(prices, p)
+	}
+	return prices, nil
+}
+
+func readCSVPrices(path string) ([]filePriceModel, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return readCSVPriceModels(file)
+}
+
+func readCSVPriceModels(reader io.Reader) ([]filePriceModel, error) {
+	r := csv.NewReader(reader)
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	header, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("can't read header: %w", err)
+	}
+	columns := map[string]int{}
+	for i, h := range header {
+		columns[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	for _, c := range []string{"asset", "date", "close"} {
+		if _, ok := columns[c]; !ok {
+			return nil, fmt.Errorf("column %s is missing", c)
+		}
+	}
+
+	pms := []filePriceModel{}
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		field := func(name string) string {
+			i, ok := columns[name]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+		close, err := decimal.NewFromString(field("close"))
+		if err != nil {
+			return nil, fmt.Errorf("incorrect close %s: %w", field("close"), err)
+		}
+		pm := filePriceModel{Asset: field("asset"), Date: field("date"), Close: close, Currency: field("currency")}
+		if pc := field("previous_close"); pc != "" {
+			pm.PreviousClose, err = decimal.NewFromString(pc)
+			if err != nil {
+				return nil, fmt.Errorf("incorrect previous close %s: %w", pc, err)
+			}
+		}
+		pms = append(pms, pm)
+	}
+	return pms, nil
+}
+
+func readJSONPrices(path string) ([]filePriceModel, error) {
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pms := []filePriceModel{}
+	if err := json.Unmarshal(bytes, &pms); err != nil {
+		return nil, err
+	}
+	return pms, nil
+}
+
+func filePriceModelsToPrices(pms []filePriceModel) ([]*price.Price, error) {
+	type row struct {
+		date time.Time
+		pm   filePriceModel
+	}
+	byAsset := map[string][]row{}
+	for _, pm := range pms {
+		date, err := time.Parse("2006-01-02", pm.Date)
+		if err != nil {
+			return nil, fmt.Errorf("incorrect date of %s: %w", pm.Asset, err)
+		}
+		byAsset[pm.Asset] = append(byAsset[pm.Asset], row{date: date, pm: pm})
+	}
+
+	prices := []*price.Price{}
+	for asset, rows := range byAsset {
+		sort.Slice(rows, func(i, j int) bool { return rows[i].date.Before(rows[j].date) })
+		last := rows[len(rows)-1]
+		previousClose := last.pm.PreviousClose
+		if previousClose.IsZero() && len(rows) > 1 {
+			previousClose = rows[len(rows)-2].pm.Close
+		}
+		p, err := price.NewPrice(asset, last.date, last.pm.Close, previousClose, last.pm.Currency, last.pm.Source)
+		if err != nil {
+			return nil, fmt.Errorf("incorrect price of %s: %w", asset, err)
+		}
+		prices = append(prices, p)
+	}
+	return prices, nil
+}
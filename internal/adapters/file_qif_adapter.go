@@ -0,0 +1,282 @@
+package adapters
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/invine/portfolio/internal/domain/portfolio"
+	"github.com/shopspring/decimal"
+)
+
+type qifRecord struct {
+	section string
+	line    int
+	fields  map[byte]string
+}
+
+func (r qifRecord) number(code byte) (decimal.Decimal, error) {
+	s := strings.ReplaceAll(strings.TrimSpace(r.fields[code]), ",", "")
+	if s == "" {
+		return decimal.Zero, nil
+	}
+	d, err := decimal.NewFromString(s)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("incorrect number %s", r.fields[code])
+	}
+	return d, nil
+}
+
+func parseQIF(r io.Reader) ([]qifRecord, error) {
+	res := []qifRecord{}
+	section := ""
+	var rec *qifRecord
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if line == 1 {
+			text = strings.TrimPrefix(text, "\ufeff")
+		}
+		switch {
+		case strings.TrimSpace(text) == "":
+		case strings.HasPrefix(text, "!"):
+			if strings.HasPrefix(strings.ToLower(text), "!type:") {
+				section = strings.ToLower(strings.TrimSpace(text[len("!type:"):]))
+			} else if strings.EqualFold(strings.TrimSpace(text), "!account") {
+				section = "account"
+			}
+			rec = nil
+		case strings.HasPrefix(text, "^"):
+			if rec != nil {
+				res = append(res, *rec)
+			}
+			rec = nil
+		default:
+			if rec == nil {
+				rec = &qifRecord{section: section, line: line, fields: map[byte]string{}}
+			}
+			// repeated codes, such as the splits of a bank transaction, keep the first value
+			if _, ok := rec.fields[text[0]]; !ok {
+				rec.fields[text[0]] = strings.TrimSpace(text[1:])
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if rec != nil {
+		res = append(res, *rec)
+	}
+	return res, nil
+}
+
+func ReadQIF(r io.Reader, options ImportOptions) (*portfolio.Statement, error) {
+	records, err := parseQIF(r)
+	if err != nil {
+		return nil, fmt.Errorf("can't read QIF: %w", err)
+	}
+	symbols := map[string]string{}
+	for _, rec := range records {
+		if rec.section == "security" && rec.fields['N'] != "" && rec.fields['S'] != "" {
+			symbols[rec.fields['N']] = rec.fields['S']
+		}
+	}
+
+	q := qifReader{symbols: symbols, dateFormat: strings.ToUpper(options.DateFormat)}
+	res := &portfolio.Statement{Rows: []portfolio.ImportRow{}, Positions: []portfolio.StatementPosition{}}
+	for _, rec := range records {
+		switch rec.section {
+		case "invst":
+			res.Rows = append(res.Rows, q.investmentRows(rec)...)
+		case "bank", "cash", "ccard", "oth a", "oth l":
+			res.Rows = append(res.Rows, q.cashRow(rec))
+		}
+	}
+	return res, nil
+}
+
+type qifReader struct {
+	symbols    map[string]string
+	dateFormat string
+}
+
+func (q qifReader) date(s string) (time.Time, error) {
+	apostrophe := strings.Contains(s, "'")
+	parts := strings.FieldsFunc(s, func(r rune) bool { return r < '0' || r > '9' })
+	if len(parts) != 3 {
+		return time.Time{}, fmt.Errorf("incorrect date %s", s)
+	}
+	n := [3]int{}
+	for i, p := range parts {
+		v, err := strconv.Atoi(p)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("incorrect date %s", s)
+		}
+		n[i] = v
+	}
+	var y, m, d int
+	switch {
+	case strings.HasPrefix(q.dateFormat, "DD"):
+		d, m, y = n[0], n[1], n[2]
+	case strings.HasPrefix(q.dateFormat, "YY"):
+		y, m, d = n[0], n[1], n[2]
+	default:
+		m, d, y = n[0], n[1], n[2]
+	}
+	switch {
+	case y >= 100:
+	case apostrophe || y < 70:
+		y += 2000
+	default:
+		y += 1900
+	}
+	date := time.Date(y, time.Month(m), d, 0, 0, 0, 0, time.UTC)
+	if date.Month() != time.Month(m) || date.Day() != d {
+		return time.Time{}, fmt.Errorf("incorrect date %s", s)
+	}
+	return date, nil
+}
+
+var qifIncomes = map[string]portfolio.Kind{
+	"div":     portfolio.Dividend,
+	"intinc":  portfolio.Interest,
+	"cglong":  portfolio.CapitalGain,
+	"cgmid":   portfolio.CapitalGain,
+	"cgshort": portfolio.CapitalGain,
+}
+
+var qifReinvestments = map[string]portfolio.Kind{
+	"reinvdiv": portfolio.Dividend,
+	"reinvint": portfolio.Interest,
+	"reinvlg":  portfolio.CapitalGain,
+	"reinvmd":  portfolio.CapitalGain,
+	"reinvsh":  portfolio.CapitalGain,
+}
+
+func (q qifReader) investmentRows(rec qifRecord) []portfolio.ImportRow {
+	recs, action, err := q.investmentRecords(rec)
+	if err != nil {
+		return []portfolio.ImportRow{{Line: rec.line, Err: err}}
+	}
+	if action != nil {
+		return []portfolio.ImportRow{{Line: rec.line, Action: action}}
+	}
+	rows := []portfolio.ImportRow{}
+	for _, r := range recs {
+		t, err := r.Transaction(uuid.New())
+		rows = append(rows, portfolio.ImportRow{Line: rec.line, Transaction: t, Err: err})
+	}
+	return rows
+}
+
+func (q qifReader) investmentRecords(rec qifRecord) ([]portfolio.ImportRecord, *portfolio.CorporateAction, error) {
+	date, err := q.date(rec.fields['D'])
+	if err != nil {
+		return nil, nil, err
+	}
+	asset := rec.fields['Y']
+	if symbol, ok := q.symbols[asset]; ok {
+		asset = symbol
+	}
+	numbers := map[byte]decimal.Decimal{}
+	for _, code := range []byte{'Q', 'I', 'T', 'U', 'O'} {
+		if numbers[code], err = rec.number(code); err != nil {
+			return nil, nil, err
+		}
+	}
+	quantity, price, commission := numbers['Q'].Abs(), numbers['I'], numbers['O']
+	total := numbers['T']
+	if total.IsZero() {
+		total = numbers['U']
+	}
+	base := portfolio.ImportRecord{Asset: asset, Date: date}
+
+	action := strings.ToLower(rec.fields['N'])
+	switch action {
+	case "buy", "buyx", "cvrshrt", "cvrshrtx", "sell", "sellx", "shtsell", "shtsellx":
+		trade := base
+		trade.Kind, trade.Quantity, trade.Price, trade.Fee = portfolio.Sell, quantity, price, commission
+		if strings.HasPrefix(action, "buy") || strings.HasPrefix(action, "cvrshrt") {
+			trade.Kind = portfolio.Buy
+		}
+		// the price is left out when it follows from the total
+		if price.IsZero() && quantity.IsPositive() {
+			net := total.Abs().Sub(commission)
+			if trade.Kind == portfolio.Sell {
+				net = total.Abs().Add(commission)
+			}
+			trade.Price = net.Div(quantity)
+		}
+		return []portfolio.ImportRecord{trade}, nil, nil
+	case "miscexp", "miscexpx", "margint", "margintx":
+		fee := base
+		fee.Kind, fee.Amount = portfolio.AccountFee, total
+		if strings.HasPrefix(action, "margint") {
+			fee.Asset = ""
+		}
+		return []portfolio.ImportRecord{fee}, nil, nil
+	case "xin", "contribx", "cash", "xout", "withdrwx":
+		flow := portfolio.ImportRecord{Date: date, Kind: portfolio.Deposit, Amount: total}
+		if action == "xout" || action == "withdrwx" || action == "cash" && total.IsNegative() {
+			flow.Kind = portfolio.Withdrawal
+		}
+		return []portfolio.ImportRecord{flow}, nil, nil
+	case "shrsin", "shrsout":
+		transfer := base
+		transfer.Kind, transfer.Quantity, transfer.Price = portfolio.TransferIn, quantity, price
+		if action == "shrsout" {
+			transfer.Kind = portfolio.TransferOut
+		}
+		return []portfolio.ImportRecord{transfer}, nil, nil
+	case "stksplit":
+		from, to, err := splitRatio(quantity, decimal.NewFromInt(10))
+		if err != nil {
+			return nil, nil, err
+		}
+		a, err := portfolio.NewSplit(uuid.New(), date, asset, from, to, decimal.Zero)
+		if err != nil {
+			return nil, nil, err
+		}
+		return nil, a, nil
+	}
+	if kind, ok := qifIncomes[strings.TrimSuffix(action, "x")]; ok {
+		income := base
+		income.Kind, income.Amount = kind, total
+		return []portfolio.ImportRecord{income}, nil, nil
+	}
+	if kind, ok := qifReinvestments[action]; ok {
+		income := base
+		income.Kind, income.Amount = kind, total
+		buy := base
+		buy.Kind, buy.Quantity, buy.Price, buy.Fee = portfolio.Buy, quantity, price, commission
+		if price.IsZero() && quantity.IsPositive() {
+			buy.Price = total.Abs().Sub(commission).Div(quantity)
+		}
+		return []portfolio.ImportRecord{income, buy}, nil, nil
+	}
+	return nil, nil, fmt.Errorf("can't import transaction: %s can't be imported", rec.fields['N'])
+}
+
+func (q qifReader) cashRow(rec qifRecord) portfolio.ImportRow {
+	date, err := q.date(rec.fields['D'])
+	if err != nil {
+		return portfolio.ImportRow{Line: rec.line, Err: err}
+	}
+	amount, err := rec.number('T')
+	if err == nil && amount.IsZero() {
+		amount, err = rec.number('U')
+	}
+	if err != nil {
+		return portfolio.ImportRow{Line: rec.line, Err: err}
+	}
+	r := portfolio.ImportRecord{Date: date, Kind: portfolio.Deposit, Amount: amount}
+	if amount.IsNegative() {
+		r.Kind = portfolio.Withdrawal
+	}
+	t, err := r.Transaction(uuid.New())
+	return portfolio.ImportRow{Line: rec.line, Transaction: t, Err: err}
+}
@@ -0,0 +1,279 @@
+package adapters
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/invine/portfolio/internal/domain/portfolio"
+	"github.com/shopspring/decimal"
+)
+
+type ImportOptions struct {
+	Mapping    CSVMapping `json:"mapping"`
+	DateFormat string     `json:"dateFormat"`
+}
+
+type TransactionImporter func(r io.Reader, options ImportOptions) (*portfolio.Statement, error)
+
+type importer struct {
+	read       TransactionImporter
+	extensions []string
+}
+
+var importers = map[string]importer{
+	"csv": {read: readCSVStatement, extensions: []string{".csv"}},
+	"ofx": {read: ReadOFX, extensions: []string{".ofx"}},
+	"qfx": {read: ReadOFX, extensions: []string{".qfx"}},
+	"qif": {read: ReadQIF, extensions: []string{".qif"}},
+}
+
+func RegisterImporter(format string, extensions []string, read TransactionImporter) {
+	importers[strings.ToLower(format)] = importer{read: read, extensions: extensions}
+}
+
+func ImportFormats() []string {
+	res := []string{}
+	for f := range importers {
+		res = append(res, f)
+	}
+	sort.Strings(res)
+	return res
+}
+
+func ImportFormatOf(filename string) string {
+	ext := strings.ToLower(filepath.Ext(filename))
+	for f, im := range importers {
+		for _, e := range im.extensions {
+			if strings.ToLower(e) == ext {
+				return f
+			}
+		}
+	}
+	return ""
+}
+
+func ReadStatement(r io.Reader, format string, options ImportOptions) (*portfolio.Statement, error) {
+	im, ok := importers[strings.ToLower(format)]
+	if !ok {
+		return nil, fmt.Errorf("unknown import format %q", format)
+	}
+	return im.read(r, options)
+}
+
+func readCSVStatement(r io.Reader, options ImportOptions) (*portfolio.Statement, error) {
+	rows, err := ReadTransactionsCSV(r, options.Mapping)
+	if err != nil {
+		return nil, err
+	}
+	return &portfolio.Statement{Rows: rows}, nil
+}
+
+type CSVMapping struct {
+	Date               string            `json:"date"`
+	Kind               string            `json:"kind"`
+	Asset              string            `json:"asset"`
+	Quantity           string            `json:"quantity"`
+	Price              string            `json:"price"`
+	Amount             string            `json:"amount"`
+	Currency           string            `json:"currency"`
+	Fee                string            `json:"fee"`
+	DateFormat         string            `json:"dateFormat"`
+	DecimalSeparator   string            `json:"decimalSeparator"`
+	ThousandsSeparator string            `json:"thousandsSeparator"`
+	Delimiter          string            `json:"delimiter"`
+	Kinds              map[string]string `json:"kinds"`
+}
+
+var dateFormatTokens = strings.NewReplacer("YYYY", "2006", "YY", "06", "MM", "01", "DD", "02", "hh", "15", "mm", "04", "ss", "05")
+
+func (m CSVMapping) layout() string {
+	if m.DateFormat == "" {
+		return "2006-01-02"
+	}
+	return dateFormatTokens.Replace(m.DateFormat)
+}
+
+func (m CSVMapping) validate() error {
+	if m.Date == "" {
+		return fmt.Errorf("date column is required")
+	}
+	if m.Quantity == "" && m.Amount == "" {
+		return fmt.Errorf("quantity or amount column is required")
+	}
+	if m.Quantity != "" && m.Price == "" {
+		return fmt.Errorf("price column is required along with quantity")
+	}
+	for _, s := range []string{m.Delimiter, m.DecimalSeparator, m.ThousandsSeparator} {
+		if utf8.RuneCountInString(s) > 1 {
+			return fmt.Errorf("separator %q must be a single character", s)
+		}
+	}
+	if m.DecimalSeparator != "" && m.DecimalSeparator == m.ThousandsSeparator {
+		return fmt.Errorf("decimal and thousands separators must differ")
+	}
+	for label, k := range m.Kinds {
+		if _, err := portfolio.ParseKind(k); err != nil {
+			return fmt.Errorf("incorrect kind of label %s: %w", label, err)
+		}
+	}
+	return nil
+}
+
+func (m CSVMapping) kind(label string) (portfolio.Kind, error) {
+	if label == "" {
+		return "", nil
+	}
+	for l, k := range m.Kinds {
+		if strings.EqualFold(strings.TrimSpace(l), label) {
+			return portfolio.Kind(k), nil
+		}
+	}
+	return portfolio.ParseKind(strings.ToLower(label))
+}
+
+func (m CSVMapping) number(s string) (decimal.Decimal, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return decimal.Zero, nil
+	}
+	negative := strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")")
+	if negative {
+		s = strings.TrimSpace(s[1 : len(s)-1])
+	}
+	if m.ThousandsSeparator != "" {
+		s = strings.ReplaceAll(s, m.ThousandsSeparator, "")
+	}
+	if m.DecimalSeparator != "" && m.DecimalSeparator != "." {
+		s = strings.ReplaceAll(s, m.DecimalSeparator, ".")
+	}
+	d, err := decimal.NewFromString(s)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	if negative {
+		d = d.Neg()
+	}
+	return d, nil
+}
+
+func ReadTransactionsCSV(reader io.Reader, m CSVMapping) ([]portfolio.ImportRow, error) {
+	if err := m.validate(); err != nil {
+		return nil, fmt.Errorf("incorrect mapping: %w", err)
+	}
+	r := csv.NewReader(reader)
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+	if m.Delimiter != "" {
+		r.Comma, _ = utf8.DecodeRuneInString(m.Delimiter)
+	}
+
+	header, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("can't read header: %w", err)
+	}
+	columns := map[string]int{}
+	for i, h := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))] = i
+	}
+	for _, c := range []string{m.Date, m.Kind, m.Asset, m.Quantity, m.Price, m.Amount, m.Currency, m.Fee} {
+		if _, ok := columns[strings.ToLower(c)]; c != "" && !ok {
+			return nil, fmt.Errorf("column %s is missing", c)
+		}
+	}
+
+	rows := []portfolio.ImportRow{}
+	for line := 2; ; line++ {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if _, ok := err.(*csv.ParseError); ok {
+				rows = append(rows, portfolio.ImportRow{Line: line, Err: err})
+				continue
+			}
+			return nil, fmt.Errorf("can't read transactions: %w", err)
+		}
+		if len(record) == 1 && strings.TrimSpace(record[0]) == "" {
+			continue
+		}
+		t, err := m.transaction(record, columns)
+		rows = append(rows, portfolio.ImportRow{Line: line, Transaction: t, Err: err})
+	}
+	return rows, nil
+}
+
+func (m CSVMapping) transaction(record []string, columns map[string]int) (*portfolio.Transaction, error) {
+	field := func(name string) string {
+		i, ok := columns[strings.ToLower(name)]
+		if name == "" || !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+	number := func(what, name string) (decimal.Decimal, error) {
+		d, err := m.number(field(name))
+		if err != nil {
+			return decimal.Zero, fmt.Errorf("incorrect %s %s", what, field(name))
+		}
+		return d, nil
+	}
+
+	date, err := time.Parse(m.layout(), field(m.Date))
+	if err != nil {
+		return nil, fmt.Errorf("incorrect date %s: %w", field(m.Date), err)
+	}
+	kind, err := m.kind(field(m.Kind))
+	if err != nil {
+		return nil, err
+	}
+	rec := portfolio.ImportRecord{
+		Kind:     kind,
+		Asset:    field(m.Asset),
+		Date:     date,
+		Currency: strings.ToUpper(field(m.Currency)),
+	}
+	if rec.Quantity, err = number("quantity", m.Quantity); err != nil {
+		return nil, err
+	}
+	if rec.Price, err = number("price", m.Price); err != nil {
+		return nil, err
+	}
+	if rec.Amount, err = number("amount", m.Amount); err != nil {
+		return nil, err
+	}
+	if rec.Fee, err = number("fee", m.Fee); err != nil {
+		return nil, err
+	}
+	return rec.Transaction(uuid.New())
+}
+
+func splitRatio(numerator, denominator decimal.Decimal) (from, to int, err error) {
+	if !numerator.IsPositive() || !denominator.IsPositive() {
+		return 0, 0, fmt.Errorf("incorrect split ratio %s:%s", numerator, denominator)
+	}
+	for i := 0; i < 8 && !(numerator.IsInteger() && denominator.IsInteger()); i++ {
+		numerator, denominator = numerator.Shift(1), denominator.Shift(1)
+	}
+	if !numerator.IsInteger() || !denominator.IsInteger() || numerator.IntPart() > math.MaxInt32 || denominator.IntPart() > math.MaxInt32 {
+		return 0, 0, fmt.Errorf("incorrect split ratio %s:%s", numerator, denominator)
+	}
+	n, d := numerator.IntPart(), denominator.IntPart()
+	g := gcd(n, d)
+	return int(d / g), int(n / g), nil
+}
+
+func gcd(a, b int64) int64 {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
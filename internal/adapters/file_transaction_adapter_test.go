@@ -0,0 +1,168 @@
+package adapters
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/invine/portfolio/internal/domain/portfolio"
+	"github.com/shopspring/decimal"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files of the tests")
+
+func TestReadStatement(t *testing.T) {
+	tests := []struct {
+		file    string
+		format  string
+		options ImportOptions
+	}{
+		{file: "broker.ofx", format: "ofx"},
+		{file: "statement.qfx", format: "qfx"},
+		{file: "quicken.qif", format: "qif"},
+		{file: "european.qif", format: "qif", options: ImportOptions{DateFormat: "DD/MM/YY"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			f, err := os.Open(filepath.Join("testdata", tt.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			st, err := ReadStatement(f, tt.format, tt.options)
+			if err != nil {
+				t.Fatalf("ReadStatement() error = %v", err)
+			}
+			got := formatStatement(st)
+
+			golden := filepath.Join("testdata", tt.file+".golden")
+			if *update {
+				if err := os.WriteFile(golden, []byte(got), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != string(want) {
+				t.Errorf("ReadStatement() of %s =\n%s\nwant\n%s", tt.file, got, want)
+			}
+		})
+	}
+}
+
+func TestReadStatementUnknownFormat(t *testing.T) {
+	if _, err := ReadStatement(strings.NewReader(""), "xls", ImportOptions{}); err == nil {
+		t.Error("ReadStatement() of xls error = nil, want an error")
+	}
+	if _, err := ReadStatement(strings.NewReader("no tags"), "ofx", ImportOptions{}); err == nil {
+		t.Error("ReadStatement() of a file without OFX element error = nil, want an error")
+	}
+}
+
+func TestSplitRatio(t *testing.T) {
+	tests := []struct {
+		numerator, denominator string
+		from, to               int
+		wantErr                bool
+	}{
+		{numerator: "2", denominator: "1", from: 1, to: 2},
+		{numerator: "1", denominator: "10", from: 10, to: 1},
+		{numerator: "1.5", denominator: "1", from: 2, to: 3},
+		{numerator: "20", denominator: "10", from: 1, to: 2},
+		{numerator: "15", denominator: "10", from: 2, to: 3},
+		{numerator: "0.125", denominator: "1", from: 8, to: 1},
+		{numerator: "0", denominator: "10", wantErr: true},
+		{numerator: "2", denominator: "-1", wantErr: true},
+		{numerator: "0.000000001", denominator: "1", wantErr: true},
+		{numerator: "3000000000", denominator: "1", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.numerator+":"+tt.denominator, func(t *testing.T) {
+			from, to, err := splitRatio(decimal.RequireFromString(tt.numerator), decimal.RequireFromString(tt.denominator))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("splitRatio() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if from != tt.from || to != tt.to {
+				t.Errorf("splitRatio() = %d:%d, want %d:%d", from, to, tt.from, tt.to)
+			}
+		})
+	}
+}
+
+func TestQIFDate(t *testing.T) {
+	tests := []struct {
+		format string
+		s      string
+		want   string
+	}{
+		{s: "1/5'24", want: "2024-01-05"},
+		{s: "12/31'99", want: "2099-12-31"},
+		{s: "01/05/2024", want: "2024-01-05"},
+		{s: "1-5-69", want: "2069-01-05"},
+		{s: "1-5-70", want: "1970-01-05"},
+		{s: "1/5/99", want: "1999-01-05"},
+		{format: "DD/MM/YYYY", s: "05.01.24", want: "2024-01-05"},
+		{format: "DD/MM/YY", s: "31/12/69", want: "2069-12-31"},
+		{format: "YYYY-MM-DD", s: "2024-01-05", want: "2024-01-05"},
+		{s: "2/30/2024"},
+		{s: "13/1/2024"},
+		{s: "1/5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format+" "+tt.s, func(t *testing.T) {
+			got, err := qifReader{dateFormat: tt.format}.date(tt.s)
+			if tt.want == "" {
+				if err == nil {
+					t.Errorf("date(%q) = %s, want an error", tt.s, got.Format("2006-01-02"))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("date(%q) error = %v", tt.s, err)
+			}
+			if got.Format("2006-01-02") != tt.want {
+				t.Errorf("date(%q) = %s, want %s", tt.s, got.Format("2006-01-02"), tt.want)
+			}
+		})
+	}
+}
+
+func formatStatement(st *portfolio.Statement) string {
+	var b strings.Builder
+	for _, r := range st.Rows {
+		fmt.Fprintf(&b, "%d: ", r.Line)
+		switch {
+		case r.Err != nil:
+			fmt.Fprintf(&b, "error %v", r.Err)
+		case r.Action != nil:
+			a := r.Action
+			from, to := a.Ratio()
+			fmt.Fprintf(&b, "%s %s %s %d:%d", a.Type(), day(a.Date()), a.Asset(), from, to)
+		default:
+			tr := r.Transaction
+			fmt.Fprintf(&b, "%s %s asset=%q quantity=%s price=%s amount=%s currency=%q",
+				tr.Kind(), day(tr.Date()), tr.Asset(), tr.Quantity(), tr.Price(), tr.Amount(), tr.Currency())
+			if !tr.Accrued().IsZero() {
+				fmt.Fprintf(&b, " accrued=%s", tr.Accrued())
+			}
+			for _, f := range tr.Fees() {
+				fmt.Fprintf(&b, " %s=%s%s", f.Type, f.Amount, f.Currency)
+			}
+		}
+		b.WriteString("\n")
+	}
+	for _, p := range st.Positions {
+		fmt.Fprintf(&b, "position %s %s %s\n", day(p.Date), p.Asset, p.Quantity)
+	}
+	return b.String()
+}
+
+func day(t time.Time) string {
+	return t.Format("2006-01-02")
+}
@@ -0,0 +1,55 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/invine/portfolio/internal/domain/price"
+)
+
+type MemoryPriceProvider struct {
+	mu     sync.RWMutex
+	prices map[string]*price.Price
+}
+
+func NewMemoryPriceProvider(prices []*price.Price) *MemoryPriceProvider {
+	m := &MemoryPriceProvider{}
+	m.reset(prices)
+	return m
+}
+
+func (m *MemoryPriceProvider) reset(prices []*price.Price) {
+	latest := map[string]*price.Price{}
+	for _, p := range prices {
+		setLatestPrice(latest, p)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.prices = latest
+}
+
+func (m *MemoryPriceProvider) SetPrice(p *price.Price) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	setLatestPrice(m.prices, p)
+}
+
+func setLatestPrice(prices map[string]*price.Price, p *price.Price) {
+	if old, ok := prices[p.Asset()]; ok && old.Date().After(p.Date()) {
+		return
+	}
+	prices[p.Asset()] = p
+}
+
+func (m *MemoryPriceProvider) GetPrice(ctx context.Context, asset string) (*price.Price, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	p, ok := m.prices[asset]
+	if !ok {
+		return nil, fmt.Errorf("can't get price of %s: %w", asset, price.ErrPriceNotFound)
+	}
+	return p, nil
+}
@@ -0,0 +1,181 @@
+package adapters
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/invine/portfolio/internal/domain/portfolio"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/shopspring/decimal"
+)
+
+type SQLiteCorporateActionRepository struct {
+	db *sql.DB
+}
+
+type corporateActionModel struct {
+	ID             uuid.UUID
+	DateString     string
+	Type           string
+	Asset          string
+	NewAsset       string
+	From           int
+	To             int
+	CostAllocation decimal.Decimal
+	CashPrice      decimal.Decimal
+}
+
+func NewSQLiteCorporateActionRepository(db *sql.DB) (*SQLiteCorporateActionRepository, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database required")
+	}
+
+	if err := createCorporateActionsTable(db); err != nil {
+		return nil, err
+	}
+
+	r := &SQLiteCorporateActionRepository{db: db}
+	return r, nil
+}
+
+func createCorporateActionsTable(db *sql.DB) error {
+	sqlStmt := `
+        CREATE TABLE IF NOT EXISTS corporate_actions
+        (
+            id text not null primary key,
+            date text not null,
+            type text not null,
+            asset text not null,
+            newasset text not null default '',
+            ratiofrom integer not null default 1,
+            ratioto integer not null default 1,
+            costallocation text not null default '0',
+            cashprice text not null default '0'
+        );
+	`
+	if _, err := db.Exec(sqlStmt); err != nil {
+		return fmt.Errorf("can't insert table: %w", err)
+	}
+	if err := changeColumnsToText(db, "corporate_actions", "costallocation", "cashprice"); err != nil {
+		return fmt.Errorf("can't migrate table: %w", err)
+	}
+	// actions with a portfolio id apply to that portfolio only
+	if err := addColumn(db, "corporate_actions", "portfolioid", "text not null default ''"); err != nil {
+		return fmt.Errorf("can't migrate table: %w", err)
+	}
+	return nil
+}
+
+func (r *SQLiteCorporateActionRepository) AddCorporateAction(ctx context.Context, a *portfolio.CorporateAction) error {
+	am := corporateActionToCorporateActionModel(a)
+	sqlStmt := `
+        INSERT INTO
+        corporate_actions(id, date, type, asset, newasset, ratiofrom, ratioto, costallocation, cashprice)
+        VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+	if _, err := r.db.ExecContext(ctx, sqlStmt, am.ID, am.DateString, am.Type, am.Asset, am.NewAsset, am.From, am.To, am.CostAllocation, am.CashPrice); err != nil {
+		return fmt.Errorf("can't add corporate action %s: %w", am.ID.String(), err)
+	}
+	return nil
+}
+
+func (r *SQLiteCorporateActionRepository) GetCorporateActions(ctx context.Context) ([]*portfolio.CorporateAction, error) {
+	return getCorporateActions(ctx, r.db)
+}
+
+func (r *SQLiteCorporateActionRepository) DeleteCorporateAction(ctx context.Context, id uuid.UUID) error {
+	sqlStmt := "DELETE FROM corporate_actions WHERE id=$1 AND portfolioid=''"
+	res, err := r.db.ExecContext(ctx, sqlStmt, id)
+	if err != nil {
+		return fmt.Errorf("can't delete corporate action %s: %w", id.String(), err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("can't delete corporate action %s: %w", id.String(), err)
+	}
+	if n == 0 {
+		return fmt.Errorf("can't delete corporate action %s: not found", id.String())
+	}
+	return nil
+}
+
+func getCorporateActions(ctx context.Context, db querier) ([]*portfolio.CorporateAction, error) {
+	return queryCorporateActions(ctx, db, "")
+}
+
+func getPortfolioActions(ctx context.Context, db querier, portfolioID uuid.UUID) ([]*portfolio.CorporateAction, error) {
+	return queryCorporateActions(ctx, db, portfolioID.String())
+}
+
+func queryCorporateActions(ctx context.Context, db querier, portfolioID string) ([]*portfolio.CorporateAction, error) {
+	sqlStmt := `
+        select id, date, type, asset, newasset, ratiofrom, ratioto, costallocation, cashprice
+        from corporate_actions where portfolioid = $1 order by date
+	`
+	rows, err := db.QueryContext(ctx, sqlStmt, portfolioID)
+	if err != nil {
+		return nil, fmt.Errorf("can't list corporate actions: %w", err)
+	}
+	defer rows.Close()
+
+	actions := []*portfolio.CorporateAction{}
+	for rows.Next() {
+		am := &corporateActionModel{}
+		if err := rows.Scan(&am.ID, &am.DateString, &am.Type, &am.Asset, &am.NewAsset, &am.From, &am.To, &am.CostAllocation, &am.CashPrice); err != nil {
+			return nil, fmt.Errorf("can't list corporate actions: %w", err)
+		}
+		a, err := corporateActionModelToCorporateAction(am)
+		if err != nil {
+			return nil, fmt.Errorf("can't list corporate actions: %w", err)
+		}
+		actions = append(actions, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("can't list corporate actions: %w", err)
+	}
+	return actions, nil
+}
+
+func corporateActionToCorporateActionModel(a *portfolio.CorporateAction) *corporateActionModel {
+	from, to := a.Ratio()
+	return &corporateActionModel{
+		ID:             a.ID(),
+		DateString:     a.Date().Format(priceHistoryDateFormat),
+		Type:           string(a.Type()),
+		Asset:          a.Asset(),
+		NewAsset:       a.NewAsset(),
+		From:           from,
+		To:             to,
+		CostAllocation: a.CostAllocation(),
+		CashPrice:      a.CashPrice(),
+	}
+}
+
+func corporateActionModelToCorporateAction(am *corporateActionModel) (*portfolio.CorporateAction, error) {
+	date, err := time.Parse(priceHistoryDateFormat, am.DateString)
+	if err != nil {
+		return nil, fmt.Errorf("incorrect corporate action parameter: %w", err)
+	}
+	kind, err := portfolio.ParseCorporateActionType(am.Type)
+	if err != nil {
+		return nil, fmt.Errorf("incorrect corporate action parameter: %w", err)
+	}
+	var a *portfolio.CorporateAction
+	switch kind {
+	case portfolio.Split, portfolio.ReverseSplit:
+		a, err = portfolio.NewSplit(am.ID, date, am.Asset, am.From, am.To, am.CashPrice)
+	case portfolio.SymbolChange:
+		a, err = portfolio.NewSymbolChange(am.ID, date, am.Asset, am.NewAsset)
+	case portfolio.Merger:
+		a, err = portfolio.NewMerger(am.ID, date, am.Asset, am.NewAsset, am.From, am.To, am.CashPrice)
+	case portfolio.SpinOff:
+		a, err = portfolio.NewSpinOff(am.ID, date, am.Asset, am.NewAsset, am.From, am.To, am.CostAllocation, am.CashPrice)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("incorrect corporate action parameter: %w", err)
+	}
+	return a, nil
+}
@@ -0,0 +1,128 @@
+package adapters
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/invine/portfolio/internal/domain/fx"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/shopspring/decimal"
+)
+
+type SQLiteRateRepository struct {
+	db *sql.DB
+}
+
+type rateModel struct {
+	Base       string
+	Quote      string
+	DateString string
+	Rate       decimal.Decimal
+	Source     string
+}
+
+func NewSQLiteRateRepository(db *sql.DB) (*SQLiteRateRepository, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database required")
+	}
+
+	sqlStmt := `
+        CREATE TABLE IF NOT EXISTS fx_rates
+        (
+            base text not null,
+            quote text not null,
+            date text not null,
+            rate text not null,
+            source text not null default '',
+            primary key(base, quote, date)
+        );
+	`
+	if _, err := db.Exec(sqlStmt); err != nil {
+		return nil, fmt.Errorf("can't insert table: %w", err)
+	}
+
+	r := &SQLiteRateRepository{db: db}
+	return r, nil
+}
+
+func (r *SQLiteRateRepository) AddRates(ctx context.Context, rates []*fx.Rate) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("can't add exchange rates: %w", err)
+	}
+	defer tx.Rollback()
+
+	sqlStmt := `
+        INSERT INTO fx_rates(base, quote, date, rate, source)
+        VALUES($1, $2, $3, $4, $5)
+        ON CONFLICT(base, quote, date) DO UPDATE SET
+        rate=excluded.rate, source=excluded.source
+	`
+	stmt, err := tx.Prepare(sqlStmt)
+	if err != nil {
+		return fmt.Errorf("can't add exchange rates: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, rate := range rates {
+		rm := rateToRateModel(rate)
+		if _, err := stmt.ExecContext(ctx, rm.Base, rm.Quote, rm.DateString, rm.Rate, rm.Source); err != nil {
+			return fmt.Errorf("can't add exchange rate of %s/%s at %s: %w", rm.Base, rm.Quote, rm.DateString, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("can't add exchange rates: %w", err)
+	}
+	return nil
+}
+
+func (r *SQLiteRateRepository) GetRates(ctx context.Context) (*fx.Rates, error) {
+	rows, err := r.db.QueryContext(ctx, "select base, quote, date, rate, source from fx_rates")
+	if err != nil {
+		return nil, fmt.Errorf("can't list exchange rates: %w", err)
+	}
+	defer rows.Close()
+
+	rates := []*fx.Rate{}
+	for rows.Next() {
+		rm := &rateModel{}
+		if err := rows.Scan(&rm.Base, &rm.Quote, &rm.DateString, &rm.Rate, &rm.Source); err != nil {
+			return nil, fmt.Errorf("can't list exchange rates: %w", err)
+		}
+		rate, err := rateModelToRate(rm)
+		if err != nil {
+			return nil, fmt.Errorf("can't list exchange rates: %w", err)
+		}
+		rates = append(rates, rate)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("can't list exchange rates: %w", err)
+	}
+
+	return fx.NewRates(rates), nil
+}
+
+func rateToRateModel(r *fx.Rate) *rateModel {
+	return &rateModel{
+		Base:       r.Base(),
+		Quote:      r.Quote(),
+		DateString: r.Date().Format(priceHistoryDateFormat),
+		Rate:       r.Rate(),
+		Source:     r.Source(),
+	}
+}
+
+func rateModelToRate(rm *rateModel) (*fx.Rate, error) {
+	date, err := time.Parse(priceHistoryDateFormat, rm.DateString)
+	if err != nil {
+		return nil, fmt.Errorf("incorrect exchange rate parameter: %w", err)
+	}
+	rate, err := fx.NewRate(rm.Base, rm.Quote, date, rm.Rate, rm.Source)
+	if err != nil {
+		return nil, fmt.Errorf("incorrect exchange rate parameter: %w", err)
+	}
+	return rate, nil
+}
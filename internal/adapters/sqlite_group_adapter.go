@@ -0,0 +1,286 @@
+package adapters
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/invine/portfolio/internal/domain/portfolio"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+type SQLiteGroupRepository struct {
+	db *sql.DB
+}
+
+type groupModel struct {
+	ID         uuid.UUID
+	UserID     uuid.UUID
+	Name       string
+	ParentID   string
+	Portfolios []uuid.UUID
+}
+
+func NewSQLiteGroupRepository(db *sql.DB) (*SQLiteGroupRepository, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database required")
+	}
+
+	if err := createGroupsTables(db); err != nil {
+		return nil, err
+	}
+
+	r := &SQLiteGroupRepository{db: db}
+	return r, nil
+}
+
+func createGroupsTables(db *sql.DB) error {
+	sqlStmt := `
+        CREATE TABLE IF NOT EXISTS portfolio_groups
+        (
+            id text not null primary key,
+            userid text not null,
+            name text not null,
+            parentid text not null default ''
+        );
+	`
+	if _, err := db.Exec(sqlStmt); err != nil {
+		return fmt.Errorf("can't insert table: %w", err)
+	}
+
+	sqlStmt = `
+        CREATE TABLE IF NOT EXISTS portfolio_group_members
+        (
+            portfolioid text not null primary key,
+            groupid text not null,
+            position integer not null
+        );
+	`
+	if _, err := db.Exec(sqlStmt); err != nil {
+		return fmt.Errorf("can't insert table: %w", err)
+	}
+	return nil
+}
+
+func (r *SQLiteGroupRepository) AddGroup(ctx context.Context, g *portfolio.Group) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("can't add group %s: %w", g.Name(), err)
+	}
+	defer tx.Rollback()
+
+	gm := groupToGroupModel(g)
+	sqlStmt := "INSERT INTO portfolio_groups(id, userid, name, parentid) VALUES($1, $2, $3, $4)"
+	if _, err := tx.ExecContext(ctx, sqlStmt, gm.ID, gm.UserID, gm.Name, gm.ParentID); err != nil {
+		return fmt.Errorf("can't add group %s: %w", gm.Name, err)
+	}
+	if err := r.upsertMembers(ctx, tx, gm); err != nil {
+		return fmt.Errorf("can't add group %s: %w", gm.Name, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("can't add group %s: %w", gm.Name, err)
+	}
+	return nil
+}
+
+func (r *SQLiteGroupRepository) GetGroups(ctx context.Context, userID uuid.UUID) ([]*portfolio.Group, error) {
+	gms, err := getGroupModels(ctx, r.db, userID, "")
+	if err != nil {
+		return nil, fmt.Errorf("can't list groups for user %s: %w", userID.String(), err)
+	}
+
+	groups := []*portfolio.Group{}
+	for _, gm := range gms {
+		g, err := groupModelToGroup(gm)
+		if err != nil {
+			return nil, fmt.Errorf("can't list groups for user %s: %w", userID.String(), err)
+		}
+		groups = append(groups, g)
+	}
+	return groups, nil
+}
+
+func (r *SQLiteGroupRepository) GetGroup(ctx context.Context, userID, id uuid.UUID) (*portfolio.Group, error) {
+	return getGroup(ctx, r.db, userID, id)
+}
+
+func (r *SQLiteGroupRepository) UpdateGroup(ctx context.Context, userID, id uuid.UUID, updateFn func(g *portfolio.Group) error) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("can't update group %s: %w", id.String(), err)
+	}
+	defer tx.Rollback()
+
+	g, err := getGroup(ctx, tx, userID, id)
+	if err != nil {
+		return fmt.Errorf("can't update group %s: %w", id.String(), err)
+	}
+
+	if err := updateFn(g); err != nil {
+		return fmt.Errorf("can't update group %s: %w", id.String(), err)
+	}
+
+	gm := groupToGroupModel(g)
+	sqlStmt := "UPDATE portfolio_groups SET name = $1, parentid = $2 WHERE userid = $3 AND id = $4"
+	if _, err := tx.ExecContext(ctx, sqlStmt, gm.Name, gm.ParentID, gm.UserID, gm.ID); err != nil {
+		return fmt.Errorf("can't update group %s: %w", id.String(), err)
+	}
+	if err := r.upsertMembers(ctx, tx, gm); err != nil {
+		return fmt.Errorf("can't update group %s: %w", id.String(), err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("can't update group %s: %w", id.String(), err)
+	}
+	return nil
+}
+
+func (r *SQLiteGroupRepository) DeleteGroup(ctx context.Context, userID, id uuid.UUID) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("can't delete group %s: %w", id.String(), err)
+	}
+	defer tx.Rollback()
+
+	g, err := getGroup(ctx, tx, userID, id)
+	if err != nil {
+		return fmt.Errorf("can't delete group %s: %w", id.String(), err)
+	}
+	gm := groupToGroupModel(g)
+
+	sqlStmt := "UPDATE portfolio_groups SET parentid = $1 WHERE userid = $2 AND parentid = $3"
+	if _, err := tx.ExecContext(ctx, sqlStmt, gm.ParentID, userID, id); err != nil {
+		return fmt.Errorf("can't delete group %s: %w", id.String(), err)
+	}
+
+	if gm.ParentID == "" {
+		sqlStmt = "DELETE FROM portfolio_group_members WHERE groupid = $1"
+		if _, err := tx.ExecContext(ctx, sqlStmt, id); err != nil {
+			return fmt.Errorf("can't delete group %s: %w", id.String(), err)
+		}
+	} else {
+		sqlStmt = `
+            UPDATE portfolio_group_members
+            SET groupid = $1, position = position + (SELECT count(*) FROM portfolio_group_members WHERE groupid = $1)
+            WHERE groupid = $2
+		`
+		if _, err := tx.ExecContext(ctx, sqlStmt, gm.ParentID, id); err != nil {
+			return fmt.Errorf("can't delete group %s: %w", id.String(), err)
+		}
+	}
+
+	sqlStmt = "DELETE FROM portfolio_groups WHERE userid = $1 AND id = $2"
+	if _, err := tx.ExecContext(ctx, sqlStmt, userID, id); err != nil {
+		return fmt.Errorf("can't delete group %s: %w", id.String(), err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("can't delete group %s: %w", id.String(), err)
+	}
+	return nil
+}
+
+func (r *SQLiteGroupRepository) upsertMembers(ctx context.Context, tx *sql.Tx, gm *groupModel) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM portfolio_group_members WHERE groupid = $1", gm.ID); err != nil {
+		return fmt.Errorf("can't upsert portfolios: %w", err)
+	}
+
+	sqlStmt := "INSERT INTO portfolio_group_members(portfolioid, groupid, position) VALUES($1, $2, $3)"
+	for i, id := range gm.Portfolios {
+		if _, err := tx.ExecContext(ctx, sqlStmt, id, gm.ID, i); err != nil {
+			return fmt.Errorf("can't upsert portfolio %s: %w", id.String(), err)
+		}
+	}
+	return nil
+}
+
+func getGroup(ctx context.Context, db querier, userID, id uuid.UUID) (*portfolio.Group, error) {
+	gms, err := getGroupModels(ctx, db, userID, "and id = $2", id)
+	if err != nil {
+		return nil, fmt.Errorf("can't get group %s: %w", id.String(), err)
+	}
+	if len(gms) == 0 {
+		return nil, fmt.Errorf("can't get group %s: %w", id.String(), portfolio.ErrGroupNotFound)
+	}
+	g, err := groupModelToGroup(gms[0])
+	if err != nil {
+		return nil, fmt.Errorf("can't get group %s: %w", id.String(), err)
+	}
+	return g, nil
+}
+
+func getGroupModels(ctx context.Context, db querier, userID uuid.UUID, condition string, args ...interface{}) ([]*groupModel, error) {
+	sqlStmt := "select id, name, parentid from portfolio_groups where userid = $1 " + condition + " order by name, id"
+	rows, err := db.QueryContext(ctx, sqlStmt, append([]interface{}{userID}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	gms := []*groupModel{}
+	byID := map[uuid.UUID]*groupModel{}
+	for rows.Next() {
+		gm := &groupModel{UserID: userID, Portfolios: []uuid.UUID{}}
+		if err := rows.Scan(&gm.ID, &gm.Name, &gm.ParentID); err != nil {
+			return nil, err
+		}
+		gms = append(gms, gm)
+		byID[gm.ID] = gm
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sqlStmt = `
+        select m.groupid, m.portfolioid from portfolio_group_members m
+        join portfolio_groups g on g.id = m.groupid
+        where g.userid = $1
+        order by m.position
+	`
+	rows, err = db.QueryContext(ctx, sqlStmt, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var groupID, portfolioID uuid.UUID
+		if err := rows.Scan(&groupID, &portfolioID); err != nil {
+			return nil, err
+		}
+		if gm, ok := byID[groupID]; ok {
+			gm.Portfolios = append(gm.Portfolios, portfolioID)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return gms, nil
+}
+
+func groupToGroupModel(g *portfolio.Group) *groupModel {
+	gm := &groupModel{
+		ID:         g.ID(),
+		UserID:     g.UserID(),
+		Name:       g.Name(),
+		Portfolios: g.Portfolios(),
+	}
+	if g.ParentID() != uuid.Nil {
+		gm.ParentID = g.ParentID().String()
+	}
+	return gm
+}
+
+func groupModelToGroup(gm *groupModel) (*portfolio.Group, error) {
+	parentID := uuid.Nil
+	if gm.ParentID != "" {
+		id, err := uuid.Parse(gm.ParentID)
+		if err != nil {
+			return nil, fmt.Errorf("incorrect parent id %s: %w", gm.ParentID, err)
+		}
+		parentID = id
+	}
+	return portfolio.NewGroup(gm.ID, gm.UserID, gm.Name, parentID, gm.Portfolios)
+}
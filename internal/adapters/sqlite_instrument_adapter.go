@@ -0,0 +1,589 @@
+package adapters
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/invine/portfolio/internal/domain/instrument"
+	"github.com/invine/portfolio/internal/domain/portfolio"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/shopspring/decimal"
+)
+
+type SQLiteInstrumentRepository struct {
+	db *sql.DB
+}
+
+type instrumentModel struct {
+	ID        uuid.UUID
+	Symbol    string
+	ISIN      string
+	Name      string
+	Class     string
+	Exchange  string
+	Currency  string
+	Sector    string
+	Country   string
+	Precision int32
+	Aliases   []string
+	Tags      map[string]string
+
+	Underlying   string
+	Multiplier   decimal.Decimal
+	Right        string
+	Strike       decimal.Decimal
+	ExpiryString string
+
+	FaceValue      decimal.Decimal
+	CouponRate     decimal.Decimal
+	Frequency      int
+	DayCount       string
+	IssueString    string
+	MaturityString string
+}
+
+func NewSQLiteInstrumentRepository(db *sql.DB) (*SQLiteInstrumentRepository, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database required")
+	}
+
+	if err := createInstrumentsTables(db); err != nil {
+		return nil, err
+	}
+
+	r := &SQLiteInstrumentRepository{db: db}
+	return r, nil
+}
+
+func createInstrumentsTables(db *sql.DB) error {
+	sqlStmt := `
+        CREATE TABLE IF NOT EXISTS instruments
+        (
+            id text not null primary key,
+            symbol text not null unique,
+            isin text not null default '',
+            name text not null default '',
+            class text not null default 'other',
+            exchange text not null default '',
+            currency text not null default '',
+            sector text not null default '',
+            country text not null default '',
+            places integer not null default 0
+        );
+	`
+	if _, err := db.Exec(sqlStmt); err != nil {
+		return fmt.Errorf("can't insert table: %w", err)
+	}
+
+	sqlStmt = `
+        CREATE TABLE IF NOT EXISTS instrument_aliases
+        (
+            alias text not null primary key,
+            instrumentid text not null
+        );
+	`
+	if _, err := db.Exec(sqlStmt); err != nil {
+		return fmt.Errorf("can't insert table: %w", err)
+	}
+
+	sqlStmt = `
+        CREATE TABLE IF NOT EXISTS instrument_tags
+        (
+            instrumentid text not null,
+            name text not null,
+            value text not null,
+            primary key(instrumentid, name)
+        );
+	`
+	if _, err := db.Exec(sqlStmt); err != nil {
+		return fmt.Errorf("can't insert table: %w", err)
+	}
+
+	if err := addColumn(db, "instruments", "underlying", "text not null default ''"); err != nil {
+		return fmt.Errorf("can't migrate table: %w", err)
+	}
+	if err := addColumn(db, "instruments", "multiplier", "text not null default '0'"); err != nil {
+		return fmt.Errorf("can't migrate table: %w", err)
+	}
+	if err := addColumn(db, "instruments", "optionright", "text not null default ''"); err != nil {
+		return fmt.Errorf("can't migrate table: %w", err)
+	}
+	if err := addColumn(db, "instruments", "strike", "text not null default '0'"); err != nil {
+		return fmt.Errorf("can't migrate table: %w", err)
+	}
+	if err := addColumn(db, "instruments", "expiry", "text not null default ''"); err != nil {
+		return fmt.Errorf("can't migrate table: %w", err)
+	}
+	if err := addColumn(db, "instruments", "facevalue", "text not null default '0'"); err != nil {
+		return fmt.Errorf("can't migrate table: %w", err)
+	}
+	if err := addColumn(db, "instruments", "couponrate", "text not null default '0'"); err != nil {
+		return fmt.Errorf("can't migrate table: %w", err)
+	}
+	if err := addColumn(db, "instruments", "frequency", "integer not null default 0"); err != nil {
+		return fmt.Errorf("can't migrate table: %w", err)
+	}
+	if err := addColumn(db, "instruments", "daycount", "text not null default ''"); err != nil {
+		return fmt.Errorf("can't migrate table: %w", err)
+	}
+	if err := addColumn(db, "instruments", "issue", "text not null default ''"); err != nil {
+		return fmt.Errorf("can't migrate table: %w", err)
+	}
+	if err := addColumn(db, "instruments", "maturity", "text not null default ''"); err != nil {
+		return fmt.Errorf("can't migrate table: %w", err)
+	}
+	return nil
+}
+
+func (r *SQLiteInstrumentRepository) AddInstrument(ctx context.Context, i *instrument.Instrument) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("can't add instrument %s: %w", i.Symbol(), err)
+	}
+	defer tx.Rollback()
+
+	if err := r.checkCodes(ctx, tx, i); err != nil {
+		return fmt.Errorf("can't add instrument %s: %w", i.Symbol(), err)
+	}
+
+	im := instrumentToInstrumentModel(i)
+	sqlStmt := `
+        INSERT INTO
+        instruments(id, symbol, isin, name, class, exchange, currency, sector, country, places, underlying, multiplier, optionright, strike, expiry,
+        facevalue, couponrate, frequency, daycount, issue, maturity)
+        VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21)
+	`
+	if _, err := tx.ExecContext(
+		ctx, sqlStmt, im.ID, im.Symbol, im.ISIN, im.Name, im.Class, im.Exchange, im.Currency, im.Sector, im.Country, im.Precision,
+		im.Underlying, im.Multiplier, im.Right, im.Strike, im.ExpiryString,
+		im.FaceValue, im.CouponRate, im.Frequency, im.DayCount, im.IssueString, im.MaturityString,
+	); err != nil {
+		return fmt.Errorf("can't add instrument %s: %w", im.Symbol, err)
+	}
+	if err := r.upsertAliases(ctx, tx, im); err != nil {
+		return fmt.Errorf("can't add instrument %s: %w", im.Symbol, err)
+	}
+	if err := r.upsertTags(ctx, tx, im); err != nil {
+		return fmt.Errorf("can't add instrument %s: %w", im.Symbol, err)
+	}
+	if err := r.linkTransactions(ctx, tx, i); err != nil {
+		return fmt.Errorf("can't add instrument %s: %w", im.Symbol, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("can't add instrument %s: %w", im.Symbol, err)
+	}
+	return nil
+}
+
+func (r *SQLiteInstrumentRepository) GetInstrument(ctx context.Context, id uuid.UUID) (*instrument.Instrument, error) {
+	return getInstrument(ctx, r.db, id)
+}
+
+func (r *SQLiteInstrumentRepository) GetInstruments(ctx context.Context) ([]*instrument.Instrument, error) {
+	ims, err := getInstrumentModels(ctx, r.db, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	res := []*instrument.Instrument{}
+	for _, im := range ims {
+		i, err := instrumentModelToInstrument(im)
+		if err != nil {
+			return nil, fmt.Errorf("can't list instruments: %w", err)
+		}
+		res = append(res, i)
+	}
+	return res, nil
+}
+
+func (r *SQLiteInstrumentRepository) FindInstrument(ctx context.Context, code string) (*instrument.Instrument, error) {
+	return findInstrument(ctx, r.db, code)
+}
+
+func (r *SQLiteInstrumentRepository) UpdateInstrument(ctx context.Context, id uuid.UUID, updateFn func(i *instrument.Instrument) error) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("can't update instrument %s: %w", id.String(), err)
+	}
+	defer tx.Rollback()
+
+	i, err := getInstrument(ctx, tx, id)
+	if err != nil {
+		return fmt.Errorf("can't update instrument %s: %w", id.String(), err)
+	}
+
+	if err := updateFn(i); err != nil {
+		return fmt.Errorf("can't update instrument %s: %w", id.String(), err)
+	}
+
+	if err := r.checkCodes(ctx, tx, i); err != nil {
+		return fmt.Errorf("can't update instrument %s: %w", id.String(), err)
+	}
+
+	im := instrumentToInstrumentModel(i)
+	sqlStmt := `
+        UPDATE instruments SET
+        symbol = $1, isin = $2, name = $3, class = $4, exchange = $5, currency = $6, sector = $7, country = $8, places = $9,
+        underlying = $10, multiplier = $11, optionright = $12, strike = $13, expiry = $14,
+        facevalue = $15, couponrate = $16, frequency = $17, daycount = $18, issue = $19, maturity = $20
+        WHERE id = $21
+	`
+	if _, err := tx.ExecContext(
+		ctx, sqlStmt, im.Symbol, im.ISIN, im.Name, im.Class, im.Exchange, im.Currency, im.Sector, im.Country, im.Precision,
+		im.Underlying, im.Multiplier, im.Right, im.Strike, im.ExpiryString,
+		im.FaceValue, im.CouponRate, im.Frequency, im.DayCount, im.IssueString, im.MaturityString, im.ID,
+	); err != nil {
+		return fmt.Errorf("can't update instrument %s: %w", id.String(), err)
+	}
+	if err := r.upsertAliases(ctx, tx, im); err != nil {
+		return fmt.Errorf("can't update instrument %s: %w", id.String(), err)
+	}
+	if err := r.upsertTags(ctx, tx, im); err != nil {
+		return fmt.Errorf("can't update instrument %s: %w", id.String(), err)
+	}
+	if err := r.linkTransactions(ctx, tx, i); err != nil {
+		return fmt.Errorf("can't update instrument %s: %w", id.String(), err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("can't update instrument %s: %w", id.String(), err)
+	}
+	return nil
+}
+
+func (r *SQLiteInstrumentRepository) DeleteInstrument(ctx context.Context, id uuid.UUID) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("can't delete instrument %s: %w", id.String(), err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, "DELETE FROM instruments WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("can't delete instrument %s: %w", id.String(), err)
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return fmt.Errorf("can't delete instrument %s: %w", id.String(), instrument.ErrInstrumentNotFound)
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM instrument_aliases WHERE instrumentid = $1", id); err != nil {
+		return fmt.Errorf("can't delete instrument %s: %w", id.String(), err)
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM instrument_tags WHERE instrumentid = $1", id); err != nil {
+		return fmt.Errorf("can't delete instrument %s: %w", id.String(), err)
+	}
+	if _, err := tx.ExecContext(ctx, "UPDATE transactions SET instrumentid = '' WHERE instrumentid = $1", id); err != nil {
+		return fmt.Errorf("can't delete instrument %s: %w", id.String(), err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("can't delete instrument %s: %w", id.String(), err)
+	}
+	return nil
+}
+
+func (r *SQLiteInstrumentRepository) checkCodes(ctx context.Context, tx *sql.Tx, i *instrument.Instrument) error {
+	for _, code := range i.Codes() {
+		other, err := findInstrument(ctx, tx, code)
+		if errors.Is(err, instrument.ErrInstrumentNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if other.ID() != i.ID() {
+			return fmt.Errorf("%s identifies instrument %s: %w", code, other.Symbol(), instrument.ErrCodeTaken)
+		}
+	}
+	return nil
+}
+
+func (r *SQLiteInstrumentRepository) upsertAliases(ctx context.Context, tx *sql.Tx, im *instrumentModel) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM instrument_aliases WHERE instrumentid = $1", im.ID); err != nil {
+		return fmt.Errorf("can't upsert aliases: %w", err)
+	}
+
+	sqlStmt := "INSERT INTO instrument_aliases(alias, instrumentid) VALUES($1, $2)"
+	for _, alias := range im.Aliases {
+		if _, err := tx.ExecContext(ctx, sqlStmt, alias, im.ID); err != nil {
+			return fmt.Errorf("can't upsert alias %s: %w", alias, err)
+		}
+	}
+	return nil
+}
+
+func (r *SQLiteInstrumentRepository) upsertTags(ctx context.Context, tx *sql.Tx, im *instrumentModel) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM instrument_tags WHERE instrumentid = $1", im.ID); err != nil {
+		return fmt.Errorf("can't upsert tags: %w", err)
+	}
+
+	sqlStmt := "INSERT INTO instrument_tags(instrumentid, name, value) VALUES($1, $2, $3)"
+	for name, value := range im.Tags {
+		if _, err := tx.ExecContext(ctx, sqlStmt, im.ID, name, value); err != nil {
+			return fmt.Errorf("can't upsert tag %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func (r *SQLiteInstrumentRepository) linkTransactions(ctx context.Context, tx *sql.Tx, i *instrument.Instrument) error {
+	args := []interface{}{i.ID()}
+	placeholders := []string{}
+	for _, code := range i.Codes() {
+		args = append(args, code)
+		placeholders = append(placeholders, fmt.Sprintf("$%d", len(args)))
+	}
+	sqlStmt := fmt.Sprintf(`
+        UPDATE transactions SET instrumentid = $1
+        WHERE instrumentid = '' AND upper(asset) IN (%s)
+	`, strings.Join(placeholders, ", "))
+	if _, err := tx.ExecContext(ctx, sqlStmt, args...); err != nil {
+		return fmt.Errorf("can't link transactions: %w", err)
+	}
+	return nil
+}
+
+func getInstrument(ctx context.Context, db querier, id uuid.UUID) (*instrument.Instrument, error) {
+	ims, err := getInstrumentModels(ctx, db, "where id = $1", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(ims) == 0 {
+		return nil, fmt.Errorf("can't get instrument %s: %w", id.String(), instrument.ErrInstrumentNotFound)
+	}
+	i, err := instrumentModelToInstrument(ims[0])
+	if err != nil {
+		return nil, fmt.Errorf("can't get instrument %s: %w", id.String(), err)
+	}
+	return i, nil
+}
+
+func findInstrument(ctx context.Context, db querier, code string) (*instrument.Instrument, error) {
+	if id, err := uuid.Parse(code); err == nil {
+		return getInstrument(ctx, db, id)
+	}
+	sqlStmt := `
+        select id from instruments where symbol = $1 or isin = $1
+        union
+        select instrumentid from instrument_aliases where alias = $1
+	`
+	rows, err := db.QueryContext(ctx, sqlStmt, instrument.NormalizeCode(code))
+	if err != nil {
+		return nil, fmt.Errorf("can't find instrument %s: %w", code, err)
+	}
+	defer rows.Close()
+
+	var id uuid.UUID
+	found := false
+	for rows.Next() {
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("can't find instrument %s: %w", code, err)
+		}
+		found = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("can't find instrument %s: %w", code, err)
+	}
+	if !found {
+		return nil, fmt.Errorf("can't find instrument %s: %w", code, instrument.ErrInstrumentNotFound)
+	}
+	return getInstrument(ctx, db, id)
+}
+
+func getInstrumentModels(ctx context.Context, db querier, filter string, arg interface{}) ([]*instrumentModel, error) {
+	sqlStmt := `
+        select id, symbol, isin, name, class, exchange, currency, sector, country, places, underlying, multiplier, optionright, strike, expiry,
+        facevalue, couponrate, frequency, daycount, issue, maturity
+        from instruments ` + filter + " order by symbol"
+	args := []interface{}{}
+	if arg != nil {
+		args = append(args, arg)
+	}
+	rows, err := db.QueryContext(ctx, sqlStmt, args...)
+	if err != nil {
+		return nil, fmt.Errorf("can't list instruments: %w", err)
+	}
+	defer rows.Close()
+
+	ims := []*instrumentModel{}
+	byID := map[uuid.UUID]*instrumentModel{}
+	for rows.Next() {
+		im := &instrumentModel{Aliases: []string{}, Tags: map[string]string{}}
+		if err := rows.Scan(
+			&im.ID, &im.Symbol, &im.ISIN, &im.Name, &im.Class, &im.Exchange, &im.Currency, &im.Sector, &im.Country, &im.Precision,
+			&im.Underlying, &im.Multiplier, &im.Right, &im.Strike, &im.ExpiryString,
+			&im.FaceValue, &im.CouponRate, &im.Frequency, &im.DayCount, &im.IssueString, &im.MaturityString,
+		); err != nil {
+			return nil, fmt.Errorf("can't list instruments: %w", err)
+		}
+		ims = append(ims, im)
+		byID[im.ID] = im
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("can't list instruments: %w", err)
+	}
+
+	rows, err = db.QueryContext(ctx, "select alias, instrumentid from instrument_aliases order by alias")
+	if err != nil {
+		return nil, fmt.Errorf("can't list instruments: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			alias string
+			id    uuid.UUID
+		)
+		if err := rows.Scan(&alias, &id); err != nil {
+			return nil, fmt.Errorf("can't list instruments: %w", err)
+		}
+		if im, ok := byID[id]; ok {
+			im.Aliases = append(im.Aliases, alias)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("can't list instruments: %w", err)
+	}
+
+	rows, err = db.QueryContext(ctx, "select instrumentid, name, value from instrument_tags")
+	if err != nil {
+		return nil, fmt.Errorf("can't list instruments: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			id          uuid.UUID
+			name, value string
+		)
+		if err := rows.Scan(&id, &name, &value); err != nil {
+			return nil, fmt.Errorf("can't list instruments: %w", err)
+		}
+		if im, ok := byID[id]; ok {
+			im.Tags[name] = value
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("can't list instruments: %w", err)
+	}
+
+	return ims, nil
+}
+
+func getContracts(ctx context.Context, db querier) (portfolio.Contracts, error) {
+	ims, err := getInstrumentModels(ctx, db, "where class in ('option', 'future')", nil)
+	if err != nil {
+		return nil, fmt.Errorf("can't list contracts: %w", err)
+	}
+	contracts := portfolio.Contracts{}
+	for _, im := range ims {
+		i, err := instrumentModelToInstrument(im)
+		if err != nil {
+			return nil, fmt.Errorf("can't list contracts: %w", err)
+		}
+		contracts[i.Symbol()] = i.Contract()
+	}
+	return contracts, nil
+}
+
+func getBonds(ctx context.Context, db querier) (portfolio.Bonds, error) {
+	ims, err := getInstrumentModels(ctx, db, "where class = 'bond' and maturity != ''", nil)
+	if err != nil {
+		return nil, fmt.Errorf("can't list bonds: %w", err)
+	}
+	bonds := portfolio.Bonds{}
+	for _, im := range ims {
+		i, err := instrumentModelToInstrument(im)
+		if err != nil {
+			return nil, fmt.Errorf("can't list bonds: %w", err)
+		}
+		bonds[i.Symbol()] = i.Bond()
+	}
+	return bonds, nil
+}
+
+func instrumentToInstrumentModel(i *instrument.Instrument) *instrumentModel {
+	d := i.Details()
+	im := &instrumentModel{
+		ID:        i.ID(),
+		Symbol:    i.Symbol(),
+		ISIN:      d.ISIN,
+		Name:      i.Name(),
+		Class:     string(i.Class()),
+		Exchange:  d.Exchange,
+		Currency:  d.Currency,
+		Sector:    d.Sector,
+		Country:   d.Country,
+		Precision: d.Precision,
+		Aliases:   d.Aliases,
+		Tags:      d.Tags,
+
+		Underlying: d.Contract.Underlying,
+		Multiplier: d.Contract.Multiplier,
+		Right:      string(d.Contract.Right),
+		Strike:     d.Contract.Strike,
+	}
+	if !d.Contract.Expiry.IsZero() {
+		im.ExpiryString = d.Contract.Expiry.Format(time.RFC3339)
+	}
+	if !d.Bond.IsZero() {
+		im.FaceValue = d.Bond.FaceValue
+		im.CouponRate = d.Bond.CouponRate
+		im.Frequency = d.Bond.Frequency
+		im.DayCount = string(d.Bond.DayCount)
+		im.IssueString = d.Bond.Issue.Format(time.RFC3339)
+		im.MaturityString = d.Bond.Maturity.Format(time.RFC3339)
+	}
+	return im
+}
+
+func instrumentModelToInstrument(im *instrumentModel) (*instrument.Instrument, error) {
+	class, err := instrument.ParseAssetClass(im.Class)
+	if err != nil {
+		return nil, fmt.Errorf("incorrect instrument parameter: %w", err)
+	}
+	details := instrument.Details{
+		ISIN:      im.ISIN,
+		Exchange:  im.Exchange,
+		Currency:  im.Currency,
+		Sector:    im.Sector,
+		Country:   im.Country,
+		Precision: im.Precision,
+		Aliases:   im.Aliases,
+		Tags:      im.Tags,
+	}
+	if im.Underlying != "" {
+		details.Contract = portfolio.Contract{
+			Underlying: im.Underlying,
+			Multiplier: im.Multiplier,
+			Right:      portfolio.OptionRight(im.Right),
+			Strike:     im.Strike,
+		}
+		if details.Contract.Expiry, err = time.Parse(time.RFC3339, im.ExpiryString); err != nil {
+			return nil, fmt.Errorf("incorrect instrument parameter: %w", err)
+		}
+	}
+	if im.MaturityString != "" {
+		details.Bond = portfolio.Bond{
+			FaceValue:  im.FaceValue,
+			CouponRate: im.CouponRate,
+			Frequency:  im.Frequency,
+			DayCount:   portfolio.DayCount(im.DayCount),
+		}
+		if details.Bond.Issue, err = time.Parse(time.RFC3339, im.IssueString); err != nil {
+			return nil, fmt.Errorf("incorrect instrument parameter: %w", err)
+		}
+		if details.Bond.Maturity, err = time.Parse(time.RFC3339, im.MaturityString); err != nil {
+			return nil, fmt.Errorf("incorrect instrument parameter: %w", err)
+		}
+	}
+	i, err := instrument.NewInstrument(im.ID, im.Symbol, im.Name, class, details)
+	if err != nil {
+		return nil, fmt.Errorf("incorrect instrument parameter: %w", err)
+	}
+	return i, nil
+}
@@ -4,11 +4,13 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/invine/portfolio/internal/domain/portfolio"
 	_ "github.com/mattn/go-sqlite3"
+	"github.com/shopspring/decimal"
 )
 
 type SQLitePortfolioRepository struct {
@@ -20,19 +22,59 @@ type querier interface {
 }
 
 type transactionModel struct {
-	ID          uuid.UUID
-	UserID      uuid.UUID
-	PortfolioID uuid.UUID
-	Asset       string
-	Quantity    int
-	Price       float64
-	DateString  string
+	ID           uuid.UUID
+	UserID       uuid.UUID
+	PortfolioID  uuid.UUID
+	Kind         string
+	Asset        string
+	Quantity     decimal.Decimal
+	Price        decimal.Decimal
+	Amount       decimal.Decimal
+	Currency     string
+	InstrumentID string
+	Multiplier   decimal.Decimal
+	Premium      decimal.Decimal
+	Accrued      decimal.Decimal
+	Pending      bool
+	DateString   string
+	// TransferPortfolioID and TransferID point to the other side of a transfer
+	TransferPortfolioID string
+	TransferID          string
+	Lots                []lotModel
+	Fees                []feeModel
+}
+
+type lotModel struct {
+	LotID      uuid.UUID
+	Quantity   decimal.Decimal
+	DateString string
+	Price      decimal.Decimal
+}
+
+type feeModel struct {
+	Type     string
+	Amount   decimal.Decimal
+	Currency string
 }
 
 type portfolioModel struct {
-	ID     uuid.UUID
-	UserID uuid.UUID
+	ID              uuid.UUID
+	UserID          uuid.UUID
+	Name            string
+	CostBasisMethod string
+	Overdraft       bool
+	ShortSelling    bool
+	WashSaleRule    bool
+	Currency        string
+	Tolerance       decimal.Decimal
+	MinTrade        decimal.Decimal
+	Targets         []targetModel
+}
+
+type targetModel struct {
+	Key    string
 	Name   string
+	Weight decimal.Decimal
 }
 
 func NewSQLitePortfolioRepository(db *sql.DB) (*SQLitePortfolioRepository, error) {
@@ -61,8 +103,8 @@ func NewSQLitePortfolioRepository(db *sql.DB) (*SQLitePortfolioRepository, error
             portfolioid text not null,
             date text not null,
             asset text not null,
-            price real not null,
-            quantity integer not null
+            price text not null,
+            quantity text not null
         );
 	`
 	_, err = db.Exec(sqlStmt)
@@ -70,6 +112,148 @@ func NewSQLitePortfolioRepository(db *sql.DB) (*SQLitePortfolioRepository, error
 		return nil, fmt.Errorf("can't insert table: %w", err)
 	}
 
+	sqlStmt = `
+        CREATE TABLE IF NOT EXISTS transaction_lots
+        (
+            transactionid text not null,
+            lotid text not null,
+            quantity text not null,
+            primary key(transactionid, lotid)
+        );
+	`
+	_, err = db.Exec(sqlStmt)
+	if err != nil {
+		return nil, fmt.Errorf("can't insert table: %w", err)
+	}
+
+	sqlStmt = `
+        CREATE TABLE IF NOT EXISTS transaction_fees
+        (
+            transactionid text not null,
+            position integer not null,
+            type text not null,
+            amount text not null,
+            currency text not null default '',
+            primary key(transactionid, position)
+        );
+	`
+	_, err = db.Exec(sqlStmt)
+	if err != nil {
+		return nil, fmt.Errorf("can't insert table: %w", err)
+	}
+
+	if err := createCorporateActionsTable(db); err != nil {
+		return nil, err
+	}
+
+	if err := createAssetPrecisionsTable(db); err != nil {
+		return nil, err
+	}
+
+	if err := createInstrumentsTables(db); err != nil {
+		return nil, err
+	}
+
+	if err := createGroupsTables(db); err != nil {
+		return nil, err
+	}
+
+	sqlStmt = `
+        CREATE TABLE IF NOT EXISTS portfolio_targets
+        (
+            portfolioid text not null,
+            key text not null,
+            name text not null,
+            weight text not null,
+            position integer not null,
+            primary key(portfolioid, key, name)
+        );
+	`
+	if _, err := db.Exec(sqlStmt); err != nil {
+		return nil, fmt.Errorf("can't insert table: %w", err)
+	}
+
+	if err := addColumn(db, "portfolios", "costbasis", "text not null default 'fifo'"); err != nil {
+		return nil, fmt.Errorf("can't migrate table: %w", err)
+	}
+	if err := addColumn(db, "portfolios", "overdraft", "integer not null default 1"); err != nil {
+		return nil, fmt.Errorf("can't migrate table: %w", err)
+	}
+	if err := addColumn(db, "portfolios", "currency", "text not null default 'USD'"); err != nil {
+		return nil, fmt.Errorf("can't migrate table: %w", err)
+	}
+	if err := addColumn(db, "portfolios", "tolerance", "text not null default '0'"); err != nil {
+		return nil, fmt.Errorf("can't migrate table: %w", err)
+	}
+	if err := addColumn(db, "portfolios", "mintrade", "text not null default '0'"); err != nil {
+		return nil, fmt.Errorf("can't migrate table: %w", err)
+	}
+	if err := addColumn(db, "portfolios", "shortselling", "integer not null default 0"); err != nil {
+		return nil, fmt.Errorf("can't migrate table: %w", err)
+	}
+	if err := addColumn(db, "portfolios", "washsalerule", "integer not null default 0"); err != nil {
+		return nil, fmt.Errorf("can't migrate table: %w", err)
+	}
+	if err := addColumn(db, "transactions", "kind", "text not null default ''"); err != nil {
+		return nil, fmt.Errorf("can't migrate table: %w", err)
+	}
+	if err := addColumn(db, "transactions", "amount", "text not null default '0'"); err != nil {
+		return nil, fmt.Errorf("can't migrate table: %w", err)
+	}
+	// transactions recorded before kinds were introduced are trades
+	sqlStmt = "UPDATE transactions SET kind = CASE WHEN quantity > 0 THEN 'buy' ELSE 'sell' END WHERE kind = ''"
+	if _, err := db.Exec(sqlStmt); err != nil {
+		return nil, fmt.Errorf("can't migrate table: %w", err)
+	}
+	if err := addColumn(db, "transactions", "currency", "text not null default ''"); err != nil {
+		return nil, fmt.Errorf("can't migrate table: %w", err)
+	}
+	if err := addColumn(db, "transactions", "instrumentid", "text not null default ''"); err != nil {
+		return nil, fmt.Errorf("can't migrate table: %w", err)
+	}
+	if err := addColumn(db, "transactions", "transferportfolioid", "text not null default ''"); err != nil {
+		return nil, fmt.Errorf("can't migrate table: %w", err)
+	}
+	if err := addColumn(db, "transactions", "transferid", "text not null default ''"); err != nil {
+		return nil, fmt.Errorf("can't migrate table: %w", err)
+	}
+	if err := addColumn(db, "transactions", "multiplier", "text not null default '1'"); err != nil {
+		return nil, fmt.Errorf("can't migrate table: %w", err)
+	}
+	if err := addColumn(db, "transactions", "premium", "text not null default '0'"); err != nil {
+		return nil, fmt.Errorf("can't migrate table: %w", err)
+	}
+	if err := addColumn(db, "transactions", "accrued", "text not null default '0'"); err != nil {
+		return nil, fmt.Errorf("can't migrate table: %w", err)
+	}
+	if err := addColumn(db, "transactions", "pending", "integer not null default 0"); err != nil {
+		return nil, fmt.Errorf("can't migrate table: %w", err)
+	}
+	if err := addColumn(db, "transaction_lots", "date", "text not null default ''"); err != nil {
+		return nil, fmt.Errorf("can't migrate table: %w", err)
+	}
+	if err := addColumn(db, "transaction_lots", "price", "text not null default '0'"); err != nil {
+		return nil, fmt.Errorf("can't migrate table: %w", err)
+	}
+	// transactions recorded before currencies were introduced are settled in the currency of their portfolio
+	sqlStmt = `
+        UPDATE transactions SET currency = (SELECT currency FROM portfolios WHERE portfolios.id = transactions.portfolioid)
+        WHERE currency = ''
+	`
+	if _, err := db.Exec(sqlStmt); err != nil {
+		return nil, fmt.Errorf("can't migrate table: %w", err)
+	}
+	// numbers are kept as text, so that decimals are stored exactly
+	if err := changeColumnsToText(db, "transactions", "price", "quantity", "amount"); err != nil {
+		return nil, fmt.Errorf("can't migrate table: %w", err)
+	}
+	if err := changeColumnsToText(db, "transaction_lots", "quantity"); err != nil {
+		return nil, fmt.Errorf("can't migrate table: %w", err)
+	}
+	if err := changeColumnsToText(db, "transaction_fees", "amount"); err != nil {
+		return nil, fmt.Errorf("can't migrate table: %w", err)
+	}
+
 	r := &SQLitePortfolioRepository{db: db}
 	return r, nil
 }
@@ -83,8 +267,8 @@ func (r *SQLitePortfolioRepository) CreatePortfolio(ctx context.Context, p *port
 
 	pm := portfolioToPortfolioModel(p)
 
-	sqlStmt := `insert into portfolios (id, userid, name) values ($1, $2, $3)`
-	if _, err := tx.ExecContext(ctx, sqlStmt, pm.ID, pm.UserID, pm.Name); err != nil {
+	sqlStmt := `insert into portfolios (id, userid, name, costbasis, overdraft, shortselling, washsalerule, currency) values ($1, $2, $3, $4, $5, $6, $7, $8)`
+	if _, err := tx.ExecContext(ctx, sqlStmt, pm.ID, pm.UserID, pm.Name, pm.CostBasisMethod, pm.Overdraft, pm.ShortSelling, pm.WashSaleRule, pm.Currency); err != nil {
 		return fmt.Errorf("can't create portfolio: %w", err)
 	}
 
@@ -101,6 +285,9 @@ func (r *SQLitePortfolioRepository) GetPortfolio(ctx context.Context, userID, id
 	if err != nil {
 		return nil, fmt.Errorf("can't find portfolio with id %s: %w", id.String(), err)
 	}
+	if pm.Targets, err = r.getTargets(ctx, r.db, pm.ID); err != nil {
+		return nil, fmt.Errorf("can't find portfolio with id %s: %w", id.String(), err)
+	}
 
 	trms, err := r.getAllTransactions(ctx, r.db, pm.UserID, pm.ID, false)
 	if err != nil {
@@ -111,10 +298,43 @@ func (r *SQLitePortfolioRepository) GetPortfolio(ctx context.Context, userID, id
 		return nil, fmt.Errorf("can't find portfolio with id %s: %w", id.String(), err)
 	}
 
-	p, err := portfolio.NewPortfolio(pm.ID, pm.UserID, pm.Name, trs)
+	p, err := portfolioModelToPortfolio(pm, trs)
+	if err != nil {
+		return nil, fmt.Errorf("can't find portfolio with id %s: %w", id.String(), err)
+	}
+	actions, err := getCorporateActions(ctx, r.db)
 	if err != nil {
 		return nil, fmt.Errorf("can't find portfolio with id %s: %w", id.String(), err)
 	}
+	p.SetCorporateActions(actions)
+	own, err := getPortfolioActions(ctx, r.db, p.ID())
+	if err != nil {
+		return nil, fmt.Errorf("can't find portfolio with id %s: %w", id.String(), err)
+	}
+	p.SetPortfolioActions(own)
+	precisions, err := getPrecisions(ctx, r.db)
+	if err != nil {
+		return nil, fmt.Errorf("can't find portfolio with id %s: %w", id.String(), err)
+	}
+	p.SetPrecisions(precisions)
+	contracts, err := getContracts(ctx, r.db)
+	if err != nil {
+		return nil, fmt.Errorf("can't find portfolio with id %s: %w", id.String(), err)
+	}
+	p.SetContracts(contracts)
+	bonds, err := getBonds(ctx, r.db)
+	if err != nil {
+		return nil, fmt.Errorf("can't find portfolio with id %s: %w", id.String(), err)
+	}
+	p.SetBonds(bonds)
+	washSales, err := r.getWashSales(ctx, r.db, userID)
+	if err != nil {
+		return nil, fmt.Errorf("can't find portfolio with id %s: %w", id.String(), err)
+	}
+	p.SetWashSales(washSales)
+	if err := p.ChangeOverdraft(pm.Overdraft); err != nil {
+		return nil, fmt.Errorf("can't find portfolio with id %s: %w", id.String(), err)
+	}
 
 	return p, nil
 }
@@ -127,10 +347,13 @@ func (r *SQLitePortfolioRepository) GetAllPortfolios(ctx context.Context, userID
 
 	portfolios := []*portfolio.Portfolio{}
 	for _, pm := range pms {
-		p, err := portfolio.NewPortfolio(pm.ID, pm.UserID, pm.Name, nil)
+		p, err := portfolioModelToPortfolio(pm, nil)
 		if err != nil {
 			return nil, fmt.Errorf("can't list portfolio %s for user %s: %w", pm.ID, userID.String(), err)
 		}
+		if err := p.ChangeOverdraft(pm.Overdraft); err != nil {
+			return nil, fmt.Errorf("can't list portfolio %s for user %s: %w", pm.ID, userID.String(), err)
+		}
 		portfolios = append(portfolios, p)
 	}
 
@@ -158,48 +381,200 @@ func (r *SQLitePortfolioRepository) UpdatePortfolio(ctx context.Context, userID,
 	}
 	defer tx.Rollback()
 
-	// TODO combine with GetPortfolio and nmove to function
-	pm, err := r.getPortfolio(ctx, tx, userID, id, true)
+	p, err := r.loadPortfolio(ctx, tx, userID, id)
 	if err != nil {
 		return fmt.Errorf("can't update portfolio %s: %w", id.String(), err)
 	}
-	trms, err := r.getAllTransactions(ctx, r.db, pm.UserID, pm.ID, false)
-	if err != nil {
+
+	if err := updateFn(p); err != nil {
 		return fmt.Errorf("can't update portfolio %s: %w", id.String(), err)
 	}
-	trs, err := transactionModelToTransactions(trms)
-	if err != nil {
+
+	if err := r.savePortfolio(ctx, tx, p); err != nil {
 		return fmt.Errorf("can't update portfolio %s: %w", id.String(), err)
 	}
-	p, err := portfolio.NewPortfolio(pm.ID, pm.UserID, pm.Name, trs)
-	if err != nil {
+
+	if err := tx.Commit(); err != nil {
 		return fmt.Errorf("can't update portfolio %s: %w", id.String(), err)
 	}
 
-	if err := updateFn(p); err != nil {
-		return fmt.Errorf("can't update portfolio %s: %w", id.String(), err)
+	return nil
+}
+
+func (r *SQLitePortfolioRepository) UpdatePortfolios(ctx context.Context, userID uuid.UUID, ids []uuid.UUID, updateFn func(ps []*portfolio.Portfolio) error) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("can't update portfolios: %w", err)
 	}
+	defer tx.Rollback()
 
-	pm = portfolioToPortfolioModel(p)
-	trms = portfolioToTransactionModel(p)
+	ps := []*portfolio.Portfolio{}
+	for _, id := range ids {
+		p, err := r.loadPortfolio(ctx, tx, userID, id)
+		if err != nil {
+			return fmt.Errorf("can't update portfolio %s: %w", id.String(), err)
+		}
+		ps = append(ps, p)
+	}
 
-	sqlStmt := "update portfolios set name = $3 where userid = $1 and id = $2"
-	if _, err := tx.ExecContext(ctx, sqlStmt, pm.UserID, pm.ID, pm.Name); err != nil {
-		return fmt.Errorf("can't update portfolio %s: %w", id.String(), err)
+	if err := updateFn(ps); err != nil {
+		return fmt.Errorf("can't update portfolios: %w", err)
 	}
 
-	err = r.upsertTransactions(ctx, tx, trms)
-	if err != nil {
-		return fmt.Errorf("can't update portfolio %s: %w", id.String(), err)
+	for _, p := range ps {
+		if err := r.savePortfolio(ctx, tx, p); err != nil {
+			return fmt.Errorf("can't update portfolio %s: %w", p.ID().String(), err)
+		}
 	}
 
 	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("can't update portfolio %s: %w", id.String(), err)
+		return fmt.Errorf("can't update portfolios: %w", err)
 	}
 
 	return nil
 }
 
+func (r *SQLitePortfolioRepository) loadPortfolio(ctx context.Context, tx *sql.Tx, userID, id uuid.UUID) (*portfolio.Portfolio, error) {
+	pm, err := r.getPortfolio(ctx, tx, userID, id, true)
+	if err != nil {
+		return nil, err
+	}
+	if pm.Targets, err = r.getTargets(ctx, tx, pm.ID); err != nil {
+		return nil, err
+	}
+	trms, err := r.getAllTransactions(ctx, tx, pm.UserID, pm.ID, true)
+	if err != nil {
+		return nil, err
+	}
+	trs, err := transactionModelToTransactions(trms)
+	if err != nil {
+		return nil, err
+	}
+	p, err := portfolioModelToPortfolio(pm, trs)
+	if err != nil {
+		return nil, err
+	}
+	actions, err := getCorporateActions(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	p.SetCorporateActions(actions)
+	own, err := getPortfolioActions(ctx, tx, p.ID())
+	if err != nil {
+		return nil, err
+	}
+	p.SetPortfolioActions(own)
+	precisions, err := getPrecisions(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	p.SetPrecisions(precisions)
+	contracts, err := getContracts(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	p.SetContracts(contracts)
+	bonds, err := getBonds(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	p.SetBonds(bonds)
+	washSales, err := r.getWashSales(ctx, tx, userID)
+	if err != nil {
+		return nil, err
+	}
+	p.SetWashSales(washSales)
+	if err := p.ChangeOverdraft(pm.Overdraft); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (r *SQLitePortfolioRepository) getWashSales(ctx context.Context, db querier, userID uuid.UUID) ([]portfolio.WashSale, error) {
+	pms, err := r.getAllPortfolios(ctx, db, userID, false)
+	if err != nil {
+		return nil, fmt.Errorf("can't find wash sales: %w", err)
+	}
+	applied := false
+	for _, pm := range pms {
+		applied = applied || pm.WashSaleRule
+	}
+	if !applied {
+		return []portfolio.WashSale{}, nil
+	}
+	actions, err := getCorporateActions(ctx, db)
+	if err != nil {
+		return nil, fmt.Errorf("can't find wash sales: %w", err)
+	}
+	precisions, err := getPrecisions(ctx, db)
+	if err != nil {
+		return nil, fmt.Errorf("can't find wash sales: %w", err)
+	}
+	contracts, err := getContracts(ctx, db)
+	if err != nil {
+		return nil, fmt.Errorf("can't find wash sales: %w", err)
+	}
+	bonds, err := getBonds(ctx, db)
+	if err != nil {
+		return nil, fmt.Errorf("can't find wash sales: %w", err)
+	}
+
+	portfolios := []*portfolio.Portfolio{}
+	for _, pm := range pms {
+		trms, err := r.getAllTransactions(ctx, db, userID, pm.ID, false)
+		if err != nil {
+			return nil, fmt.Errorf("can't find wash sales: %w", err)
+		}
+		trs, err := transactionModelToTransactions(trms)
+		if err != nil {
+			return nil, fmt.Errorf("can't find wash sales: %w", err)
+		}
+		p, err := portfolioModelToPortfolio(pm, trs)
+		if err != nil {
+			return nil, fmt.Errorf("can't find wash sales: %w", err)
+		}
+		p.SetCorporateActions(actions)
+		own, err := getPortfolioActions(ctx, db, p.ID())
+		if err != nil {
+			return nil, fmt.Errorf("can't find wash sales: %w", err)
+		}
+		p.SetPortfolioActions(own)
+		p.SetPrecisions(precisions)
+		p.SetContracts(contracts)
+		p.SetBonds(bonds)
+		portfolios = append(portfolios, p)
+	}
+	return portfolio.DetectWashSales(portfolios)
+}
+
+func (r *SQLitePortfolioRepository) savePortfolio(ctx context.Context, tx *sql.Tx, p *portfolio.Portfolio) error {
+	pm := portfolioToPortfolioModel(p)
+	trms := portfolioToTransactionModel(p)
+
+	sqlStmt := `
+        update portfolios set name = $1, costbasis = $2, overdraft = $3, shortselling = $4, washsalerule = $5, currency = $6, tolerance = $7, mintrade = $8
+        where userid = $9 and id = $10
+	`
+	if _, err := tx.ExecContext(ctx, sqlStmt, pm.Name, pm.CostBasisMethod, pm.Overdraft, pm.ShortSelling, pm.WashSaleRule, pm.Currency, pm.Tolerance, pm.MinTrade, pm.UserID, pm.ID); err != nil {
+		return err
+	}
+
+	if err := r.upsertTargets(ctx, tx, pm); err != nil {
+		return err
+	}
+
+	if err := r.insertPortfolioActions(ctx, tx, p); err != nil {
+		return err
+	}
+
+	// pending orders are written anew, the cancelled ones are gone and the filled ones are stored as executed
+	if err := r.deletePendingTransactions(ctx, tx, pm); err != nil {
+		return err
+	}
+
+	return r.upsertTransactions(ctx, tx, trms)
+}
+
 func (r *SQLitePortfolioRepository) DeletePortfolio(ctx context.Context, userID, id uuid.UUID) error {
 	tx, err := r.db.Begin()
 	if err != nil {
@@ -207,7 +582,32 @@ func (r *SQLitePortfolioRepository) DeletePortfolio(ctx context.Context, userID,
 	}
 	defer tx.Rollback()
 
-	sqlStmt := "DELETE FROM transactions WHERE userid=$1 AND portfolioid=$2"
+	sqlStmt := "DELETE FROM transaction_lots WHERE transactionid IN (SELECT id FROM transactions WHERE userid=$1 AND portfolioid=$2)"
+	if _, err := tx.ExecContext(ctx, sqlStmt, userID, id); err != nil {
+		return fmt.Errorf("can't delete portfolio %s: %w", id.String(), err)
+	}
+
+	sqlStmt = "DELETE FROM transaction_fees WHERE transactionid IN (SELECT id FROM transactions WHERE userid=$1 AND portfolioid=$2)"
+	if _, err := tx.ExecContext(ctx, sqlStmt, userID, id); err != nil {
+		return fmt.Errorf("can't delete portfolio %s: %w", id.String(), err)
+	}
+
+	sqlStmt = "DELETE FROM transactions WHERE userid=$1 AND portfolioid=$2"
+	if _, err := tx.ExecContext(ctx, sqlStmt, userID, id); err != nil {
+		return fmt.Errorf("can't delete portfolio %s: %w", id.String(), err)
+	}
+
+	sqlStmt = "DELETE FROM portfolio_targets WHERE portfolioid IN (SELECT id FROM portfolios WHERE userid=$1 AND id=$2)"
+	if _, err := tx.ExecContext(ctx, sqlStmt, userID, id); err != nil {
+		return fmt.Errorf("can't delete portfolio %s: %w", id.String(), err)
+	}
+
+	sqlStmt = "DELETE FROM corporate_actions WHERE portfolioid IN (SELECT id FROM portfolios WHERE userid=$1 AND id=$2)"
+	if _, err := tx.ExecContext(ctx, sqlStmt, userID, id); err != nil {
+		return fmt.Errorf("can't delete portfolio %s: %w", id.String(), err)
+	}
+
+	sqlStmt = "DELETE FROM portfolio_group_members WHERE portfolioid IN (SELECT id FROM portfolios WHERE userid=$1 AND id=$2)"
 	if _, err := tx.ExecContext(ctx, sqlStmt, userID, id); err != nil {
 		return fmt.Errorf("can't delete portfolio %s: %w", id.String(), err)
 	}
@@ -224,13 +624,45 @@ func (r *SQLitePortfolioRepository) DeletePortfolio(ctx context.Context, userID,
 	return nil
 }
 
+func (r *SQLitePortfolioRepository) insertPortfolioActions(ctx context.Context, tx *sql.Tx, p *portfolio.Portfolio) error {
+	sqlStmt := `
+        INSERT INTO
+        corporate_actions(id, date, type, asset, newasset, ratiofrom, ratioto, costallocation, cashprice, portfolioid)
+        VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+        ON CONFLICT(id) DO NOTHING
+	`
+	for _, a := range p.PortfolioActions() {
+		am := corporateActionToCorporateActionModel(a)
+		if _, err := tx.ExecContext(ctx, sqlStmt, am.ID, am.DateString, am.Type, am.Asset, am.NewAsset, am.From, am.To, am.CostAllocation, am.CashPrice, p.ID()); err != nil {
+			return fmt.Errorf("can't add corporate action %s: %w", am.ID.String(), err)
+		}
+	}
+	return nil
+}
+
+func (r *SQLitePortfolioRepository) deletePendingTransactions(ctx context.Context, tx *sql.Tx, pm *portfolioModel) error {
+	for _, sqlStmt := range []string{
+		"DELETE FROM transaction_lots WHERE transactionid IN (SELECT id FROM transactions WHERE userid=$1 AND portfolioid=$2 AND pending=1)",
+		"DELETE FROM transaction_fees WHERE transactionid IN (SELECT id FROM transactions WHERE userid=$1 AND portfolioid=$2 AND pending=1)",
+		"DELETE FROM transactions WHERE userid=$1 AND portfolioid=$2 AND pending=1",
+	} {
+		if _, err := tx.ExecContext(ctx, sqlStmt, pm.UserID, pm.ID); err != nil {
+			return fmt.Errorf("can't delete pending transactions: %w", err)
+		}
+	}
+	return nil
+}
+
 func (r *SQLitePortfolioRepository) upsertTransactions(ctx context.Context, tx *sql.Tx, trms []*transactionModel) error {
 	sqlStmt := `
         INSERT INTO
-        transactions(id, userid, portfolioid, date, asset, price, quantity)
-        VALUES($1, $2, $3, $4, $5, $6, $7)
+        transactions(id, userid, portfolioid, date, kind, asset, price, quantity, amount, currency, instrumentid, multiplier, premium, accrued, pending, transferportfolioid, transferid)
+        VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
         ON CONFLICT(id) DO UPDATE SET
-        date=excluded.date, asset=excluded.asset, price=excluded.price, quantity=excluded.quantity
+        date=excluded.date, kind=excluded.kind, asset=excluded.asset, price=excluded.price,
+        quantity=excluded.quantity, amount=excluded.amount, currency=excluded.currency, instrumentid=excluded.instrumentid,
+        multiplier=excluded.multiplier, premium=excluded.premium, accrued=excluded.accrued, pending=excluded.pending,
+        transferportfolioid=excluded.transferportfolioid, transferid=excluded.transferid
 	`
 	stmt, err := tx.Prepare(sqlStmt)
 	if err != nil {
@@ -239,7 +671,13 @@ func (r *SQLitePortfolioRepository) upsertTransactions(ctx context.Context, tx *
 	defer stmt.Close()
 
 	for _, trm := range trms {
-		if _, err := stmt.ExecContext(ctx, trm.ID, trm.UserID, trm.PortfolioID, trm.DateString, trm.Asset, trm.Price, trm.Quantity); err != nil {
+		if _, err := stmt.ExecContext(ctx, trm.ID, trm.UserID, trm.PortfolioID, trm.DateString, trm.Kind, trm.Asset, trm.Price, trm.Quantity, trm.Amount, trm.Currency, trm.InstrumentID, trm.Multiplier, trm.Premium, trm.Accrued, trm.Pending, trm.TransferPortfolioID, trm.TransferID); err != nil {
+			return fmt.Errorf("can't upsert transaction %s: %w", trm.ID.String(), err)
+		}
+		if err := r.upsertLots(ctx, tx, trm); err != nil {
+			return fmt.Errorf("can't upsert transaction %s: %w", trm.ID.String(), err)
+		}
+		if err := r.upsertFees(ctx, tx, trm); err != nil {
 			return fmt.Errorf("can't upsert transaction %s: %w", trm.ID.String(), err)
 		}
 	}
@@ -247,29 +685,110 @@ func (r *SQLitePortfolioRepository) upsertTransactions(ctx context.Context, tx *
 	return nil
 }
 
+func (r *SQLitePortfolioRepository) upsertLots(ctx context.Context, tx *sql.Tx, trm *transactionModel) error {
+	sqlStmt := "DELETE FROM transaction_lots WHERE transactionid=$1"
+	if _, err := tx.ExecContext(ctx, sqlStmt, trm.ID); err != nil {
+		return fmt.Errorf("can't upsert lots: %w", err)
+	}
+
+	sqlStmt = "INSERT INTO transaction_lots(transactionid, lotid, quantity, date, price) VALUES($1, $2, $3, $4, $5)"
+	for _, lm := range trm.Lots {
+		if _, err := tx.ExecContext(ctx, sqlStmt, trm.ID, lm.LotID, lm.Quantity, lm.DateString, lm.Price); err != nil {
+			return fmt.Errorf("can't upsert lot %s: %w", lm.LotID.String(), err)
+		}
+	}
+
+	return nil
+}
+
+func (r *SQLitePortfolioRepository) upsertFees(ctx context.Context, tx *sql.Tx, trm *transactionModel) error {
+	sqlStmt := "DELETE FROM transaction_fees WHERE transactionid=$1"
+	if _, err := tx.ExecContext(ctx, sqlStmt, trm.ID); err != nil {
+		return fmt.Errorf("can't upsert fees: %w", err)
+	}
+
+	sqlStmt = "INSERT INTO transaction_fees(transactionid, position, type, amount, currency) VALUES($1, $2, $3, $4, $5)"
+	for i, fm := range trm.Fees {
+		if _, err := tx.ExecContext(ctx, sqlStmt, trm.ID, i, fm.Type, fm.Amount, fm.Currency); err != nil {
+			return fmt.Errorf("can't upsert fee %s: %w", fm.Type, err)
+		}
+	}
+
+	return nil
+}
+
+func (r *SQLitePortfolioRepository) upsertTargets(ctx context.Context, tx *sql.Tx, pm *portfolioModel) error {
+	sqlStmt := "DELETE FROM portfolio_targets WHERE portfolioid=$1"
+	if _, err := tx.ExecContext(ctx, sqlStmt, pm.ID); err != nil {
+		return fmt.Errorf("can't upsert targets: %w", err)
+	}
+
+	sqlStmt = "INSERT INTO portfolio_targets(portfolioid, key, name, weight, position) VALUES($1, $2, $3, $4, $5)"
+	for i, tm := range pm.Targets {
+		if _, err := tx.ExecContext(ctx, sqlStmt, pm.ID, tm.Key, tm.Name, tm.Weight, i); err != nil {
+			return fmt.Errorf("can't upsert target %s: %w", tm.Name, err)
+		}
+	}
+
+	return nil
+}
+
 func (r *SQLitePortfolioRepository) getPortfolio(ctx context.Context, db rowQuerier, userID, id uuid.UUID, forUpdate bool) (*portfolioModel, error) {
-	sqlStmt := "select name from portfolios where userid = $1 and id = $2"
+	sqlStmt := "select name, costbasis, overdraft, shortselling, washsalerule, currency, tolerance, mintrade from portfolios where userid = $1 and id = $2"
 	// if forUpdate {
 	// 	sqlStmt += " for update"
 	// }
 	row := db.QueryRowContext(ctx, sqlStmt, userID, id)
 
-	var name string
-	if err := row.Scan(&name); err != nil {
+	var (
+		name, costBasis, currency             string
+		overdraft, shortSelling, washSaleRule bool
+		tolerance, minTrade                   decimal.Decimal
+	)
+	if err := row.Scan(&name, &costBasis, &overdraft, &shortSelling, &washSaleRule, &currency, &tolerance, &minTrade); err != nil {
 		return nil, fmt.Errorf("portfolio %s not found: %w", id.String(), err)
 	}
 
 	pm := &portfolioModel{
-		ID:     id,
-		UserID: userID,
-		Name:   name,
+		ID:              id,
+		UserID:          userID,
+		Name:            name,
+		CostBasisMethod: costBasis,
+		Overdraft:       overdraft,
+		ShortSelling:    shortSelling,
+		WashSaleRule:    washSaleRule,
+		Currency:        currency,
+		Tolerance:       tolerance,
+		MinTrade:        minTrade,
 	}
 
 	return pm, nil
 }
 
+func (r *SQLitePortfolioRepository) getTargets(ctx context.Context, db querier, portfolioID uuid.UUID) ([]targetModel, error) {
+	sqlStmt := "select key, name, weight from portfolio_targets where portfolioid = $1 order by position"
+	rows, err := db.QueryContext(ctx, sqlStmt, portfolioID)
+	if err != nil {
+		return nil, fmt.Errorf("can't list targets: %w", err)
+	}
+	defer rows.Close()
+
+	tms := []targetModel{}
+	for rows.Next() {
+		var tm targetModel
+		if err := rows.Scan(&tm.Key, &tm.Name, &tm.Weight); err != nil {
+			return nil, fmt.Errorf("can't list targets: %w", err)
+		}
+		tms = append(tms, tm)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("can't list targets: %w", err)
+	}
+	return tms, nil
+}
+
 func (r *SQLitePortfolioRepository) getAllPortfolios(ctx context.Context, db querier, userID uuid.UUID, forUpdate bool) ([]*portfolioModel, error) {
-	sqlStmt := `select id, name from portfolios where userid = $1`
+	sqlStmt := `select id, name, costbasis, overdraft, shortselling, washsalerule, currency from portfolios where userid = $1`
 	// if forUpdate {
 	// 	sqlStmt += " for update"
 	// }
@@ -282,17 +801,27 @@ func (r *SQLitePortfolioRepository) getAllPortfolios(ctx context.Context, db que
 	pms := []*portfolioModel{}
 	for rows.Next() {
 		var (
-			id   uuid.UUID
-			name string
+			id           uuid.UUID
+			name         string
+			costBasis    string
+			overdraft    bool
+			shortSelling bool
+			washSaleRule bool
+			currency     string
 		)
-		err := rows.Scan(&id, &name)
+		err := rows.Scan(&id, &name, &costBasis, &overdraft, &shortSelling, &washSaleRule, &currency)
 		if err != nil {
 			return nil, fmt.Errorf("can't list portfolios for user %s: %w", userID.String(), err)
 		}
 		pms = append(pms, &portfolioModel{
-			ID:     id,
-			UserID: userID,
-			Name:   name,
+			ID:              id,
+			UserID:          userID,
+			Name:            name,
+			CostBasisMethod: costBasis,
+			Overdraft:       overdraft,
+			ShortSelling:    shortSelling,
+			WashSaleRule:    washSaleRule,
+			Currency:        currency,
 		})
 	}
 	err = rows.Err()
@@ -304,7 +833,10 @@ func (r *SQLitePortfolioRepository) getAllPortfolios(ctx context.Context, db que
 }
 
 func (r *SQLitePortfolioRepository) getAllTransactions(ctx context.Context, db querier, userID, portfolioID uuid.UUID, forUpdate bool) ([]*transactionModel, error) {
-	sqlStmt := `select id, asset, quantity, price, date from transactions where userid = $1 and portfolioid = $2`
+	sqlStmt := `
+        select id, kind, asset, quantity, price, amount, currency, instrumentid, multiplier, premium, accrued, pending, transferportfolioid, transferid, date
+        from transactions where userid = $1 and portfolioid = $2
+	`
 	// if forUpdate {
 	// 	sqlStmt += " for update"
 	// }
@@ -317,30 +849,61 @@ func (r *SQLitePortfolioRepository) getAllTransactions(ctx context.Context, db q
 	trms := []*transactionModel{}
 	for rows.Next() {
 		var (
-			id         uuid.UUID
-			asset      string
-			quantity   int
-			price      float64
-			dateString string
+			id           uuid.UUID
+			kind         string
+			asset        string
+			quantity     decimal.Decimal
+			price        decimal.Decimal
+			amount       decimal.Decimal
+			currency     string
+			instrumentID string
+			multiplier   decimal.Decimal
+			premium      decimal.Decimal
+			accrued      decimal.Decimal
+			pending      bool
+			transferPID  string
+			transferID   string
+			dateString   string
 		)
 		err := rows.Scan(
 			&id,
+			&kind,
 			&asset,
 			&quantity,
 			&price,
+			&amount,
+			&currency,
+			&instrumentID,
+			&multiplier,
+			&premium,
+			&accrued,
+			&pending,
+			&transferPID,
+			&transferID,
 			&dateString,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("can't list transactions for portfolio %s: %w", portfolioID.String(), err)
 		}
 		trms = append(trms, &transactionModel{
-			ID:          id,
-			UserID:      userID,
-			PortfolioID: portfolioID,
-			Asset:       asset,
-			Quantity:    quantity,
-			Price:       price,
-			DateString:  dateString,
+			ID:           id,
+			UserID:       userID,
+			PortfolioID:  portfolioID,
+			Kind:         kind,
+			Asset:        asset,
+			Quantity:     quantity,
+			Price:        price,
+			Amount:       amount,
+			Currency:     currency,
+			InstrumentID: instrumentID,
+			Multiplier:   multiplier,
+			Premium:      premium,
+			Accrued:      accrued,
+			Pending:      pending,
+			DateString:   dateString,
+
+			TransferPortfolioID: transferPID,
+			TransferID:          transferID,
 		})
 	}
 	err = rows.Err()
@@ -348,29 +911,172 @@ func (r *SQLitePortfolioRepository) getAllTransactions(ctx context.Context, db q
 		return nil, fmt.Errorf("can't list transactions for portfolio %s: %w", portfolioID.String(), err)
 	}
 
+	if err := r.getLots(ctx, db, userID, portfolioID, trms); err != nil {
+		return nil, fmt.Errorf("can't list transactions for portfolio %s: %w", portfolioID.String(), err)
+	}
+
+	if err := r.getFees(ctx, db, userID, portfolioID, trms); err != nil {
+		return nil, fmt.Errorf("can't list transactions for portfolio %s: %w", portfolioID.String(), err)
+	}
+
 	return trms, nil
 }
 
+func (r *SQLitePortfolioRepository) getLots(ctx context.Context, db querier, userID, portfolioID uuid.UUID, trms []*transactionModel) error {
+	sqlStmt := `
+        select l.transactionid, l.lotid, l.quantity, l.date, l.price
+        from transaction_lots l join transactions t on t.id = l.transactionid
+        where t.userid = $1 and t.portfolioid = $2
+	`
+	rows, err := db.QueryContext(ctx, sqlStmt, userID, portfolioID)
+	if err != nil {
+		return fmt.Errorf("can't list lots: %w", err)
+	}
+	defer rows.Close()
+
+	byID := map[uuid.UUID]*transactionModel{}
+	for _, trm := range trms {
+		byID[trm.ID] = trm
+	}
+	for rows.Next() {
+		var (
+			transactionID uuid.UUID
+			lm            lotModel
+		)
+		if err := rows.Scan(&transactionID, &lm.LotID, &lm.Quantity, &lm.DateString, &lm.Price); err != nil {
+			return fmt.Errorf("can't list lots: %w", err)
+		}
+		if trm, ok := byID[transactionID]; ok {
+			trm.Lots = append(trm.Lots, lm)
+		}
+	}
+	return rows.Err()
+}
+
+func (r *SQLitePortfolioRepository) getFees(ctx context.Context, db querier, userID, portfolioID uuid.UUID, trms []*transactionModel) error {
+	sqlStmt := `
+        select f.transactionid, f.type, f.amount, f.currency
+        from transaction_fees f join transactions t on t.id = f.transactionid
+        where t.userid = $1 and t.portfolioid = $2
+        order by f.transactionid, f.position
+	`
+	rows, err := db.QueryContext(ctx, sqlStmt, userID, portfolioID)
+	if err != nil {
+		return fmt.Errorf("can't list fees: %w", err)
+	}
+	defer rows.Close()
+
+	byID := map[uuid.UUID]*transactionModel{}
+	for _, trm := range trms {
+		byID[trm.ID] = trm
+	}
+	for rows.Next() {
+		var (
+			transactionID uuid.UUID
+			fm            feeModel
+		)
+		if err := rows.Scan(&transactionID, &fm.Type, &fm.Amount, &fm.Currency); err != nil {
+			return fmt.Errorf("can't list fees: %w", err)
+		}
+		if trm, ok := byID[transactionID]; ok {
+			trm.Fees = append(trm.Fees, fm)
+		}
+	}
+	return rows.Err()
+}
+
 func portfolioToPortfolioModel(p *portfolio.Portfolio) *portfolioModel {
+	policy := p.RebalancePolicy()
+	tms := []targetModel{}
+	for _, t := range policy.Targets {
+		tms = append(tms, targetModel{Key: string(t.Key), Name: t.Name, Weight: t.Weight})
+	}
 	return &portfolioModel{
-		ID:     p.ID(),
-		UserID: p.UserID(),
-		Name:   p.Name(),
+		ID:              p.ID(),
+		UserID:          p.UserID(),
+		Name:            p.Name(),
+		CostBasisMethod: string(p.CostBasisMethod()),
+		Overdraft:       p.Overdraft(),
+		ShortSelling:    p.ShortSelling(),
+		WashSaleRule:    p.WashSaleRule(),
+		Currency:        p.Currency(),
+		Tolerance:       policy.Tolerance,
+		MinTrade:        policy.MinTrade,
+		Targets:         tms,
 	}
 }
 
+func portfolioModelToPortfolio(pm *portfolioModel, trs []*portfolio.Transaction) (*portfolio.Portfolio, error) {
+	p, err := portfolio.NewPortfolio(pm.ID, pm.UserID, pm.Name, trs)
+	if err != nil {
+		return nil, fmt.Errorf("incorrect portfolio parameter: %w", err)
+	}
+	method, err := portfolio.ParseCostBasisMethod(pm.CostBasisMethod)
+	if err != nil {
+		return nil, fmt.Errorf("incorrect portfolio parameter: %w", err)
+	}
+	if err := p.ChangeCostBasisMethod(method); err != nil {
+		return nil, fmt.Errorf("incorrect portfolio parameter: %w", err)
+	}
+	if err := p.ChangeCurrency(pm.Currency); err != nil {
+		return nil, fmt.Errorf("incorrect portfolio parameter: %w", err)
+	}
+	if err := p.ChangeShortSelling(pm.ShortSelling); err != nil {
+		return nil, fmt.Errorf("incorrect portfolio parameter: %w", err)
+	}
+	p.ChangeWashSaleRule(pm.WashSaleRule)
+	policy := portfolio.RebalancePolicy{Tolerance: pm.Tolerance, MinTrade: pm.MinTrade}
+	for _, tm := range pm.Targets {
+		policy.Targets = append(policy.Targets, portfolio.Target{Key: portfolio.AllocationKey(tm.Key), Name: tm.Name, Weight: tm.Weight})
+	}
+	if err := p.SetRebalancePolicy(policy); err != nil {
+		return nil, fmt.Errorf("incorrect portfolio parameter: %w", err)
+	}
+	return p, nil
+}
+
 func portfolioToTransactionModel(p *portfolio.Portfolio) []*transactionModel {
 	trms := []*transactionModel{}
 	for _, t := range p.Transactions() {
-		trms = append(trms, &transactionModel{
-			ID:          t.ID(),
-			UserID:      p.UserID(),
-			PortfolioID: p.ID(),
-			Asset:       t.Asset(),
-			Quantity:    t.Quantity(),
-			Price:       t.Price(),
-			DateString:  t.Date().Format(time.RFC3339),
-		})
+		lms := []lotModel{}
+		for _, l := range t.Lots() {
+			lms = append(lms, lotModel{LotID: l.TransactionID, Quantity: l.Quantity})
+		}
+		for _, l := range t.ReceivedLots() {
+			lms = append(lms, lotModel{LotID: l.TransactionID, Quantity: l.Quantity, DateString: l.Date.Format(time.RFC3339), Price: l.Price})
+		}
+		fms := []feeModel{}
+		for _, f := range t.Fees() {
+			fms = append(fms, feeModel{Type: string(f.Type), Amount: f.Amount, Currency: f.Currency})
+		}
+		instrumentID := ""
+		if t.InstrumentID() != uuid.Nil {
+			instrumentID = t.InstrumentID().String()
+		}
+		trm := &transactionModel{
+			ID:           t.ID(),
+			UserID:       p.UserID(),
+			PortfolioID:  p.ID(),
+			Kind:         string(t.Kind()),
+			Asset:        t.Asset(),
+			Quantity:     t.Quantity(),
+			Price:        t.Price(),
+			Amount:       t.Amount(),
+			Currency:     t.Currency(),
+			InstrumentID: instrumentID,
+			Multiplier:   t.Multiplier(),
+			Premium:      t.Premium(),
+			Accrued:      t.Accrued(),
+			Pending:      t.Pending(),
+			DateString:   t.Date().Format(time.RFC3339),
+			Lots:         lms,
+			Fees:         fms,
+		}
+		if link := t.Transfer(); link.TransactionID != uuid.Nil {
+			trm.TransferPortfolioID = link.PortfolioID.String()
+			trm.TransferID = link.TransactionID.String()
+		}
+		trms = append(trms, trm)
 	}
 	return trms
 }
@@ -382,11 +1088,220 @@ func transactionModelToTransactions(trms []*transactionModel) ([]*portfolio.Tran
 		if err != nil {
 			return nil, fmt.Errorf("incorrect transaction parameter: %w", err)
 		}
-		tr, err := portfolio.NewTransaction(trm.ID, date, trm.Asset, trm.Quantity, trm.Price)
+		kind, err := portfolio.ParseKind(trm.Kind)
+		if err != nil {
+			return nil, fmt.Errorf("incorrect transaction parameter: %w", err)
+		}
+		var tr *portfolio.Transaction
+		switch {
+		case kind.IsIncome():
+			tr, err = portfolio.NewIncomeTransaction(trm.ID, date, kind, trm.Asset, trm.Amount)
+		case kind.IsCashFlow():
+			tr, err = portfolio.NewCashTransaction(trm.ID, date, kind, trm.Amount)
+		case kind == portfolio.BorrowFee:
+			tr, err = portfolio.NewBorrowFee(trm.ID, date, trm.Asset, trm.Amount)
+		case kind == portfolio.AccountFee:
+			tr, err = portfolio.NewAccountFee(trm.ID, date, trm.Asset, trm.Amount)
+		case kind.IsTransfer():
+			tr, err = portfolio.NewTransfer(trm.ID, date, kind, trm.Asset, trm.Quantity.Abs(), trm.Price, trm.Amount)
+		case kind.IsOptionSettlement():
+			tr, err = portfolio.NewOptionSettlement(trm.ID, date, kind, trm.Asset, trm.Quantity)
+		default:
+			tr, err = portfolio.NewTransaction(trm.ID, date, trm.Asset, trm.Quantity, trm.Price)
+		}
 		if err != nil {
 			return nil, fmt.Errorf("incorrect transaction parameter: %w", err)
 		}
+		lots := []portfolio.LotSelection{}
+		received := []portfolio.Lot{}
+		for _, lm := range trm.Lots {
+			if lm.DateString == "" {
+				lots = append(lots, portfolio.LotSelection{TransactionID: lm.LotID, Quantity: lm.Quantity})
+				continue
+			}
+			lotDate, err := time.Parse(time.RFC3339, lm.DateString)
+			if err != nil {
+				return nil, fmt.Errorf("incorrect transaction parameter: %w", err)
+			}
+			received = append(received, portfolio.Lot{TransactionID: lm.LotID, Date: lotDate, Quantity: lm.Quantity, Price: lm.Price})
+		}
+		if err := tr.SelectLots(lots); err != nil {
+			return nil, fmt.Errorf("incorrect transaction parameter: %w", err)
+		}
+		if err := tr.ReceiveLots(received); err != nil {
+			return nil, fmt.Errorf("incorrect transaction parameter: %w", err)
+		}
+		if trm.TransferID != "" {
+			link := portfolio.TransferLink{}
+			if link.PortfolioID, err = uuid.Parse(trm.TransferPortfolioID); err != nil {
+				return nil, fmt.Errorf("incorrect transaction parameter: %w", err)
+			}
+			if link.TransactionID, err = uuid.Parse(trm.TransferID); err != nil {
+				return nil, fmt.Errorf("incorrect transaction parameter: %w", err)
+			}
+			if err := tr.LinkTransfer(link); err != nil {
+				return nil, fmt.Errorf("incorrect transaction parameter: %w", err)
+			}
+		}
+		fees := []portfolio.Fee{}
+		for _, fm := range trm.Fees {
+			feeType, err := portfolio.ParseFeeType(fm.Type)
+			if err != nil {
+				return nil, fmt.Errorf("incorrect transaction parameter: %w", err)
+			}
+			fees = append(fees, portfolio.Fee{Type: feeType, Amount: fm.Amount, Currency: fm.Currency})
+		}
+		if err := tr.SetFees(fees); err != nil {
+			return nil, fmt.Errorf("incorrect transaction parameter: %w", err)
+		}
+		if err := tr.SetCurrency(trm.Currency); err != nil {
+			return nil, fmt.Errorf("incorrect transaction parameter: %w", err)
+		}
+		if !trm.Multiplier.Equal(tr.Multiplier()) {
+			if err := tr.SetMultiplier(trm.Multiplier); err != nil {
+				return nil, fmt.Errorf("incorrect transaction parameter: %w", err)
+			}
+		}
+		if err := tr.SetPremium(trm.Premium); err != nil {
+			return nil, fmt.Errorf("incorrect transaction parameter: %w", err)
+		}
+		if err := tr.SetAccrued(trm.Accrued); err != nil {
+			return nil, fmt.Errorf("incorrect transaction parameter: %w", err)
+		}
+		if err := tr.SetPending(trm.Pending); err != nil {
+			return nil, fmt.Errorf("incorrect transaction parameter: %w", err)
+		}
+		if trm.InstrumentID != "" {
+			instrumentID, err := uuid.Parse(trm.InstrumentID)
+			if err != nil {
+				return nil, fmt.Errorf("incorrect transaction parameter: %w", err)
+			}
+			if err := tr.SetInstrument(instrumentID, trm.Asset); err != nil {
+				return nil, fmt.Errorf("incorrect transaction parameter: %w", err)
+			}
+		}
 		trs = append(trs, tr)
 	}
 	return trs, nil
 }
+
+func addColumn(db *sql.DB, table, column, definition string) error {
+	rows, err := db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return fmt.Errorf("can't add column %s to %s: %w", column, table, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid        int
+			name       string
+			ctype      string
+			notNull    int
+			defaultVal sql.NullString
+			pk         int
+		)
+		if err := rows.Scan(&cid, &name, &ctype, &notNull, &defaultVal, &pk); err != nil {
+			return fmt.Errorf("can't add column %s to %s: %w", column, table, err)
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("can't add column %s to %s: %w", column, table, err)
+	}
+	rows.Close()
+
+	if _, err := db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition)); err != nil {
+		return fmt.Errorf("can't add column %s to %s: %w", column, table, err)
+	}
+	return nil
+}
+
+func changeColumnsToText(db *sql.DB, table string, columns ...string) error {
+	rows, err := db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return fmt.Errorf("can't change columns of %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	toText := map[string]bool{}
+	for _, c := range columns {
+		toText[c] = true
+	}
+	var (
+		changed     bool
+		definitions []string
+		names       []string
+		values      []string
+	)
+	keys := map[int]string{}
+	for rows.Next() {
+		var (
+			cid        int
+			name       string
+			ctype      string
+			notNull    int
+			defaultVal sql.NullString
+			pk         int
+		)
+		if err := rows.Scan(&cid, &name, &ctype, &notNull, &defaultVal, &pk); err != nil {
+			return fmt.Errorf("can't change columns of %s: %w", table, err)
+		}
+		value := name
+		if toText[name] && !strings.EqualFold(ctype, "text") {
+			changed = true
+			ctype = "text"
+			value = fmt.Sprintf("CAST(%s AS TEXT)", name)
+		}
+		definition := fmt.Sprintf("%s %s", name, ctype)
+		if notNull != 0 {
+			definition += " not null"
+		}
+		if defaultVal.Valid {
+			definition += " default " + defaultVal.String
+		}
+		if pk > 0 {
+			keys[pk] = name
+		}
+		definitions = append(definitions, definition)
+		names = append(names, name)
+		values = append(values, value)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("can't change columns of %s: %w", table, err)
+	}
+	rows.Close()
+	if !changed {
+		return nil
+	}
+
+	primaryKey := []string{}
+	for i := 1; i <= len(keys); i++ {
+		primaryKey = append(primaryKey, keys[i])
+	}
+	definitions = append(definitions, fmt.Sprintf("primary key(%s)", strings.Join(primaryKey, ", ")))
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("can't change columns of %s: %w", table, err)
+	}
+	defer tx.Rollback()
+
+	stmts := []string{
+		fmt.Sprintf("CREATE TABLE %s_new (%s)", table, strings.Join(definitions, ", ")),
+		fmt.Sprintf("INSERT INTO %s_new (%s) SELECT %s FROM %s", table, strings.Join(names, ", "), strings.Join(values, ", "), table),
+		fmt.Sprintf("DROP TABLE %s", table),
+		fmt.Sprintf("ALTER TABLE %s_new RENAME TO %s", table, table),
+	}
+	for _, stmt := range stmts {
+		if _, err := tx.Exec(stmt); err != nil {
+			return fmt.Errorf("can't change columns of %s: %w", table, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("can't change columns of %s: %w", table, err)
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package adapters
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/invine/portfolio/internal/domain/portfolio"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+type SQLitePrecisionRepository struct {
+	db *sql.DB
+}
+
+func NewSQLitePrecisionRepository(db *sql.DB) (*SQLitePrecisionRepository, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database required")
+	}
+
+	if err := createAssetPrecisionsTable(db); err != nil {
+		return nil, err
+	}
+
+	if err := createInstrumentsTables(db); err != nil {
+		return nil, err
+	}
+
+	r := &SQLitePrecisionRepository{db: db}
+	return r, nil
+}
+
+func createAssetPrecisionsTable(db *sql.DB) error {
+	sqlStmt := `
+        CREATE TABLE IF NOT EXISTS asset_precisions
+        (
+            asset text not null primary key,
+            places integer not null
+        );
+	`
+	if _, err := db.Exec(sqlStmt); err != nil {
+		return fmt.Errorf("can't insert table: %w", err)
+	}
+	return nil
+}
+
+func (r *SQLitePrecisionRepository) GetPrecisions(ctx context.Context) (portfolio.Precisions, error) {
+	return getPrecisions(ctx, r.db)
+}
+
+func (r *SQLitePrecisionRepository) SetPrecision(ctx context.Context, asset string, places int32) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("can't set precision of %s: %w", asset, err)
+	}
+	defer tx.Rollback()
+
+	sqlStmt := `
+        INSERT INTO asset_precisions(asset, places) VALUES($1, $2)
+        ON CONFLICT(asset) DO UPDATE SET places=excluded.places
+	`
+	if _, err := tx.ExecContext(ctx, sqlStmt, asset, places); err != nil {
+		return fmt.Errorf("can't set precision of %s: %w", asset, err)
+	}
+	if _, err := tx.ExecContext(ctx, "UPDATE instruments SET places = $1 WHERE symbol = $2", places, asset); err != nil {
+		return fmt.Errorf("can't set precision of %s: %w", asset, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("can't set precision of %s: %w", asset, err)
+	}
+	return nil
+}
+
+func getPrecisions(ctx context.Context, db querier) (portfolio.Precisions, error) {
+	sqlStmt := `
+        select asset, places from asset_precisions where asset not in (select symbol from instruments)
+        union all
+        select symbol, places from instruments
+	`
+	rows, err := db.QueryContext(ctx, sqlStmt)
+	if err != nil {
+		return nil, fmt.Errorf("can't list precisions: %w", err)
+	}
+	defer rows.Close()
+
+	precisions := portfolio.Precisions{}
+	for rows.Next() {
+		var (
+			asset  string
+			places int32
+		)
+		if err := rows.Scan(&asset, &places); err != nil {
+			return nil, fmt.Errorf("can't list precisions: %w", err)
+		}
+		precisions[asset] = places
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("can't list precisions: %w", err)
+	}
+	return precisions, nil
+}
@@ -0,0 +1,237 @@
+package adapters
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/invine/portfolio/internal/domain/price"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/shopspring/decimal"
+)
+
+type SQLitePriceRepository struct {
+	db *sql.DB
+}
+
+type priceModel struct {
+	Asset         string
+	DateString    string
+	Close         decimal.Decimal
+	PreviousClose decimal.Decimal
+	Currency      string
+	Source        string
+}
+
+const priceHistoryDateFormat = "2006-01-02"
+
+func NewSQLitePriceRepository(db *sql.DB) (*SQLitePriceRepository, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database required")
+	}
+
+	sqlStmt := `
+        CREATE TABLE IF NOT EXISTS prices
+        (
+            asset text not null primary key,
+            date text not null,
+            close text not null,
+            previousclose text not null default '0'
+        );
+	`
+	_, err := db.Exec(sqlStmt)
+	if err != nil {
+		return nil, fmt.Errorf("can't insert table: %w", err)
+	}
+
+	sqlStmt = `
+        CREATE TABLE IF NOT EXISTS price_history
+        (
+            asset text not null,
+            date text not null,
+            close text not null,
+            currency text not null default '',
+            source text not null default '',
+            primary key(asset, date)
+        );
+	`
+	_, err = db.Exec(sqlStmt)
+	if err != nil {
+		return nil, fmt.Errorf("can't insert table: %w", err)
+	}
+
+	if err := changeColumnsToText(db, "prices", "close", "previousclose"); err != nil {
+		return nil, fmt.Errorf("can't migrate table: %w", err)
+	}
+	if err := changeColumnsToText(db, "price_history", "close"); err != nil {
+		return nil, fmt.Errorf("can't migrate table: %w", err)
+	}
+
+	r := &SQLitePriceRepository{db: db}
+	return r, nil
+}
+
+func (r *SQLitePriceRepository) GetPrice(ctx context.Context, asset string) (*price.Price, error) {
+	sqlStmt := "select date, close, previousclose from prices where asset = $1"
+	row := r.db.QueryRowContext(ctx, sqlStmt, asset)
+
+	pm := &priceModel{Asset: asset}
+	if err := row.Scan(&pm.DateString, &pm.Close, &pm.PreviousClose); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("can't get price of %s: %w", asset, price.ErrPriceNotFound)
+		}
+		return nil, fmt.Errorf("can't get price of %s: %w", asset, err)
+	}
+
+	p, err := priceModelToPrice(pm, time.RFC3339)
+	if err != nil {
+		return nil, fmt.Errorf("can't get price of %s: %w", asset, err)
+	}
+	return p, nil
+}
+
+func (r *SQLitePriceRepository) UpdatePrice(ctx context.Context, p *price.Price) error {
+	pm := priceToPriceModel(p, time.RFC3339)
+
+	sqlStmt := `
+        INSERT INTO prices(asset, date, close, previousclose)
+        VALUES($1, $2, $3, $4)
+        ON CONFLICT(asset) DO UPDATE SET
+        date=excluded.date, close=excluded.close, previousclose=excluded.previousclose
+        WHERE excluded.date >= prices.date
+	`
+	if _, err := r.db.ExecContext(ctx, sqlStmt, pm.Asset, pm.DateString, pm.Close, pm.PreviousClose); err != nil {
+		return fmt.Errorf("can't update price of %s: %w", pm.Asset, err)
+	}
+	return nil
+}
+
+func (r *SQLitePriceRepository) GetPriceAt(ctx context.Context, asset string, date time.Time) (*price.Price, error) {
+	sqlStmt := `
+        select date, close, currency, source from price_history
+        where asset = $1 and date <= $2
+        order by date desc limit 2
+	`
+	rows, err := r.db.QueryContext(ctx, sqlStmt, asset, date.Format(priceHistoryDateFormat))
+	if err != nil {
+		return nil, fmt.Errorf("can't get price of %s at %s: %w", asset, date.Format(priceHistoryDateFormat), err)
+	}
+	defer rows.Close()
+
+	pms := []*priceModel{}
+	for rows.Next() {
+		pm := &priceModel{Asset: asset}
+		if err := rows.Scan(&pm.DateString, &pm.Close, &pm.Currency, &pm.Source); err != nil {
+			return nil, fmt.Errorf("can't get price of %s at %s: %w", asset, date.Format(priceHistoryDateFormat), err)
+		}
+		pms = append(pms, pm)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("can't get price of %s at %s: %w", asset, date.Format(priceHistoryDateFormat), err)
+	}
+	if len(pms) == 0 {
+		return nil, fmt.Errorf("can't get price of %s at %s: %w", asset, date.Format(priceHistoryDateFormat), price.ErrPriceNotFound)
+	}
+
+	pm := pms[0]
+	if len(pms) > 1 {
+		pm.PreviousClose = pms[1].Close
+	}
+	p, err := priceModelToPrice(pm, priceHistoryDateFormat)
+	if err != nil {
+		return nil, fmt.Errorf("can't get price of %s at %s: %w", asset, date.Format(priceHistoryDateFormat), err)
+	}
+	return p, nil
+}
+
+func (r *SQLitePriceRepository) GetPriceHistory(ctx context.Context, asset string, from, to time.Time) (price.Series, error) {
+	sqlStmt := `
+        select date, close, currency, source from price_history
+        where asset = $1 and date <= $2 and date >= coalesce(
+            (select max(date) from price_history where asset = $1 and date <= $3), $3
+        )
+        order by date
+	`
+	rows, err := r.db.QueryContext(ctx, sqlStmt, asset, to.Format(priceHistoryDateFormat), from.Format(priceHistoryDateFormat))
+	if err != nil {
+		return nil, fmt.Errorf("can't get price history of %s: %w", asset, err)
+	}
+	defer rows.Close()
+
+	prices := []*price.Price{}
+	previousClose := decimal.Zero
+	for rows.Next() {
+		pm := &priceModel{Asset: asset, PreviousClose: previousClose}
+		if err := rows.Scan(&pm.DateString, &pm.Close, &pm.Currency, &pm.Source); err != nil {
+			return nil, fmt.Errorf("can't get price history of %s: %w", asset, err)
+		}
+		p, err := priceModelToPrice(pm, priceHistoryDateFormat)
+		if err != nil {
+			return nil, fmt.Errorf("can't get price history of %s: %w", asset, err)
+		}
+		prices = append(prices, p)
+		previousClose = pm.Close
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("can't get price history of %s: %w", asset, err)
+	}
+
+	return price.NewSeries(prices), nil
+}
+
+func (r *SQLitePriceRepository) AddPrices(ctx context.Context, prices []*price.Price) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("can't add prices: %w", err)
+	}
+	defer tx.Rollback()
+
+	sqlStmt := `
+        INSERT INTO price_history(asset, date, close, currency, source)
+        VALUES($1, $2, $3, $4, $5)
+        ON CONFLICT(asset, date) DO UPDATE SET
+        close=excluded.close, currency=excluded.currency, source=excluded.source
+	`
+	stmt, err := tx.Prepare(sqlStmt)
+	if err != nil {
+		return fmt.Errorf("can't add prices: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, p := range prices {
+		pm := priceToPriceModel(p, priceHistoryDateFormat)
+		if _, err := stmt.ExecContext(ctx, pm.Asset, pm.DateString, pm.Close, pm.Currency, pm.Source); err != nil {
+			return fmt.Errorf("can't add price of %s at %s: %w", pm.Asset, pm.DateString, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("can't add prices: %w", err)
+	}
+	return nil
+}
+
+func priceToPriceModel(p *price.Price, dateFormat string) *priceModel {
+	return &priceModel{
+		Asset:         p.Asset(),
+		DateString:    p.Date().Format(dateFormat),
+		Close:         p.Close(),
+		PreviousClose: p.PreviousClose(),
+		Currency:      p.Currency(),
+		Source:        p.Source(),
+	}
+}
+
+func priceModelToPrice(pm *priceModel, dateFormat string) (*price.Price, error) {
+	date, err := time.Parse(dateFormat, pm.DateString)
+	if err != nil {
+		return nil, fmt.Errorf("incorrect price parameter: %w", err)
+	}
+	p, err := price.NewPrice(pm.Asset, date, pm.Close, pm.PreviousClose, pm.Currency, pm.Source)
+	if err != nil {
+		return nil, fmt.Errorf("incorrect price parameter: %w", err)
+	}
+	return p, nil
+}
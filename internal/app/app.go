@@ -14,10 +14,56 @@ type Commands struct {
 	ApplyTransaction command.ApplyTransactionHandler
 	CreatePortfolio  command.CreatePortfolioHandler
 	DeletePortfolio  command.DeletePortfolioHandler
+	UpdatePortfolio  command.UpdatePortfolioHandler
+	Transfer         command.TransferHandler
+	SettleOption     command.SettleOptionHandler
+	PlaceOrders      command.PlaceOrdersHandler
+	FillOrder        command.FillOrderHandler
+	CancelOrder      command.CancelOrderHandler
+
+	ImportTransactions command.ImportTransactionsHandler
+
+	SetRebalancePolicy command.SetRebalancePolicyHandler
+
+	CreateGroup command.CreateGroupHandler
+	UpdateGroup command.UpdateGroupHandler
+	DeleteGroup command.DeleteGroupHandler
+
+	AddCorporateAction    command.AddCorporateActionHandler
+	DeleteCorporateAction command.DeleteCorporateActionHandler
+
+	SetPrecision command.SetPrecisionHandler
+
+	AddInstrument    command.AddInstrumentHandler
+	UpdateInstrument command.UpdateInstrumentHandler
+	DeleteInstrument command.DeleteInstrumentHandler
 }
 
 type Queries struct {
 	AllPortfolios   query.AllPortfoliosHandler
 	AllTransactions query.AllTransactionsHandler
 	Portfolio       query.PortfolioHandler
+	ProfitLoss      query.ProfitLossHandler
+	History         query.PortfolioHistoryHandler
+	Performance     query.PerformanceHandler
+	Income          query.IncomeHandler
+	Gains           query.GainsHandler
+	CashLedger      query.CashLedgerHandler
+	Fees            query.FeesHandler
+	Allocation      query.AllocationHandler
+	Consolidated    query.ConsolidatedHandler
+	ImportPreview   query.ImportPreviewHandler
+
+	RebalancePolicy query.RebalancePolicyHandler
+	Rebalance       query.RebalanceHandler
+
+	AllGroups query.AllGroupsHandler
+	Group     query.GroupHandler
+
+	AllCorporateActions query.AllCorporateActionsHandler
+
+	AllPrecisions query.AllPrecisionsHandler
+
+	AllInstruments query.AllInstrumentsHandler
+	Instrument     query.InstrumentHandler
 }
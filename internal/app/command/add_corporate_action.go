@@ -0,0 +1,30 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/invine/portfolio/internal/domain/portfolio"
+)
+
+type AddCorporateAction struct {
+	Action *portfolio.CorporateAction
+}
+
+type AddCorporateActionHandler struct {
+	repo portfolio.CorporateActionRepository
+}
+
+func NewAddCorporateActionHandler(repo portfolio.CorporateActionRepository) (*AddCorporateActionHandler, error) {
+	if repo == nil {
+		return nil, fmt.Errorf("corporate action repo can't be empty")
+	}
+	return &AddCorporateActionHandler{repo: repo}, nil
+}
+
+func (h AddCorporateActionHandler) Handle(ctx context.Context, cmd AddCorporateAction) error {
+	if err := h.repo.AddCorporateAction(ctx, cmd.Action); err != nil {
+		return fmt.Errorf("can't add %s of %s: %w", cmd.Action.Type(), cmd.Action.Asset(), err)
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/invine/portfolio/internal/domain/instrument"
+)
+
+type AddInstrument struct {
+	ID      uuid.UUID
+	Symbol  string
+	Name    string
+	Class   instrument.AssetClass
+	Details instrument.Details
+}
+
+type AddInstrumentHandler struct {
+	repo instrument.InstrumentRepository
+}
+
+func NewAddInstrumentHandler(repo instrument.InstrumentRepository) (*AddInstrumentHandler, error) {
+	if repo == nil {
+		return nil, fmt.Errorf("instrument repo can't be empty")
+	}
+	return &AddInstrumentHandler{repo: repo}, nil
+}
+
+func (h AddInstrumentHandler) Handle(ctx context.Context, cmd AddInstrument) error {
+	i, err := instrument.NewInstrument(cmd.ID, cmd.Symbol, cmd.Name, cmd.Class, cmd.Details)
+	if err != nil {
+		return err
+	}
+	return h.repo.AddInstrument(ctx, i)
+}
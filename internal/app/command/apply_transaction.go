@@ -2,9 +2,11 @@ package command
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/google/uuid"
+	"github.com/invine/portfolio/internal/domain/instrument"
 	"github.com/invine/portfolio/internal/domain/portfolio"
 )
 
@@ -15,17 +17,24 @@ type ApplyTransaction struct {
 }
 
 type ApplyTransactionHandler struct {
-	repo portfolio.PortfolioRepository
+	repo        portfolio.PortfolioRepository
+	instruments instrument.InstrumentRepository
 }
 
-func NewApplyTransactionHandler(repo portfolio.PortfolioRepository) (*ApplyTransactionHandler, error) {
+func NewApplyTransactionHandler(repo portfolio.PortfolioRepository, instruments instrument.InstrumentRepository) (*ApplyTransactionHandler, error) {
 	if repo == nil {
 		return nil, fmt.Errorf("portfolio repo can't be empty")
 	}
-	return &ApplyTransactionHandler{repo: repo}, nil
+	if instruments == nil {
+		return nil, fmt.Errorf("instrument repo can't be empty")
+	}
+	return &ApplyTransactionHandler{repo: repo, instruments: instruments}, nil
 }
 
 func (h ApplyTransactionHandler) Handle(ctx context.Context, cmd ApplyTransaction) error {
+	if err := resolveInstrument(ctx, h.instruments, cmd.Transaction); err != nil {
+		return err
+	}
 	return h.repo.UpdatePortfolio(
 		ctx,
 		cmd.UserID,
@@ -37,3 +46,20 @@ func (h ApplyTransactionHandler) Handle(ctx context.Context, cmd ApplyTransactio
 			return nil
 		})
 }
+
+func resolveInstrument(ctx context.Context, instruments instrument.InstrumentRepository, t *portfolio.Transaction) error {
+	if t.Asset() == "" {
+		return nil
+	}
+	i, err := instruments.FindInstrument(ctx, t.Asset())
+	if errors.Is(err, instrument.ErrInstrumentNotFound) {
+		if _, idErr := uuid.Parse(t.Asset()); idErr == nil {
+			return fmt.Errorf("can't resolve instrument of %s: %w", t.Asset(), err)
+		}
+		return t.SetAsset(instrument.NormalizeCode(t.Asset()))
+	}
+	if err != nil {
+		return fmt.Errorf("can't resolve instrument of %s: %w", t.Asset(), err)
+	}
+	return i.Link(t)
+}
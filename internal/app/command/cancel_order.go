@@ -0,0 +1,36 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/invine/portfolio/internal/domain/portfolio"
+)
+
+type CancelOrder struct {
+	UserID      uuid.UUID
+	PortfolioID uuid.UUID
+	OrderID     uuid.UUID
+}
+
+type CancelOrderHandler struct {
+	repo portfolio.PortfolioRepository
+}
+
+func NewCancelOrderHandler(repo portfolio.PortfolioRepository) (*CancelOrderHandler, error) {
+	if repo == nil {
+		return nil, fmt.Errorf("portfolio repo can't be empty")
+	}
+	return &CancelOrderHandler{repo: repo}, nil
+}
+
+func (h CancelOrderHandler) Handle(ctx context.Context, cmd CancelOrder) error {
+	return h.repo.UpdatePortfolio(
+		ctx,
+		cmd.UserID,
+		cmd.PortfolioID,
+		func(p *portfolio.Portfolio) error {
+			return p.CancelOrder(cmd.OrderID)
+		})
+}
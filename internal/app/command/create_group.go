@@ -0,0 +1,76 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/invine/portfolio/internal/domain/portfolio"
+)
+
+type CreateGroup struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+	Name   string
+	// ParentID is the group the new group is nested in, nil for a top group
+	ParentID   uuid.UUID
+	Portfolios []uuid.UUID
+}
+
+type CreateGroupHandler struct {
+	repo       portfolio.GroupRepository
+	portfolios portfolio.PortfolioRepository
+}
+
+func NewCreateGroupHandler(repo portfolio.GroupRepository, portfolios portfolio.PortfolioRepository) (*CreateGroupHandler, error) {
+	if repo == nil {
+		return nil, fmt.Errorf("group repo can't be empty")
+	}
+	if portfolios == nil {
+		return nil, fmt.Errorf("portfolio repo can't be empty")
+	}
+	return &CreateGroupHandler{repo: repo, portfolios: portfolios}, nil
+}
+
+func (h CreateGroupHandler) Handle(ctx context.Context, cmd CreateGroup) error {
+	g, err := portfolio.NewGroup(cmd.ID, cmd.UserID, cmd.Name, cmd.ParentID, cmd.Portfolios)
+	if err != nil {
+		return fmt.Errorf("can't create group %s: %w", cmd.Name, err)
+	}
+	if err := checkPortfolios(ctx, h.portfolios, cmd.UserID, g.Portfolios()); err != nil {
+		return fmt.Errorf("can't create group %s: %w", cmd.Name, err)
+	}
+
+	groups, err := h.repo.GetGroups(ctx, cmd.UserID)
+	if err != nil {
+		return fmt.Errorf("can't create group %s: %w", cmd.Name, err)
+	}
+	if err := portfolio.CheckGroups(append(groups, g)); err != nil {
+		return fmt.Errorf("can't create group %s: %w", cmd.Name, err)
+	}
+
+	if err := h.repo.AddGroup(ctx, g); err != nil {
+		return fmt.Errorf("can't create group %s: %w", cmd.Name, err)
+	}
+	return nil
+}
+
+func checkPortfolios(ctx context.Context, repo portfolio.PortfolioRepository, userID uuid.UUID, ids []uuid.UUID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	ps, err := repo.GetAllPortfolios(ctx, userID)
+	if err != nil {
+		return err
+	}
+	owned := map[uuid.UUID]bool{}
+	for _, p := range ps {
+		owned[p.ID()] = true
+	}
+	for _, id := range ids {
+		if !owned[id] {
+			return fmt.Errorf("portfolio %s not found", id.String())
+		}
+	}
+	return nil
+}
@@ -9,9 +9,18 @@ import (
 )
 
 type CreatePortfolio struct {
-	ID     uuid.UUID
-	UserID uuid.UUID
-	Name   string
+	ID              uuid.UUID
+	UserID          uuid.UUID
+	Name            string
+	CostBasisMethod portfolio.CostBasisMethod
+	// Overdraft allows the cash balance to go below zero, left unchanged if nil
+	Overdraft *bool
+	// ShortSelling allows selling more of an asset than is held, left unchanged if nil
+	ShortSelling *bool
+	// WashSaleRule makes losses on sales replaced within 30 days disallowed, left unchanged if nil
+	WashSaleRule *bool
+	// Currency is the reporting currency, USD if empty
+	Currency string
 }
 
 type CreatePortfolioHandler struct {
@@ -31,6 +40,29 @@ func (h CreatePortfolioHandler) Handle(ctx context.Context, cmd CreatePortfolio)
 	if err != nil {
 		return fmt.Errorf("can't create portfolio %s: %w", cmd.Name, err)
 	}
+	if cmd.CostBasisMethod != "" {
+		if err := p.ChangeCostBasisMethod(cmd.CostBasisMethod); err != nil {
+			return fmt.Errorf("can't create portfolio %s: %w", cmd.Name, err)
+		}
+	}
+	if cmd.Overdraft != nil {
+		if err := p.ChangeOverdraft(*cmd.Overdraft); err != nil {
+			return fmt.Errorf("can't create portfolio %s: %w", cmd.Name, err)
+		}
+	}
+	if cmd.ShortSelling != nil {
+		if err := p.ChangeShortSelling(*cmd.ShortSelling); err != nil {
+			return fmt.Errorf("can't create portfolio %s: %w", cmd.Name, err)
+		}
+	}
+	if cmd.WashSaleRule != nil {
+		p.ChangeWashSaleRule(*cmd.WashSaleRule)
+	}
+	if cmd.Currency != "" {
+		if err := p.ChangeCurrency(cmd.Currency); err != nil {
+			return fmt.Errorf("can't create portfolio %s: %w", cmd.Name, err)
+		}
+	}
 
 	if err := h.repo.CreatePortfolio(ctx, p); err != nil {
 		return fmt.Errorf("can't create portfolio %s: %w", cmd.Name, err)
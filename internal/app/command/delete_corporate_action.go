@@ -0,0 +1,28 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/invine/portfolio/internal/domain/portfolio"
+)
+
+type DeleteCorporateAction struct {
+	ID uuid.UUID
+}
+
+type DeleteCorporateActionHandler struct {
+	repo portfolio.CorporateActionRepository
+}
+
+func NewDeleteCorporateActionHandler(repo portfolio.CorporateActionRepository) (*DeleteCorporateActionHandler, error) {
+	if repo == nil {
+		return nil, fmt.Errorf("corporate action repo can't be empty")
+	}
+	return &DeleteCorporateActionHandler{repo: repo}, nil
+}
+
+func (h DeleteCorporateActionHandler) Handle(ctx context.Context, cmd DeleteCorporateAction) error {
+	return h.repo.DeleteCorporateAction(ctx, cmd.ID)
+}
@@ -0,0 +1,29 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/invine/portfolio/internal/domain/portfolio"
+)
+
+type DeleteGroup struct {
+	UserID  uuid.UUID
+	GroupID uuid.UUID
+}
+
+type DeleteGroupHandler struct {
+	repo portfolio.GroupRepository
+}
+
+func NewDeleteGroupHandler(repo portfolio.GroupRepository) (*DeleteGroupHandler, error) {
+	if repo == nil {
+		return nil, fmt.Errorf("group repo can't be empty")
+	}
+	return &DeleteGroupHandler{repo: repo}, nil
+}
+
+func (h DeleteGroupHandler) Handle(ctx context.Context, cmd DeleteGroup) error {
+	return h.repo.DeleteGroup(ctx, cmd.UserID, cmd.GroupID)
+}
@@ -0,0 +1,28 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/invine/portfolio/internal/domain/instrument"
+)
+
+type DeleteInstrument struct {
+	ID uuid.UUID
+}
+
+type DeleteInstrumentHandler struct {
+	repo instrument.InstrumentRepository
+}
+
+func NewDeleteInstrumentHandler(repo instrument.InstrumentRepository) (*DeleteInstrumentHandler, error) {
+	if repo == nil {
+		return nil, fmt.Errorf("instrument repo can't be empty")
+	}
+	return &DeleteInstrumentHandler{repo: repo}, nil
+}
+
+func (h DeleteInstrumentHandler) Handle(ctx context.Context, cmd DeleteInstrument) error {
+	return h.repo.DeleteInstrument(ctx, cmd.ID)
+}
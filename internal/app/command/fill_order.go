@@ -0,0 +1,42 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/invine/portfolio/internal/domain/portfolio"
+	"github.com/shopspring/decimal"
+)
+
+type FillOrder struct {
+	UserID      uuid.UUID
+	PortfolioID uuid.UUID
+	OrderID     uuid.UUID
+	Date        time.Time
+	Quantity    decimal.Decimal
+	Price       decimal.Decimal
+	Fees        []portfolio.Fee
+}
+
+type FillOrderHandler struct {
+	repo portfolio.PortfolioRepository
+}
+
+func NewFillOrderHandler(repo portfolio.PortfolioRepository) (*FillOrderHandler, error) {
+	if repo == nil {
+		return nil, fmt.Errorf("portfolio repo can't be empty")
+	}
+	return &FillOrderHandler{repo: repo}, nil
+}
+
+func (h FillOrderHandler) Handle(ctx context.Context, cmd FillOrder) error {
+	return h.repo.UpdatePortfolio(
+		ctx,
+		cmd.UserID,
+		cmd.PortfolioID,
+		func(p *portfolio.Portfolio) error {
+			return p.FillOrder(cmd.OrderID, cmd.Date, cmd.Quantity, cmd.Price, cmd.Fees)
+		})
+}
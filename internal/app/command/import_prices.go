@@ -0,0 +1,30 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/invine/portfolio/internal/domain/price"
+)
+
+type ImportPrices struct {
+	Prices []*price.Price
+}
+
+type ImportPricesHandler struct {
+	repo price.PriceHistoryRepository
+}
+
+func NewImportPricesHandler(repo price.PriceHistoryRepository) (*ImportPricesHandler, error) {
+	if repo == nil {
+		return nil, fmt.Errorf("price history repo can't be empty")
+	}
+	return &ImportPricesHandler{repo: repo}, nil
+}
+
+func (h ImportPricesHandler) Handle(ctx context.Context, cmd ImportPrices) error {
+	if err := h.repo.AddPrices(ctx, cmd.Prices); err != nil {
+		return fmt.Errorf("can't import %d prices: %w", len(cmd.Prices), err)
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/invine/portfolio/internal/domain/fx"
+)
+
+type ImportRates struct {
+	Rates []*fx.Rate
+}
+
+type ImportRatesHandler struct {
+	repo fx.RateRepository
+}
+
+func NewImportRatesHandler(repo fx.RateRepository) (*ImportRatesHandler, error) {
+	if repo == nil {
+		return nil, fmt.Errorf("exchange rates repo can't be empty")
+	}
+	return &ImportRatesHandler{repo: repo}, nil
+}
+
+func (h ImportRatesHandler) Handle(ctx context.Context, cmd ImportRates) error {
+	if err := h.repo.AddRates(ctx, cmd.Rates); err != nil {
+		return fmt.Errorf("can't import %d exchange rates: %w", len(cmd.Rates), err)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/invine/portfolio/internal/domain/instrument"
+	"github.com/invine/portfolio/internal/domain/portfolio"
+)
+
+type ImportTransactions struct {
+	UserID       uuid.UUID
+	PortfolioID  uuid.UUID
+	Transactions []*portfolio.Transaction
+	Actions      []*portfolio.CorporateAction
+}
+
+type ImportTransactionsHandler struct {
+	repo        portfolio.PortfolioRepository
+	instruments instrument.InstrumentRepository
+}
+
+func NewImportTransactionsHandler(repo portfolio.PortfolioRepository, instruments instrument.InstrumentRepository) (*ImportTransactionsHandler, error) {
+	if repo == nil {
+		return nil, fmt.Errorf("portfolio repo can't be empty")
+	}
+	if instruments == nil {
+		return nil, fmt.Errorf("instrument repo can't be empty")
+	}
+	return &ImportTransactionsHandler{repo: repo, instruments: instruments}, nil
+}
+
+func (h ImportTransactionsHandler) Handle(ctx context.Context, cmd ImportTransactions) error {
+	transactions := make([]*portfolio.Transaction, len(cmd.Transactions))
+	copy(transactions, cmd.Transactions)
+	for _, t := range transactions {
+		if err := resolveInstrument(ctx, h.instruments, t); err != nil {
+			return fmt.Errorf("can't import transactions to portfolio %s: %w", cmd.PortfolioID.String(), err)
+		}
+	}
+	sort.SliceStable(transactions, func(i, j int) bool {
+		return transactions[i].Date().Before(transactions[j].Date())
+	})
+
+	return h.repo.UpdatePortfolio(
+		ctx,
+		cmd.UserID,
+		cmd.PortfolioID,
+		func(p *portfolio.Portfolio) error {
+			for _, a := range cmd.Actions {
+				if err := p.AddPortfolioAction(a); err != nil {
+					return fmt.Errorf("can't import to portfolio %s: %w", cmd.PortfolioID.String(), err)
+				}
+			}
+			for _, t := range transactions {
+				if err := p.ApplyTransaction(t); err != nil {
+					return fmt.Errorf("can't import transaction %s to portfolio %s: %w", t.ID().String(), cmd.PortfolioID.String(), err)
+				}
+			}
+			return nil
+		})
+}
@@ -0,0 +1,49 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/invine/portfolio/internal/domain/instrument"
+	"github.com/invine/portfolio/internal/domain/portfolio"
+)
+
+type PlaceOrders struct {
+	UserID      uuid.UUID
+	PortfolioID uuid.UUID
+	Orders      []*portfolio.Transaction
+}
+
+type PlaceOrdersHandler struct {
+	repo        portfolio.PortfolioRepository
+	instruments instrument.InstrumentRepository
+}
+
+func NewPlaceOrdersHandler(repo portfolio.PortfolioRepository, instruments instrument.InstrumentRepository) (*PlaceOrdersHandler, error) {
+	if repo == nil {
+		return nil, fmt.Errorf("portfolio repo can't be empty")
+	}
+	if instruments == nil {
+		return nil, fmt.Errorf("instrument repo can't be empty")
+	}
+	return &PlaceOrdersHandler{repo: repo, instruments: instruments}, nil
+}
+
+func (h PlaceOrdersHandler) Handle(ctx context.Context, cmd PlaceOrders) error {
+	for _, t := range cmd.Orders {
+		if err := resolveInstrument(ctx, h.instruments, t); err != nil {
+			return err
+		}
+	}
+	return h.repo.UpdatePortfolio(
+		ctx,
+		cmd.UserID,
+		cmd.PortfolioID,
+		func(p *portfolio.Portfolio) error {
+			if err := p.PlaceOrders(cmd.Orders); err != nil {
+				return fmt.Errorf("can't place orders in portfolio %s: %w", cmd.PortfolioID.String(), err)
+			}
+			return nil
+		})
+}
@@ -0,0 +1,34 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/invine/portfolio/internal/domain/portfolio"
+)
+
+type SetPrecision struct {
+	Asset  string
+	Places int32
+}
+
+type SetPrecisionHandler struct {
+	repo portfolio.PrecisionRepository
+}
+
+func NewSetPrecisionHandler(repo portfolio.PrecisionRepository) (*SetPrecisionHandler, error) {
+	if repo == nil {
+		return nil, fmt.Errorf("precision repo can't be empty")
+	}
+	return &SetPrecisionHandler{repo: repo}, nil
+}
+
+func (h SetPrecisionHandler) Handle(ctx context.Context, cmd SetPrecision) error {
+	if cmd.Asset == "" {
+		return fmt.Errorf("can't set precision: asset can't be empty")
+	}
+	if err := portfolio.ValidatePrecision(cmd.Places); err != nil {
+		return fmt.Errorf("can't set precision of %s: %w", cmd.Asset, err)
+	}
+	return h.repo.SetPrecision(ctx, cmd.Asset, cmd.Places)
+}
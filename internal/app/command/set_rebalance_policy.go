@@ -0,0 +1,60 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/invine/portfolio/internal/domain/instrument"
+	"github.com/invine/portfolio/internal/domain/portfolio"
+)
+
+type SetRebalancePolicy struct {
+	UserID      uuid.UUID
+	PortfolioID uuid.UUID
+	Policy      portfolio.RebalancePolicy
+}
+
+type SetRebalancePolicyHandler struct {
+	repo        portfolio.PortfolioRepository
+	instruments instrument.InstrumentRepository
+}
+
+func NewSetRebalancePolicyHandler(repo portfolio.PortfolioRepository, instruments instrument.InstrumentRepository) (*SetRebalancePolicyHandler, error) {
+	if repo == nil {
+		return nil, fmt.Errorf("portfolio repo can't be empty")
+	}
+	if instruments == nil {
+		return nil, fmt.Errorf("instrument repo can't be empty")
+	}
+	return &SetRebalancePolicyHandler{repo: repo, instruments: instruments}, nil
+}
+
+func (h SetRebalancePolicyHandler) Handle(ctx context.Context, cmd SetRebalancePolicy) error {
+	policy := cmd.Policy
+	policy.Targets = []portfolio.Target{}
+	for _, t := range cmd.Policy.Targets {
+		if t.Key == portfolio.ByAsset {
+			i, err := h.instruments.FindInstrument(ctx, t.Name)
+			switch {
+			case err == nil:
+				t.Name = i.Symbol()
+			case !errors.Is(err, instrument.ErrInstrumentNotFound):
+				return fmt.Errorf("can't resolve instrument of %s: %w", t.Name, err)
+			}
+		}
+		policy.Targets = append(policy.Targets, t)
+	}
+
+	return h.repo.UpdatePortfolio(
+		ctx,
+		cmd.UserID,
+		cmd.PortfolioID,
+		func(p *portfolio.Portfolio) error {
+			if err := p.SetRebalancePolicy(policy); err != nil {
+				return fmt.Errorf("can't update portfolio %s: %w", cmd.PortfolioID.String(), err)
+			}
+			return nil
+		})
+}
@@ -0,0 +1,68 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/invine/portfolio/internal/domain/instrument"
+	"github.com/invine/portfolio/internal/domain/portfolio"
+)
+
+type SettleOption struct {
+	UserID       uuid.UUID
+	PortfolioID  uuid.UUID
+	SettlementID uuid.UUID
+	TradeID      uuid.UUID
+	Settlement   portfolio.OptionSettlement
+}
+
+type SettleOptionHandler struct {
+	repo        portfolio.PortfolioRepository
+	instruments instrument.InstrumentRepository
+}
+
+func NewSettleOptionHandler(repo portfolio.PortfolioRepository, instruments instrument.InstrumentRepository) (*SettleOptionHandler, error) {
+	if repo == nil {
+		return nil, fmt.Errorf("portfolio repo can't be empty")
+	}
+	if instruments == nil {
+		return nil, fmt.Errorf("instrument repo can't be empty")
+	}
+	return &SettleOptionHandler{repo: repo, instruments: instruments}, nil
+}
+
+func (h SettleOptionHandler) Handle(ctx context.Context, cmd SettleOption) error {
+	s := cmd.Settlement
+	i, err := h.instruments.FindInstrument(ctx, s.Asset)
+	if err != nil {
+		return fmt.Errorf("can't resolve option %s: %w", s.Asset, err)
+	}
+	if i.Class() != instrument.Option {
+		return fmt.Errorf("can't settle option: %s is a %s", i.Symbol(), i.Class())
+	}
+	s.Asset = i.Symbol()
+	s.InstrumentID = i.ID()
+	s.Contract = i.Contract()
+	if s.Kind != portfolio.Expiry {
+		underlying, err := h.instruments.FindInstrument(ctx, s.Contract.Underlying)
+		switch {
+		case errors.Is(err, instrument.ErrInstrumentNotFound):
+			s.Contract.Underlying = instrument.NormalizeCode(s.Contract.Underlying)
+		case err != nil:
+			return fmt.Errorf("can't resolve underlying %s: %w", s.Contract.Underlying, err)
+		default:
+			s.Contract.Underlying = underlying.Symbol()
+			s.UnderlyingID = underlying.ID()
+		}
+	}
+
+	return h.repo.UpdatePortfolio(
+		ctx,
+		cmd.UserID,
+		cmd.PortfolioID,
+		func(p *portfolio.Portfolio) error {
+			return p.SettleOption(cmd.SettlementID, cmd.TradeID, s)
+		})
+}
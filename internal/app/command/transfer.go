@@ -0,0 +1,65 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/invine/portfolio/internal/domain/instrument"
+	"github.com/invine/portfolio/internal/domain/portfolio"
+)
+
+type Transfer struct {
+	UserID          uuid.UUID
+	FromPortfolioID uuid.UUID
+	ToPortfolioID   uuid.UUID
+	OutID           uuid.UUID
+	InID            uuid.UUID
+	Transfer        portfolio.Transfer
+}
+
+type TransferHandler struct {
+	repo        portfolio.PortfolioRepository
+	instruments instrument.InstrumentRepository
+}
+
+func NewTransferHandler(repo portfolio.PortfolioRepository, instruments instrument.InstrumentRepository) (*TransferHandler, error) {
+	if repo == nil {
+		return nil, fmt.Errorf("portfolio repo can't be empty")
+	}
+	if instruments == nil {
+		return nil, fmt.Errorf("instrument repo can't be empty")
+	}
+	return &TransferHandler{repo: repo, instruments: instruments}, nil
+}
+
+func (h TransferHandler) Handle(ctx context.Context, cmd Transfer) error {
+	if cmd.FromPortfolioID == cmd.ToPortfolioID {
+		return fmt.Errorf("can't transfer: portfolios must be different")
+	}
+	tr := cmd.Transfer
+	if tr.Asset != "" {
+		i, err := h.instruments.FindInstrument(ctx, tr.Asset)
+		switch {
+		case errors.Is(err, instrument.ErrInstrumentNotFound):
+			if _, idErr := uuid.Parse(tr.Asset); idErr == nil {
+				return fmt.Errorf("can't resolve instrument of %s: %w", tr.Asset, err)
+			}
+			tr.Asset = instrument.NormalizeCode(tr.Asset)
+		case err != nil:
+			return fmt.Errorf("can't resolve instrument of %s: %w", tr.Asset, err)
+		default:
+			tr.Asset = i.Symbol()
+			tr.InstrumentID = i.ID()
+		}
+	}
+
+	return h.repo.UpdatePortfolios(
+		ctx,
+		cmd.UserID,
+		[]uuid.UUID{cmd.FromPortfolioID, cmd.ToPortfolioID},
+		func(ps []*portfolio.Portfolio) error {
+			return ps[0].TransferTo(ps[1], cmd.OutID, cmd.InID, tr)
+		})
+}
@@ -0,0 +1,78 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/invine/portfolio/internal/domain/portfolio"
+)
+
+type UpdateGroup struct {
+	UserID  uuid.UUID
+	GroupID uuid.UUID
+	// Name is left unchanged if empty
+	Name string
+	// ParentID moves the group into another one, or to the top if it's nil, left unchanged if nil pointer
+	ParentID *uuid.UUID
+	// Portfolios replace the ones held by the group, left unchanged if nil
+	Portfolios []uuid.UUID
+}
+
+type UpdateGroupHandler struct {
+	repo       portfolio.GroupRepository
+	portfolios portfolio.PortfolioRepository
+}
+
+func NewUpdateGroupHandler(repo portfolio.GroupRepository, portfolios portfolio.PortfolioRepository) (*UpdateGroupHandler, error) {
+	if repo == nil {
+		return nil, fmt.Errorf("group repo can't be empty")
+	}
+	if portfolios == nil {
+		return nil, fmt.Errorf("portfolio repo can't be empty")
+	}
+	return &UpdateGroupHandler{repo: repo, portfolios: portfolios}, nil
+}
+
+func (h UpdateGroupHandler) Handle(ctx context.Context, cmd UpdateGroup) error {
+	if err := checkPortfolios(ctx, h.portfolios, cmd.UserID, cmd.Portfolios); err != nil {
+		return fmt.Errorf("can't update group %s: %w", cmd.GroupID.String(), err)
+	}
+	groups, err := h.repo.GetGroups(ctx, cmd.UserID)
+	if err != nil {
+		return fmt.Errorf("can't update group %s: %w", cmd.GroupID.String(), err)
+	}
+
+	return h.repo.UpdateGroup(
+		ctx,
+		cmd.UserID,
+		cmd.GroupID,
+		func(g *portfolio.Group) error {
+			if cmd.Name != "" {
+				if err := g.Rename(cmd.Name); err != nil {
+					return fmt.Errorf("can't update group %s: %w", cmd.GroupID.String(), err)
+				}
+			}
+			if cmd.ParentID != nil {
+				if err := g.Move(*cmd.ParentID); err != nil {
+					return fmt.Errorf("can't update group %s: %w", cmd.GroupID.String(), err)
+				}
+			}
+			if cmd.Portfolios != nil {
+				if err := g.SetPortfolios(cmd.Portfolios); err != nil {
+					return fmt.Errorf("can't update group %s: %w", cmd.GroupID.String(), err)
+				}
+			}
+
+			tree := []*portfolio.Group{g}
+			for _, other := range groups {
+				if other.ID() != g.ID() {
+					tree = append(tree, other)
+				}
+			}
+			if err := portfolio.CheckGroups(tree); err != nil {
+				return fmt.Errorf("can't update group %s: %w", cmd.GroupID.String(), err)
+			}
+			return nil
+		})
+}
@@ -0,0 +1,37 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/invine/portfolio/internal/domain/instrument"
+)
+
+type UpdateInstrument struct {
+	ID      uuid.UUID
+	Symbol  string
+	Name    string
+	Class   instrument.AssetClass
+	Details instrument.Details
+}
+
+type UpdateInstrumentHandler struct {
+	repo instrument.InstrumentRepository
+}
+
+func NewUpdateInstrumentHandler(repo instrument.InstrumentRepository) (*UpdateInstrumentHandler, error) {
+	if repo == nil {
+		return nil, fmt.Errorf("instrument repo can't be empty")
+	}
+	return &UpdateInstrumentHandler{repo: repo}, nil
+}
+
+func (h UpdateInstrumentHandler) Handle(ctx context.Context, cmd UpdateInstrument) error {
+	return h.repo.UpdateInstrument(
+		ctx,
+		cmd.ID,
+		func(i *instrument.Instrument) error {
+			return i.Update(cmd.Symbol, cmd.Name, cmd.Class, cmd.Details)
+		})
+}
@@ -0,0 +1,73 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/invine/portfolio/internal/domain/portfolio"
+)
+
+type UpdatePortfolio struct {
+	UserID          uuid.UUID
+	PortfolioID     uuid.UUID
+	Name            string
+	CostBasisMethod portfolio.CostBasisMethod
+	// Overdraft allows the cash balance to go below zero, left unchanged if nil
+	Overdraft *bool
+	// ShortSelling allows selling more of an asset than is held, left unchanged if nil
+	ShortSelling *bool
+	// WashSaleRule makes losses on sales replaced within 30 days disallowed, left unchanged if nil
+	WashSaleRule *bool
+	// Currency is the reporting currency, left unchanged if empty
+	Currency string
+}
+
+type UpdatePortfolioHandler struct {
+	repo portfolio.PortfolioRepository
+}
+
+func NewUpdatePortfolioHandler(repo portfolio.PortfolioRepository) (*UpdatePortfolioHandler, error) {
+	if repo == nil {
+		return nil, fmt.Errorf("portfolio repo can't be empty")
+	}
+	return &UpdatePortfolioHandler{repo: repo}, nil
+}
+
+func (h UpdatePortfolioHandler) Handle(ctx context.Context, cmd UpdatePortfolio) error {
+	return h.repo.UpdatePortfolio(
+		ctx,
+		cmd.UserID,
+		cmd.PortfolioID,
+		func(p *portfolio.Portfolio) error {
+			if cmd.Name != "" {
+				if err := p.RenamePortfolio(cmd.Name); err != nil {
+					return fmt.Errorf("can't update portfolio %s: %w", cmd.PortfolioID.String(), err)
+				}
+			}
+			if cmd.CostBasisMethod != "" {
+				if err := p.ChangeCostBasisMethod(cmd.CostBasisMethod); err != nil {
+					return fmt.Errorf("can't update portfolio %s: %w", cmd.PortfolioID.String(), err)
+				}
+			}
+			if cmd.Overdraft != nil {
+				if err := p.ChangeOverdraft(*cmd.Overdraft); err != nil {
+					return fmt.Errorf("can't update portfolio %s: %w", cmd.PortfolioID.String(), err)
+				}
+			}
+			if cmd.ShortSelling != nil {
+				if err := p.ChangeShortSelling(*cmd.ShortSelling); err != nil {
+					return fmt.Errorf("can't update portfolio %s: %w", cmd.PortfolioID.String(), err)
+				}
+			}
+			if cmd.WashSaleRule != nil {
+				p.ChangeWashSaleRule(*cmd.WashSaleRule)
+			}
+			if cmd.Currency != "" {
+				if err := p.ChangeCurrency(cmd.Currency); err != nil {
+					return fmt.Errorf("can't update portfolio %s: %w", cmd.PortfolioID.String(), err)
+				}
+			}
+			return nil
+		})
+}
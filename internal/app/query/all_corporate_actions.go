@@ -0,0 +1,44 @@
+package query
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/invine/portfolio/internal/domain/portfolio"
+)
+
+type AllCorporateActionsHandler struct {
+	readModel AllCorporateActionsReadModel
+}
+
+type AllCorporateActionsReadModel interface {
+	GetCorporateActions(ctx context.Context) ([]*portfolio.CorporateAction, error)
+}
+
+type AllCorporateActions struct {
+	Asset string
+}
+
+func NewAllCorporateActionsHandler(readModel AllCorporateActionsReadModel) (*AllCorporateActionsHandler, error) {
+	if readModel == nil {
+		return nil, fmt.Errorf("empty readModel")
+	}
+	return &AllCorporateActionsHandler{readModel: readModel}, nil
+}
+
+func (h AllCorporateActionsHandler) Handle(ctx context.Context, query AllCorporateActions) ([]*portfolio.CorporateAction, error) {
+	actions, err := h.readModel.GetCorporateActions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if query.Asset == "" {
+		return actions, nil
+	}
+	res := []*portfolio.CorporateAction{}
+	for _, a := range actions {
+		if a.Asset() == query.Asset || a.NewAsset() == query.Asset {
+			res = append(res, a)
+		}
+	}
+	return res, nil
+}
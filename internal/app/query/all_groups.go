@@ -0,0 +1,32 @@
+package query
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/invine/portfolio/internal/domain/portfolio"
+)
+
+type AllGroupsHandler struct {
+	readModel AllGroupsReadModel
+}
+
+type AllGroupsReadModel interface {
+	GetGroups(ctx context.Context, userID uuid.UUID) ([]*portfolio.Group, error)
+}
+
+type AllGroups struct {
+	UserID uuid.UUID
+}
+
+func NewAllGroupsHandler(readModel AllGroupsReadModel) (*AllGroupsHandler, error) {
+	if readModel == nil {
+		return nil, fmt.Errorf("empty readModel")
+	}
+	return &AllGroupsHandler{readModel: readModel}, nil
+}
+
+func (h AllGroupsHandler) Handle(ctx context.Context, query AllGroups) ([]*portfolio.Group, error) {
+	return h.readModel.GetGroups(ctx, query.UserID)
+}
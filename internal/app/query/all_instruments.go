@@ -0,0 +1,43 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/invine/portfolio/internal/domain/instrument"
+)
+
+type AllInstrumentsHandler struct {
+	readModel AllInstrumentsReadModel
+}
+
+type AllInstrumentsReadModel interface {
+	GetInstruments(ctx context.Context) ([]*instrument.Instrument, error)
+	FindInstrument(ctx context.Context, code string) (*instrument.Instrument, error)
+}
+
+type AllInstruments struct {
+	Code string
+}
+
+func NewAllInstrumentsHandler(readModel AllInstrumentsReadModel) (*AllInstrumentsHandler, error) {
+	if readModel == nil {
+		return nil, fmt.Errorf("empty readModel")
+	}
+	return &AllInstrumentsHandler{readModel: readModel}, nil
+}
+
+func (h AllInstrumentsHandler) Handle(ctx context.Context, query AllInstruments) ([]*instrument.Instrument, error) {
+	if query.Code == "" {
+		return h.readModel.GetInstruments(ctx)
+	}
+	i, err := h.readModel.FindInstrument(ctx, query.Code)
+	if errors.Is(err, instrument.ErrInstrumentNotFound) {
+		return []*instrument.Instrument{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return []*instrument.Instrument{i}, nil
+}
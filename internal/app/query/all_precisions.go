@@ -0,0 +1,29 @@
+package query
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/invine/portfolio/internal/domain/portfolio"
+)
+
+type AllPrecisionsHandler struct {
+	readModel AllPrecisionsReadModel
+}
+
+type AllPrecisionsReadModel interface {
+	GetPrecisions(ctx context.Context) (portfolio.Precisions, error)
+}
+
+type AllPrecisions struct{}
+
+func NewAllPrecisionsHandler(readModel AllPrecisionsReadModel) (*AllPrecisionsHandler, error) {
+	if readModel == nil {
+		return nil, fmt.Errorf("empty readModel")
+	}
+	return &AllPrecisionsHandler{readModel: readModel}, nil
+}
+
+func (h AllPrecisionsHandler) Handle(ctx context.Context, query AllPrecisions) (portfolio.Precisions, error) {
+	return h.readModel.GetPrecisions(ctx)
+}
@@ -0,0 +1,81 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/invine/portfolio/internal/domain/instrument"
+	"github.com/invine/portfolio/internal/domain/portfolio"
+	"github.com/invine/portfolio/internal/domain/price"
+)
+
+type AllocationHandler struct {
+	snapshot    PortfolioHandler
+	rates       RatesReadModel
+	instruments InstrumentsReadModel
+}
+
+type InstrumentsReadModel interface {
+	GetInstruments(ctx context.Context) ([]*instrument.Instrument, error)
+}
+
+type Allocation struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+	Date   time.Time
+	By     []portfolio.AllocationKey
+}
+
+func NewAllocationHandler(readModel PortfolioReadModel, prices price.PriceProvider, history price.PriceHistoryRepository, rates RatesReadModel, instruments InstrumentsReadModel) (*AllocationHandler, error) {
+	snapshot, err := NewPortfolioHandler(readModel, prices, history, rates)
+	if err != nil {
+		return nil, err
+	}
+	if instruments == nil {
+		return nil, fmt.Errorf("empty instruments")
+	}
+	return &AllocationHandler{snapshot: *snapshot, rates: rates, instruments: instruments}, nil
+}
+
+func (h AllocationHandler) Handle(ctx context.Context, query Allocation) (*portfolio.Allocation, error) {
+	s, err := h.snapshot.Handle(ctx, Portfolio{ID: query.ID, UserID: query.UserID, Date: query.Date})
+	if err != nil {
+		return nil, err
+	}
+
+	classes, err := classifications(ctx, h.instruments)
+	if err != nil {
+		return nil, fmt.Errorf("can't allocate portfolio %s: %w", query.ID.String(), err)
+	}
+
+	rates, err := h.rates.GetRates(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("can't allocate portfolio %s: %w", query.ID.String(), err)
+	}
+	res, err := s.Allocation(query.By, classes, rates)
+	if err != nil {
+		return nil, fmt.Errorf("can't allocate portfolio %s: %w", query.ID.String(), err)
+	}
+	return res, nil
+}
+
+func classifications(ctx context.Context, instruments InstrumentsReadModel) (map[string]portfolio.Classification, error) {
+	is, err := instruments.GetInstruments(ctx)
+	if err != nil {
+		return nil, err
+	}
+	classes := map[string]portfolio.Classification{}
+	for _, i := range is {
+		d := i.Details()
+		classes[i.Symbol()] = portfolio.Classification{
+			Class:    string(i.Class()),
+			Sector:   d.Sector,
+			Country:  d.Country,
+			Currency: d.Currency,
+			Tags:     d.Tags,
+		}
+	}
+	return classes, nil
+}
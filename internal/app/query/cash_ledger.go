@@ -0,0 +1,42 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/invine/portfolio/internal/domain/portfolio"
+)
+
+type CashLedgerHandler struct {
+	readModel PortfolioReadModel
+}
+
+type CashLedger struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+	From   time.Time
+	To     time.Time
+	// Currency of the cash, the reporting currency of the portfolio if empty
+	Currency string
+}
+
+func NewCashLedgerHandler(readModel PortfolioReadModel) (*CashLedgerHandler, error) {
+	if readModel == nil {
+		return nil, fmt.Errorf("empty readModel")
+	}
+	return &CashLedgerHandler{readModel: readModel}, nil
+}
+
+func (h CashLedgerHandler) Handle(ctx context.Context, query CashLedger) (*portfolio.CashLedger, error) {
+	p, err := h.readModel.GetPortfolio(ctx, query.UserID, query.ID)
+	if err != nil {
+		return nil, fmt.Errorf("can't get portfolio %s: %w", query.ID.String(), err)
+	}
+	l, err := p.CashLedger(query.From, query.To, query.Currency)
+	if err != nil {
+		return nil, fmt.Errorf("can't get cash ledger of portfolio %s: %w", query.ID.String(), err)
+	}
+	return l, nil
+}
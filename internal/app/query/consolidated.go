@@ -0,0 +1,117 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/invine/portfolio/internal/domain/portfolio"
+	"github.com/invine/portfolio/internal/domain/price"
+)
+
+type ConsolidatedHandler struct {
+	snapshot  PortfolioHandler
+	readModel ConsolidatedReadModel
+}
+
+type ConsolidatedReadModel interface {
+	PortfolioReadModel
+	AllPortfoliosReadModel
+}
+
+type Consolidated struct {
+	UserID   uuid.UUID
+	IDs      []uuid.UUID
+	Date     time.Time
+	From     time.Time
+	To       time.Time
+	Currency string
+}
+
+func NewConsolidatedHandler(readModel ConsolidatedReadModel, prices price.PriceProvider, history price.PriceHistoryRepository, rates RatesReadModel) (*ConsolidatedHandler, error) {
+	snapshot, err := NewPortfolioHandler(readModel, prices, history, rates)
+	if err != nil {
+		return nil, err
+	}
+	return &ConsolidatedHandler{snapshot: *snapshot, readModel: readModel}, nil
+}
+
+func (h ConsolidatedHandler) Handle(ctx context.Context, query Consolidated) (*portfolio.Consolidated, error) {
+	ids := query.IDs
+	if len(ids) == 0 {
+		all, err := h.readModel.GetAllPortfolios(ctx, query.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("can't consolidate portfolios: %w", err)
+		}
+		for _, p := range all {
+			ids = append(ids, p.ID())
+		}
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("can't consolidate portfolios: user %s has no portfolios", query.UserID.String())
+	}
+
+	portfolios := []*portfolio.Portfolio{}
+	seen := map[uuid.UUID]bool{}
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		p, err := h.readModel.GetPortfolio(ctx, query.UserID, id)
+		if err != nil {
+			return nil, fmt.Errorf("can't get portfolio %s: %w", id.String(), err)
+		}
+		portfolios = append(portfolios, p)
+	}
+
+	currency := query.Currency
+	if currency == "" {
+		currency = portfolios[0].Currency()
+		for _, p := range portfolios {
+			if p.Currency() != currency {
+				currency = portfolio.DefaultCurrency
+				break
+			}
+		}
+	}
+
+	snapshots := []*portfolio.Snapshot{}
+	for _, p := range portfolios {
+		// the portfolios are loaded for the query only, so they are reported in one currency without being saved
+		if err := p.ChangeCurrency(currency); err != nil {
+			return nil, fmt.Errorf("can't consolidate portfolios: %w", err)
+		}
+		s, _, err := h.snapshot.value(ctx, p, query.Date)
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, s)
+	}
+
+	rates, err := h.snapshot.rates.GetRates(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("can't consolidate portfolios: %w", err)
+	}
+	c, err := portfolio.Consolidate(snapshots, rates)
+	if err != nil {
+		return nil, err
+	}
+
+	prices := map[string]price.Series{}
+	for _, p := range portfolios {
+		ps, err := priceSeries(ctx, h.snapshot.history, p, query.From, query.To)
+		if err != nil {
+			return nil, fmt.Errorf("can't get performance of portfolio %s: %w", p.ID().String(), err)
+		}
+		for asset, s := range ps {
+			prices[asset] = s
+		}
+	}
+	c.Performance, err = portfolio.ConsolidatedPerformance(portfolios, query.From, query.To, prices, rates)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
@@ -0,0 +1,49 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/invine/portfolio/internal/domain/portfolio"
+)
+
+type FeesHandler struct {
+	readModel PortfolioReadModel
+	rates     RatesReadModel
+}
+
+type Fees struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+	From   time.Time
+	To     time.Time
+	Period portfolio.Period
+}
+
+func NewFeesHandler(readModel PortfolioReadModel, rates RatesReadModel) (*FeesHandler, error) {
+	if readModel == nil {
+		return nil, fmt.Errorf("empty readModel")
+	}
+	if rates == nil {
+		return nil, fmt.Errorf("empty exchange rates")
+	}
+	return &FeesHandler{readModel: readModel, rates: rates}, nil
+}
+
+func (h FeesHandler) Handle(ctx context.Context, query Fees) (*portfolio.FeesReport, error) {
+	p, err := h.readModel.GetPortfolio(ctx, query.UserID, query.ID)
+	if err != nil {
+		return nil, fmt.Errorf("can't get portfolio %s: %w", query.ID.String(), err)
+	}
+	rates, err := h.rates.GetRates(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("can't get fees of portfolio %s: %w", query.ID.String(), err)
+	}
+	res, err := p.Fees(query.From, query.To, query.Period, rates)
+	if err != nil {
+		return nil, fmt.Errorf("can't get fees of portfolio %s: %w", query.ID.String(), err)
+	}
+	return res, nil
+}
@@ -0,0 +1,49 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/invine/portfolio/internal/domain/portfolio"
+)
+
+type GainsHandler struct {
+	readModel PortfolioReadModel
+	rates     RatesReadModel
+}
+
+type Gains struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+	From   time.Time
+	To     time.Time
+	Rule   portfolio.HoldingRule
+}
+
+func NewGainsHandler(readModel PortfolioReadModel, rates RatesReadModel) (*GainsHandler, error) {
+	if readModel == nil {
+		return nil, fmt.Errorf("empty readModel")
+	}
+	if rates == nil {
+		return nil, fmt.Errorf("empty exchange rates")
+	}
+	return &GainsHandler{readModel: readModel, rates: rates}, nil
+}
+
+func (h GainsHandler) Handle(ctx context.Context, query Gains) (*portfolio.GainsReport, error) {
+	p, err := h.readModel.GetPortfolio(ctx, query.UserID, query.ID)
+	if err != nil {
+		return nil, fmt.Errorf("can't get portfolio %s: %w", query.ID.String(), err)
+	}
+	rates, err := h.rates.GetRates(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("can't get gains of portfolio %s: %w", query.ID.String(), err)
+	}
+	res, err := p.Gains(query.From, query.To, query.Rule, rates)
+	if err != nil {
+		return nil, fmt.Errorf("can't get gains of portfolio %s: %w", query.ID.String(), err)
+	}
+	return res, nil
+}
@@ -0,0 +1,68 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/invine/portfolio/internal/domain/portfolio"
+	"github.com/invine/portfolio/internal/domain/price"
+)
+
+type GroupHandler struct {
+	groups       AllGroupsReadModel
+	consolidated ConsolidatedHandler
+}
+
+type Group struct {
+	ID       uuid.UUID
+	UserID   uuid.UUID
+	Date     time.Time
+	From     time.Time
+	To       time.Time
+	Currency string
+}
+
+func NewGroupHandler(groups AllGroupsReadModel, readModel ConsolidatedReadModel, prices price.PriceProvider, history price.PriceHistoryRepository, rates RatesReadModel) (*GroupHandler, error) {
+	if groups == nil {
+		return nil, fmt.Errorf("empty groups readModel")
+	}
+	consolidated, err := NewConsolidatedHandler(readModel, prices, history, rates)
+	if err != nil {
+		return nil, err
+	}
+	return &GroupHandler{groups: groups, consolidated: *consolidated}, nil
+}
+
+func (h GroupHandler) Handle(ctx context.Context, query Group) (*portfolio.GroupReport, error) {
+	groups, err := h.groups.GetGroups(ctx, query.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("can't get group %s: %w", query.ID.String(), err)
+	}
+	subgroups := portfolio.Subgroups(groups, query.ID)
+	if len(subgroups) == 0 {
+		return nil, fmt.Errorf("can't get group %s: %w", query.ID.String(), portfolio.ErrGroupNotFound)
+	}
+
+	report := &portfolio.GroupReport{Group: subgroups[0], Subgroups: subgroups[1:], Portfolios: []uuid.UUID{}}
+	for _, g := range subgroups {
+		report.Portfolios = append(report.Portfolios, g.Portfolios()...)
+	}
+	if len(report.Portfolios) == 0 {
+		return report, nil
+	}
+
+	report.Consolidated, err = h.consolidated.Handle(ctx, Consolidated{
+		UserID:   query.UserID,
+		IDs:      report.Portfolios,
+		Date:     query.Date,
+		From:     query.From,
+		To:       query.To,
+		Currency: query.Currency,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("can't get group %s: %w", query.ID.String(), err)
+	}
+	return report, nil
+}
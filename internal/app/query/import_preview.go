@@ -0,0 +1,81 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/invine/portfolio/internal/domain/instrument"
+	"github.com/invine/portfolio/internal/domain/portfolio"
+)
+
+type ImportPreviewHandler struct {
+	readModel   PortfolioReadModel
+	instruments AllInstrumentsReadModel
+}
+
+type ImportPreview struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	Rows      []portfolio.ImportRow
+	Positions []portfolio.StatementPosition
+}
+
+func NewImportPreviewHandler(readModel PortfolioReadModel, instruments AllInstrumentsReadModel) (*ImportPreviewHandler, error) {
+	if readModel == nil {
+		return nil, fmt.Errorf("empty readModel")
+	}
+	if instruments == nil {
+		return nil, fmt.Errorf("empty instruments")
+	}
+	return &ImportPreviewHandler{readModel: readModel, instruments: instruments}, nil
+}
+
+func (h ImportPreviewHandler) Handle(ctx context.Context, query ImportPreview) (*portfolio.ImportPreview, error) {
+	p, err := h.readModel.GetPortfolio(ctx, query.UserID, query.ID)
+	if err != nil {
+		return nil, fmt.Errorf("can't get portfolio %s: %w", query.ID.String(), err)
+	}
+	rows := make([]portfolio.ImportRow, len(query.Rows))
+	copy(rows, query.Rows)
+	for i, r := range rows {
+		if r.Err != nil || r.Transaction == nil || r.Transaction.Asset() == "" {
+			continue
+		}
+		ins, err := h.instruments.FindInstrument(ctx, r.Transaction.Asset())
+		if errors.Is(err, instrument.ErrInstrumentNotFound) {
+			if _, idErr := uuid.Parse(r.Transaction.Asset()); idErr == nil {
+				rows[i].Err = fmt.Errorf("can't resolve instrument of %s: %w", r.Transaction.Asset(), err)
+			} else if err := r.Transaction.SetAsset(instrument.NormalizeCode(r.Transaction.Asset())); err != nil {
+				rows[i].Err = err
+			}
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("can't preview import to portfolio %s: %w", query.ID.String(), err)
+		}
+		if err := ins.Link(r.Transaction); err != nil {
+			rows[i].Err = err
+		}
+	}
+	positions := make([]portfolio.StatementPosition, len(query.Positions))
+	copy(positions, query.Positions)
+	for i, pos := range positions {
+		ins, err := h.instruments.FindInstrument(ctx, pos.Asset)
+		if errors.Is(err, instrument.ErrInstrumentNotFound) {
+			positions[i].Asset = instrument.NormalizeCode(pos.Asset)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("can't preview import to portfolio %s: %w", query.ID.String(), err)
+		}
+		positions[i].Asset = ins.Symbol()
+	}
+
+	res := p.Import(rows)
+	if res.Positions, err = p.Reconcile(positions); err != nil {
+		return nil, fmt.Errorf("can't preview import to portfolio %s: %w", query.ID.String(), err)
+	}
+	return res, nil
+}
@@ -0,0 +1,49 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/invine/portfolio/internal/domain/portfolio"
+)
+
+type IncomeHandler struct {
+	readModel PortfolioReadModel
+	rates     RatesReadModel
+}
+
+type Income struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+	From   time.Time
+	To     time.Time
+	Period portfolio.Period
+}
+
+func NewIncomeHandler(readModel PortfolioReadModel, rates RatesReadModel) (*IncomeHandler, error) {
+	if readModel == nil {
+		return nil, fmt.Errorf("empty readModel")
+	}
+	if rates == nil {
+		return nil, fmt.Errorf("empty exchange rates")
+	}
+	return &IncomeHandler{readModel: readModel, rates: rates}, nil
+}
+
+func (h IncomeHandler) Handle(ctx context.Context, query Income) (*portfolio.IncomeReport, error) {
+	p, err := h.readModel.GetPortfolio(ctx, query.UserID, query.ID)
+	if err != nil {
+		return nil, fmt.Errorf("can't get portfolio %s: %w", query.ID.String(), err)
+	}
+	rates, err := h.rates.GetRates(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("can't get income of portfolio %s: %w", query.ID.String(), err)
+	}
+	res, err := p.Income(query.From, query.To, query.Period, rates)
+	if err != nil {
+		return nil, fmt.Errorf("can't get income of portfolio %s: %w", query.ID.String(), err)
+	}
+	return res, nil
+}
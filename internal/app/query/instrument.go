@@ -0,0 +1,32 @@
+package query
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/invine/portfolio/internal/domain/instrument"
+)
+
+type InstrumentHandler struct {
+	readModel InstrumentReadModel
+}
+
+type InstrumentReadModel interface {
+	GetInstrument(ctx context.Context, id uuid.UUID) (*instrument.Instrument, error)
+}
+
+type Instrument struct {
+	ID uuid.UUID
+}
+
+func NewInstrumentHandler(readModel InstrumentReadModel) (*InstrumentHandler, error) {
+	if readModel == nil {
+		return nil, fmt.Errorf("empty readModel")
+	}
+	return &InstrumentHandler{readModel: readModel}, nil
+}
+
+func (h InstrumentHandler) Handle(ctx context.Context, query Instrument) (*instrument.Instrument, error) {
+	return h.readModel.GetInstrument(ctx, query.ID)
+}
@@ -0,0 +1,60 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/invine/portfolio/internal/domain/portfolio"
+	"github.com/invine/portfolio/internal/domain/price"
+)
+
+type PerformanceHandler struct {
+	readModel PortfolioReadModel
+	history   price.PriceHistoryRepository
+	rates     RatesReadModel
+}
+
+type Performance struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+	From   time.Time
+	To     time.Time
+}
+
+func NewPerformanceHandler(readModel PortfolioReadModel, history price.PriceHistoryRepository, rates RatesReadModel) (*PerformanceHandler, error) {
+	if readModel == nil {
+		return nil, fmt.Errorf("empty readModel")
+	}
+	if history == nil {
+		return nil, fmt.Errorf("empty price history")
+	}
+	if rates == nil {
+		return nil, fmt.Errorf("empty exchange rates")
+	}
+	return &PerformanceHandler{readModel: readModel, history: history, rates: rates}, nil
+}
+
+func (h PerformanceHandler) Handle(ctx context.Context, query Performance) (*portfolio.Performance, error) {
+	p, err := h.readModel.GetPortfolio(ctx, query.UserID, query.ID)
+	if err != nil {
+		return nil, fmt.Errorf("can't get portfolio %s: %w", query.ID.String(), err)
+	}
+
+	prices, err := priceSeries(ctx, h.history, p, query.From, query.To)
+	if err != nil {
+		return nil, fmt.Errorf("can't get performance of portfolio %s: %w", query.ID.String(), err)
+	}
+
+	rates, err := h.rates.GetRates(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("can't get performance of portfolio %s: %w", query.ID.String(), err)
+	}
+
+	perf, err := p.Performance(query.From, query.To, prices, rates)
+	if err != nil {
+		return nil, fmt.Errorf("can't get performance of portfolio %s: %w", query.ID.String(), err)
+	}
+	return perf, nil
+}
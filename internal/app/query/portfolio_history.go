@@ -0,0 +1,85 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/invine/portfolio/internal/domain/portfolio"
+	"github.com/invine/portfolio/internal/domain/price"
+)
+
+const maxHistoryPoints = 5000
+
+type PortfolioHistoryHandler struct {
+	readModel PortfolioReadModel
+	history   price.PriceHistoryRepository
+	rates     RatesReadModel
+}
+
+type PortfolioHistory struct {
+	ID       uuid.UUID
+	UserID   uuid.UUID
+	From     time.Time
+	To       time.Time
+	Interval portfolio.Period
+}
+
+func NewPortfolioHistoryHandler(readModel PortfolioReadModel, history price.PriceHistoryRepository, rates RatesReadModel) (*PortfolioHistoryHandler, error) {
+	if readModel == nil {
+		return nil, fmt.Errorf("empty readModel")
+	}
+	if history == nil {
+		return nil, fmt.Errorf("empty price history")
+	}
+	if rates == nil {
+		return nil, fmt.Errorf("empty exchange rates")
+	}
+	return &PortfolioHistoryHandler{readModel: readModel, history: history, rates: rates}, nil
+}
+
+func (h PortfolioHistoryHandler) Handle(ctx context.Context, query PortfolioHistory) (*portfolio.History, error) {
+	if query.To.Before(query.From) {
+		return nil, fmt.Errorf("can't get history of portfolio %s: period ends before it starts", query.ID.String())
+	}
+	points := 0
+	for start := query.Interval.Start(query.From); !start.After(query.To); start = query.Interval.Next(start) {
+		if points++; points > maxHistoryPoints {
+			return nil, fmt.Errorf("can't get history of portfolio %s: more than %d points, choose a longer interval", query.ID.String(), maxHistoryPoints)
+		}
+	}
+
+	p, err := h.readModel.GetPortfolio(ctx, query.UserID, query.ID)
+	if err != nil {
+		return nil, fmt.Errorf("can't get portfolio %s: %w", query.ID.String(), err)
+	}
+
+	prices, err := priceSeries(ctx, h.history, p, query.From, query.To)
+	if err != nil {
+		return nil, fmt.Errorf("can't get history of portfolio %s: %w", query.ID.String(), err)
+	}
+
+	rates, err := h.rates.GetRates(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("can't get history of portfolio %s: %w", query.ID.String(), err)
+	}
+
+	history, err := p.History(query.From, query.To, query.Interval, prices, rates)
+	if err != nil {
+		return nil, fmt.Errorf("can't get history of portfolio %s: %w", query.ID.String(), err)
+	}
+	return history, nil
+}
+
+func priceSeries(ctx context.Context, history price.PriceHistoryRepository, p *portfolio.Portfolio, from, to time.Time) (map[string]price.Series, error) {
+	prices := map[string]price.Series{}
+	for _, asset := range p.Assets() {
+		s, err := history.GetPriceHistory(ctx, asset, price.LastCloseDate(from), to)
+		if err != nil {
+			return nil, err
+		}
+		prices[asset] = s
+	}
+	return prices, nil
+}
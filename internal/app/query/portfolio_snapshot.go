@@ -2,32 +2,51 @@ package query
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/invine/portfolio/internal/domain/fx"
 	"github.com/invine/portfolio/internal/domain/portfolio"
+	"github.com/invine/portfolio/internal/domain/price"
 )
 
 type PortfolioHandler struct {
 	readModel PortfolioReadModel
+	prices    price.PriceProvider
+	history   price.PriceHistoryRepository
+	rates     RatesReadModel
 }
 
 type PortfolioReadModel interface {
 	GetPortfolio(ctx context.Context, userID, id uuid.UUID) (*portfolio.Portfolio, error)
 }
 
+type RatesReadModel interface {
+	GetRates(ctx context.Context) (*fx.Rates, error)
+}
+
 type Portfolio struct {
 	ID     uuid.UUID
 	UserID uuid.UUID
 	Date   time.Time
 }
 
-func NewPortfolioHandler(readModel PortfolioReadModel) (*PortfolioHandler, error) {
+func NewPortfolioHandler(readModel PortfolioReadModel, prices price.PriceProvider, history price.PriceHistoryRepository, rates RatesReadModel) (*PortfolioHandler, error) {
 	if readModel == nil {
 		return nil, fmt.Errorf("empty readModel")
 	}
-	return &PortfolioHandler{readModel: readModel}, nil
+	if prices == nil {
+		return nil, fmt.Errorf("empty price provider")
+	}
+	if history == nil {
+		return nil, fmt.Errorf("empty price history")
+	}
+	if rates == nil {
+		return nil, fmt.Errorf("empty exchange rates")
+	}
+	return &PortfolioHandler{readModel: readModel, prices: prices, history: history, rates: rates}, nil
 
 }
 
@@ -36,5 +55,67 @@ func (h PortfolioHandler) Handle(ctx context.Context, query Portfolio) (*portfol
 	if err != nil {
 		return nil, fmt.Errorf("can't get portfolio %s: %w", query.ID.String(), err)
 	}
-	return p.Snapshot(query.Date), nil
+	s, _, err := h.value(ctx, p, query.Date)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (h PortfolioHandler) value(ctx context.Context, p *portfolio.Portfolio, date time.Time) (*portfolio.Snapshot, map[string]*price.Price, error) {
+	s, err := p.Snapshot(date)
+	if err != nil {
+		return nil, nil, fmt.Errorf("can't get portfolio %s: %w", p.ID().String(), err)
+	}
+
+	assets := map[string]bool{}
+	for asset, pos := range s.Positions {
+		assets[asset] = true
+		if pos.Contract != nil {
+			assets[pos.Contract.Underlying] = true
+		}
+	}
+	prices := map[string]*price.Price{}
+	for asset := range assets {
+		pr, err := h.priceAt(ctx, asset, date)
+		if errors.Is(err, price.ErrPriceNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("can't value portfolio %s: %w", p.ID().String(), err)
+		}
+		prices[asset] = pr
+	}
+	rates, err := h.rates.GetRates(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("can't value portfolio %s: %w", p.ID().String(), err)
+	}
+	if err := s.Value(prices, rates); err != nil {
+		return nil, nil, fmt.Errorf("can't value portfolio %s: %w", p.ID().String(), err)
+	}
+
+	return s, prices, nil
+}
+
+func (h PortfolioHandler) priceAt(ctx context.Context, asset string, date time.Time) (*price.Price, error) {
+	quote, err := h.prices.GetPrice(ctx, asset)
+	if err != nil && !errors.Is(err, price.ErrPriceNotFound) {
+		return nil, err
+	}
+	if quote != nil && quote.Date().After(date) {
+		quote = nil
+	}
+
+	close, err := h.history.GetPriceAt(ctx, asset, price.LastCloseDate(date))
+	if err != nil && !errors.Is(err, price.ErrPriceNotFound) {
+		return nil, err
+	}
+
+	switch {
+	case quote != nil && (close == nil || !quote.Date().Before(close.Date())):
+		return quote, nil
+	case close != nil:
+		return close, nil
+	}
+	return nil, fmt.Errorf("can't get price of %s: %w", asset, price.ErrPriceNotFound)
 }
@@ -0,0 +1,50 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/invine/portfolio/internal/domain/portfolio"
+	"github.com/shopspring/decimal"
+)
+
+type ProfitLossHandler struct {
+	readModel PortfolioReadModel
+	rates     RatesReadModel
+}
+
+type ProfitLoss struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+	Date   time.Time
+	Period portfolio.Period
+	Prices map[string]decimal.Decimal
+}
+
+func NewProfitLossHandler(readModel PortfolioReadModel, rates RatesReadModel) (*ProfitLossHandler, error) {
+	if readModel == nil {
+		return nil, fmt.Errorf("empty readModel")
+	}
+	if rates == nil {
+		return nil, fmt.Errorf("empty exchange rates")
+	}
+	return &ProfitLossHandler{readModel: readModel, rates: rates}, nil
+}
+
+func (h ProfitLossHandler) Handle(ctx context.Context, query ProfitLoss) (*portfolio.ProfitLoss, error) {
+	p, err := h.readModel.GetPortfolio(ctx, query.UserID, query.ID)
+	if err != nil {
+		return nil, fmt.Errorf("can't get portfolio %s: %w", query.ID.String(), err)
+	}
+	rates, err := h.rates.GetRates(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("can't get profit and loss of portfolio %s: %w", query.ID.String(), err)
+	}
+	res, err := p.ProfitLoss(query.Date, query.Prices, query.Period, rates)
+	if err != nil {
+		return nil, fmt.Errorf("can't get profit and loss of portfolio %s: %w", query.ID.String(), err)
+	}
+	return res, nil
+}
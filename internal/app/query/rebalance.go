@@ -0,0 +1,74 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/invine/portfolio/internal/domain/portfolio"
+	"github.com/invine/portfolio/internal/domain/price"
+)
+
+type RebalanceHandler struct {
+	readModel   PortfolioReadModel
+	snapshot    PortfolioHandler
+	rates       RatesReadModel
+	instruments InstrumentsReadModel
+}
+
+type Rebalance struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+	Date   time.Time
+}
+
+func NewRebalanceHandler(readModel PortfolioReadModel, prices price.PriceProvider, history price.PriceHistoryRepository, rates RatesReadModel, instruments InstrumentsReadModel) (*RebalanceHandler, error) {
+	snapshot, err := NewPortfolioHandler(readModel, prices, history, rates)
+	if err != nil {
+		return nil, err
+	}
+	if instruments == nil {
+		return nil, fmt.Errorf("empty instruments")
+	}
+	return &RebalanceHandler{readModel: readModel, snapshot: *snapshot, rates: rates, instruments: instruments}, nil
+}
+
+func (h RebalanceHandler) Handle(ctx context.Context, query Rebalance) (*portfolio.Rebalance, error) {
+	p, err := h.readModel.GetPortfolio(ctx, query.UserID, query.ID)
+	if err != nil {
+		return nil, fmt.Errorf("can't get portfolio %s: %w", query.ID.String(), err)
+	}
+	s, prices, err := h.snapshot.value(ctx, p, query.Date)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range p.RebalancePolicy().Targets {
+		if _, ok := prices[t.Name]; ok || t.Key != portfolio.ByAsset {
+			continue
+		}
+		pr, err := h.snapshot.priceAt(ctx, t.Name, query.Date)
+		if errors.Is(err, price.ErrPriceNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("can't rebalance portfolio %s: %w", query.ID.String(), err)
+		}
+		prices[t.Name] = pr
+	}
+
+	classes, err := classifications(ctx, h.instruments)
+	if err != nil {
+		return nil, fmt.Errorf("can't rebalance portfolio %s: %w", query.ID.String(), err)
+	}
+	rates, err := h.rates.GetRates(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("can't rebalance portfolio %s: %w", query.ID.String(), err)
+	}
+	res, err := p.Rebalance(s, prices, classes, rates)
+	if err != nil {
+		return nil, fmt.Errorf("can't rebalance portfolio %s: %w", query.ID.String(), err)
+	}
+	return res, nil
+}
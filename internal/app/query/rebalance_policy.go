@@ -0,0 +1,33 @@
+package query
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/invine/portfolio/internal/domain/portfolio"
+)
+
+type RebalancePolicyHandler struct {
+	readModel PortfolioReadModel
+}
+
+type RebalancePolicy struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+}
+
+func NewRebalancePolicyHandler(readModel PortfolioReadModel) (*RebalancePolicyHandler, error) {
+	if readModel == nil {
+		return nil, fmt.Errorf("empty readModel")
+	}
+	return &RebalancePolicyHandler{readModel: readModel}, nil
+}
+
+func (h RebalancePolicyHandler) Handle(ctx context.Context, query RebalancePolicy) (portfolio.RebalancePolicy, error) {
+	p, err := h.readModel.GetPortfolio(ctx, query.UserID, query.ID)
+	if err != nil {
+		return portfolio.RebalancePolicy{}, fmt.Errorf("can't get portfolio %s: %w", query.ID.String(), err)
+	}
+	return p.RebalancePolicy(), nil
+}
@@ -0,0 +1,83 @@
+package fx
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+var ErrRateNotFound = errors.New("exchange rate not found")
+
+type Rate struct {
+	base   string
+	quote  string
+	date   time.Time
+	rate   decimal.Decimal
+	source string
+}
+
+func NewRate(base, quote string, date time.Time, rate decimal.Decimal, source string) (*Rate, error) {
+	r := &Rate{source: source}
+	if err := r.setCurrencies(base, quote); err != nil {
+		return nil, fmt.Errorf("can't create exchange rate: %w", err)
+	}
+	if date.IsZero() {
+		return nil, fmt.Errorf("can't create exchange rate: date can't be empty")
+	}
+	if !rate.IsPositive() {
+		return nil, fmt.Errorf("can't create exchange rate: rate must be positive")
+	}
+	r.date = date
+	r.rate = rate
+	return r, nil
+}
+
+func (r *Rate) setCurrencies(base, quote string) error {
+	var err error
+	if r.base, err = ParseCurrency(base); err != nil {
+		return err
+	}
+	if r.quote, err = ParseCurrency(quote); err != nil {
+		return err
+	}
+	if r.base == r.quote {
+		return fmt.Errorf("base and quote currencies must differ")
+	}
+	return nil
+}
+
+func (r *Rate) Base() string {
+	return r.base
+}
+
+func (r *Rate) Quote() string {
+	return r.quote
+}
+
+func (r *Rate) Date() time.Time {
+	return r.date
+}
+
+func (r *Rate) Rate() decimal.Decimal {
+	return r.rate
+}
+
+func (r *Rate) Source() string {
+	return r.source
+}
+
+func ParseCurrency(currency string) (string, error) {
+	c := strings.ToUpper(strings.TrimSpace(currency))
+	if len(c) != 3 {
+		return "", fmt.Errorf("incorrect currency %q", currency)
+	}
+	for _, r := range c {
+		if r < 'A' || r > 'Z' {
+			return "", fmt.Errorf("incorrect currency %q", currency)
+		}
+	}
+	return c, nil
+}
@@ -0,0 +1,10 @@
+package fx
+
+import (
+	"context"
+)
+
+type RateRepository interface {
+	AddRates(ctx context.Context, rates []*Rate) error
+	GetRates(ctx context.Context) (*Rates, error)
+}
@@ -0,0 +1,64 @@
+package fx
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type pair struct {
+	base  string
+	quote string
+}
+
+type Rates struct {
+	series map[pair][]*Rate
+}
+
+func NewRates(rates []*Rate) *Rates {
+	r := &Rates{series: map[pair][]*Rate{}}
+	for _, rate := range rates {
+		p := pair{base: rate.base, quote: rate.quote}
+		r.series[p] = append(r.series[p], rate)
+	}
+	for _, s := range r.series {
+		sort.Slice(s, func(i, j int) bool { return s[i].date.Before(s[j].date) })
+	}
+	return r
+}
+
+func (r *Rates) At(from, to string, date time.Time) (decimal.Decimal, error) {
+	if from == to {
+		return decimal.NewFromInt(1), nil
+	}
+	if r != nil {
+		if rate := last(r.series[pair{base: from, quote: to}], date); rate != nil {
+			return rate.rate, nil
+		}
+		if rate := last(r.series[pair{base: to, quote: from}], date); rate != nil {
+			return decimal.NewFromInt(1).Div(rate.rate), nil
+		}
+	}
+	return decimal.Zero, fmt.Errorf("can't convert %s to %s on %s: %w", from, to, date.Format("2006-01-02"), ErrRateNotFound)
+}
+
+func (r *Rates) Convert(amount decimal.Decimal, from, to string, date time.Time) (decimal.Decimal, error) {
+	if amount.IsZero() {
+		return decimal.Zero, nil
+	}
+	rate, err := r.At(from, to, date)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return amount.Mul(rate), nil
+}
+
+func last(s []*Rate, date time.Time) *Rate {
+	i := sort.Search(len(s), func(i int) bool { return s[i].date.After(date) })
+	if i == 0 {
+		return nil
+	}
+	return s[i-1]
+}
@@ -0,0 +1,301 @@
+package instrument
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/invine/portfolio/internal/domain/fx"
+	"github.com/invine/portfolio/internal/domain/portfolio"
+)
+
+var (
+	ErrInstrumentNotFound = errors.New("instrument not found")
+	// ErrCodeTaken is returned when a symbol, an ISIN or an alias already identifies another instrument.
+	ErrCodeTaken = errors.New("code is taken by another instrument")
+)
+
+type AssetClass string
+
+const (
+	Stock     AssetClass = "stock"
+	ETF       AssetClass = "etf"
+	Fund      AssetClass = "fund"
+	Bond      AssetClass = "bond"
+	Crypto    AssetClass = "crypto"
+	Commodity AssetClass = "commodity"
+	Cash      AssetClass = "cash"
+	Option    AssetClass = "option"
+	Future    AssetClass = "future"
+	Other     AssetClass = "other"
+)
+
+func ParseAssetClass(s string) (AssetClass, error) {
+	c := AssetClass(s)
+	switch c {
+	case Stock, ETF, Fund, Bond, Crypto, Commodity, Cash, Option, Future, Other:
+		return c, nil
+	}
+	return "", fmt.Errorf("unknown asset class %q", s)
+}
+
+type Details struct {
+	ISIN      string
+	Exchange  string
+	Currency  string
+	Sector    string
+	Country   string
+	Precision int32
+	Aliases   []string
+	Tags      map[string]string
+	Contract  portfolio.Contract
+	Bond      portfolio.Bond
+}
+
+type Instrument struct {
+	id      uuid.UUID
+	symbol  string
+	name    string
+	class   AssetClass
+	details Details
+}
+
+func NewInstrument(id uuid.UUID, symbol, name string, class AssetClass, details Details) (*Instrument, error) {
+	if id == uuid.Nil {
+		return nil, fmt.Errorf("can't create instrument: id can't be empty")
+	}
+	i := &Instrument{id: id}
+	if err := i.Update(symbol, name, class, details); err != nil {
+		return nil, fmt.Errorf("can't create instrument: %w", err)
+	}
+	return i, nil
+}
+
+func (i *Instrument) Update(symbol, name string, class AssetClass, details Details) error {
+	symbol = NormalizeCode(symbol)
+	if symbol == "" {
+		return fmt.Errorf("symbol can't be empty")
+	}
+	if class == "" {
+		class = Other
+	}
+	if _, err := ParseAssetClass(string(class)); err != nil {
+		return err
+	}
+
+	d := Details{
+		Exchange:  strings.ToUpper(strings.TrimSpace(details.Exchange)),
+		Sector:    strings.TrimSpace(details.Sector),
+		Precision: details.Precision,
+	}
+	if details.ISIN != "" {
+		isin, err := ParseISIN(details.ISIN)
+		if err != nil {
+			return err
+		}
+		d.ISIN = isin
+	}
+	if details.Currency != "" {
+		currency, err := fx.ParseCurrency(details.Currency)
+		if err != nil {
+			return err
+		}
+		d.Currency = currency
+	}
+	if details.Country != "" {
+		country, err := parseCountry(details.Country)
+		if err != nil {
+			return err
+		}
+		d.Country = country
+	}
+	if err := portfolio.ValidatePrecision(details.Precision); err != nil {
+		return err
+	}
+	if class == Option || class == Future {
+		c := details.Contract
+		c.Underlying = NormalizeCode(c.Underlying)
+		if err := c.Validate(); err != nil {
+			return err
+		}
+		if c.IsOption() != (class == Option) {
+			return fmt.Errorf("%s must have a contract of an option with a right and a strike or of a future without them", class)
+		}
+		if c.Underlying == symbol {
+			return fmt.Errorf("%s can't be its own underlying", symbol)
+		}
+		d.Contract = c
+	} else if !details.Contract.IsZero() {
+		return fmt.Errorf("only an option or a future can have a contract")
+	}
+	if !details.Bond.IsZero() {
+		b := details.Bond
+		if class != Bond {
+			return fmt.Errorf("only a bond can have bond terms")
+		}
+		if b.DayCount == "" {
+			b.DayCount = portfolio.ActualActual
+		}
+		if err := b.Validate(); err != nil {
+			return err
+		}
+		d.Bond = b
+	}
+	d.Aliases = []string{}
+	seen := map[string]bool{symbol: true, d.ISIN: true}
+	for _, a := range details.Aliases {
+		a = NormalizeCode(a)
+		if a == "" || seen[a] {
+			continue
+		}
+		seen[a] = true
+		d.Aliases = append(d.Aliases, a)
+	}
+	d.Tags = map[string]string{}
+	for name, value := range details.Tags {
+		name, value = NormalizeTag(name), strings.TrimSpace(value)
+		if name == "" {
+			return fmt.Errorf("tag name can't be empty")
+		}
+		if value == "" {
+			continue
+		}
+		d.Tags[name] = value
+	}
+
+	i.symbol = symbol
+	i.name = strings.TrimSpace(name)
+	i.class = class
+	i.details = d
+	return nil
+}
+
+func (i *Instrument) Codes() []string {
+	codes := []string{i.symbol}
+	if i.details.ISIN != "" {
+		codes = append(codes, i.details.ISIN)
+	}
+	return append(codes, i.details.Aliases...)
+}
+
+func (i *Instrument) ID() uuid.UUID {
+	return i.id
+}
+
+func (i *Instrument) Symbol() string {
+	return i.symbol
+}
+
+func (i *Instrument) Name() string {
+	return i.name
+}
+
+func (i *Instrument) Class() AssetClass {
+	return i.class
+}
+
+func (i *Instrument) Details() Details {
+	d := i.details
+	d.Aliases = append([]string{}, i.details.Aliases...)
+	d.Tags = map[string]string{}
+	for name, value := range i.details.Tags {
+		d.Tags[name] = value
+	}
+	return d
+}
+
+func (i *Instrument) Currency() string {
+	return i.details.Currency
+}
+
+func (i *Instrument) Contract() portfolio.Contract {
+	return i.details.Contract
+}
+
+func (i *Instrument) Bond() portfolio.Bond {
+	return i.details.Bond
+}
+
+func (i *Instrument) IsDerivative() bool {
+	return i.class == Option || i.class == Future
+}
+
+func (i *Instrument) Link(t *portfolio.Transaction) error {
+	if err := t.SetInstrument(i.id, i.symbol); err != nil {
+		return err
+	}
+	if t.Currency() == "" && i.Currency() != "" {
+		if err := t.SetCurrency(i.Currency()); err != nil {
+			return err
+		}
+	}
+	if i.IsDerivative() && t.Kind().IsTrade() {
+		if err := t.SetMultiplier(i.Contract().Multiplier); err != nil {
+			return err
+		}
+	}
+	if b := i.Bond(); !b.IsZero() && t.Kind().IsTrade() {
+		if err := t.SetMultiplier(b.Multiplier()); err != nil {
+			return err
+		}
+		// accrued interest is settled in cash, so it's rounded to cents
+		if t.Accrued().IsZero() {
+			if err := t.SetAccrued(b.Accrued(t.Date()).Mul(t.Quantity().Abs()).Round(2)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func NormalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func NormalizeTag(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+func ParseISIN(isin string) (string, error) {
+	s := NormalizeCode(isin)
+	if len(s) != 12 {
+		return "", fmt.Errorf("incorrect ISIN %q: it must have 12 characters", isin)
+	}
+	digits := make([]int, 0, 24)
+	for n, r := range s {
+		switch {
+		case r >= '0' && r <= '9' && n >= 2:
+			digits = append(digits, int(r-'0'))
+		case r >= 'A' && r <= 'Z' && n < 11:
+			v := int(r-'A') + 10
+			digits = append(digits, v/10, v%10)
+		default:
+			return "", fmt.Errorf("incorrect ISIN %q", isin)
+		}
+	}
+	// Luhn algorithm over the digits of the letters and numbers, including the check digit
+	sum := 0
+	for n := range digits {
+		d := digits[len(digits)-1-n]
+		if n%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	if sum%10 != 0 {
+		return "", fmt.Errorf("incorrect ISIN %q: wrong check digit", isin)
+	}
+	return s, nil
+}
+
+func parseCountry(country string) (string, error) {
+	c := strings.ToUpper(strings.TrimSpace(country))
+	if len(c) != 2 || c[0] < 'A' || c[0] > 'Z' || c[1] < 'A' || c[1] > 'Z' {
+		return "", fmt.Errorf("incorrect country %q", country)
+	}
+	return c, nil
+}
@@ -0,0 +1,17 @@
+package instrument
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type InstrumentRepository interface {
+	AddInstrument(ctx context.Context, i *Instrument) error
+	GetInstrument(ctx context.Context, id uuid.UUID) (*Instrument, error)
+	GetInstruments(ctx context.Context) ([]*Instrument, error)
+	// FindInstrument returns the instrument with id, symbol, ISIN or alias code, ignoring case.
+	FindInstrument(ctx context.Context, code string) (*Instrument, error)
+	UpdateInstrument(ctx context.Context, id uuid.UUID, updateFn func(i *Instrument) error) error
+	DeleteInstrument(ctx context.Context, id uuid.UUID) error
+}
@@ -0,0 +1,205 @@
+package portfolio
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/invine/portfolio/internal/domain/fx"
+	"github.com/shopspring/decimal"
+)
+
+type AllocationKey string
+
+const (
+	ByClass    AllocationKey = "class"
+	BySector   AllocationKey = "sector"
+	ByCountry  AllocationKey = "country"
+	ByCurrency AllocationKey = "currency"
+	ByAsset    AllocationKey = "asset"
+
+	tagKeyPrefix = "tag:"
+)
+
+const (
+	// Unclassified is the group of holdings that don't have the attribute they're grouped by.
+	Unclassified = "unclassified"
+	// CashClass is the asset class of cash balances.
+	CashClass = "cash"
+)
+
+func ParseAllocationKey(s string) (AllocationKey, error) {
+	k := AllocationKey(strings.ToLower(strings.TrimSpace(s)))
+	switch k {
+	case ByClass, BySector, ByCountry, ByCurrency, ByAsset:
+		return k, nil
+	}
+	if name := strings.TrimPrefix(string(k), tagKeyPrefix); name != string(k) && strings.TrimSpace(name) != "" {
+		return ByTag(name), nil
+	}
+	return "", fmt.Errorf("unknown allocation key %q", s)
+}
+
+func ByTag(name string) AllocationKey {
+	return AllocationKey(tagKeyPrefix + strings.ToLower(strings.TrimSpace(name)))
+}
+
+type Classification struct {
+	Class    string
+	Sector   string
+	Country  string
+	Currency string
+	Tags     map[string]string
+}
+
+type AllocationGroup struct {
+	Key    AllocationKey
+	Name   string
+	Value  decimal.Decimal
+	Weight float64
+	Share  float64
+	Groups []*AllocationGroup
+}
+
+type Allocation struct {
+	ID       uuid.UUID
+	Name     string
+	Date     time.Time
+	Currency string
+	By       []AllocationKey
+	Value    decimal.Decimal
+	Groups   []*AllocationGroup
+}
+
+type holding struct {
+	asset    string
+	currency string
+	class    Classification
+	value    decimal.Decimal
+	cash     bool
+}
+
+func (h holding) attribute(key AllocationKey) string {
+	var v string
+	switch key {
+	case ByClass:
+		v = h.class.Class
+	case BySector:
+		v = h.class.Sector
+	case ByCountry:
+		v = h.class.Country
+	case ByCurrency:
+		v = h.currency
+	case ByAsset:
+		v = h.asset
+	default:
+		v = h.class.Tags[strings.TrimPrefix(string(key), tagKeyPrefix)]
+	}
+	if v == "" {
+		return Unclassified
+	}
+	return v
+}
+
+func (s *Snapshot) Allocation(by []AllocationKey, classes map[string]Classification, rates *fx.Rates) (*Allocation, error) {
+	if len(by) == 0 {
+		return nil, fmt.Errorf("can't allocate portfolio: no keys to group by")
+	}
+	seen := map[AllocationKey]bool{}
+	for _, k := range by {
+		if seen[k] {
+			return nil, fmt.Errorf("can't allocate portfolio: %s is used twice", k)
+		}
+		seen[k] = true
+	}
+
+	holdings, err := s.holdings(classes, rates)
+	if err != nil {
+		return nil, fmt.Errorf("can't allocate portfolio: %w", err)
+	}
+
+	a := &Allocation{
+		ID:       s.ID,
+		Name:     s.Name,
+		Date:     s.Date,
+		Currency: s.Currency,
+		By:       by,
+		Value:    decimal.Zero,
+	}
+	for _, h := range holdings {
+		a.Value = a.Value.Add(h.value)
+	}
+	a.Groups = allocate(holdings, by, a.Value, a.Value)
+	return a, nil
+}
+
+func (s *Snapshot) holdings(classes map[string]Classification, rates *fx.Rates) ([]holding, error) {
+	holdings := []holding{}
+	for asset, pos := range s.Positions {
+		rate, err := rates.At(pos.Currency, s.Currency, s.Date)
+		if err != nil {
+			return nil, fmt.Errorf("can't value %s: %w", asset, err)
+		}
+		holdings = append(holdings, holding{
+			asset:    asset,
+			currency: pos.Currency,
+			class:    classes[asset],
+			value:    pos.MarketValue.Mul(rate),
+		})
+	}
+	for currency, cash := range s.Cash {
+		if cash.IsZero() {
+			continue
+		}
+		value, err := rates.Convert(cash, currency, s.Currency, s.Date)
+		if err != nil {
+			return nil, fmt.Errorf("can't value cash: %w", err)
+		}
+		holdings = append(holdings, holding{
+			asset:    currency,
+			currency: currency,
+			class:    Classification{Class: CashClass},
+			value:    value,
+			cash:     true,
+		})
+	}
+	return holdings, nil
+}
+
+func allocate(holdings []holding, by []AllocationKey, parent, total decimal.Decimal) []*AllocationGroup {
+	if len(by) == 0 {
+		return nil
+	}
+	key := by[0]
+	members := map[string][]holding{}
+	groups := []*AllocationGroup{}
+	for _, h := range holdings {
+		name := h.attribute(key)
+		if _, ok := members[name]; !ok {
+			groups = append(groups, &AllocationGroup{Key: key, Name: name, Value: decimal.Zero})
+		}
+		members[name] = append(members[name], h)
+	}
+
+	for _, g := range groups {
+		for _, h := range members[g.Name] {
+			g.Value = g.Value.Add(h.value)
+		}
+		if !total.IsZero() {
+			g.Weight = g.Value.Div(total).InexactFloat64()
+		}
+		if !parent.IsZero() {
+			g.Share = g.Value.Div(parent).InexactFloat64()
+		}
+		g.Groups = allocate(members[g.Name], by[1:], g.Value, total)
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		if c := groups[i].Value.Cmp(groups[j].Value); c != 0 {
+			return c > 0
+		}
+		return groups[i].Name < groups[j].Name
+	})
+	return groups
+}
@@ -0,0 +1,291 @@
+package portfolio
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+type DayCount string
+
+const (
+	// Thirty360 counts every month as 30 days and a year as 360 days (30/360 US).
+	Thirty360 DayCount = "30/360"
+	Actual360 DayCount = "act/360"
+	Actual365 DayCount = "act/365"
+	// ActualActual counts the actual days of the coupon period (act/act ICMA).
+	ActualActual DayCount = "act/act"
+)
+
+func ParseDayCount(s string) (DayCount, error) {
+	d := DayCount(s)
+	switch d {
+	case Thirty360, Actual360, Actual365, ActualActual:
+		return d, nil
+	}
+	return "", fmt.Errorf("unknown day count convention %q", s)
+}
+
+func (d DayCount) fraction(start, end, periodStart, periodEnd time.Time, frequency int) decimal.Decimal {
+	switch d {
+	case Thirty360:
+		y1, m1, d1 := start.Date()
+		y2, m2, d2 := end.Date()
+		if d1 == 31 {
+			d1 = 30
+		}
+		if d2 == 31 && d1 == 30 {
+			d2 = 30
+		}
+		days := 360*(y2-y1) + 30*(int(m2)-int(m1)) + d2 - d1
+		return decimal.NewFromInt(int64(days)).Div(decimal.NewFromInt(360))
+	case Actual360:
+		return decimal.NewFromInt(int64(daysBetween(start, end))).Div(decimal.NewFromInt(360))
+	case Actual365:
+		return decimal.NewFromInt(int64(daysBetween(start, end))).Div(decimal.NewFromInt(365))
+	}
+	year := int64(daysBetween(periodStart, periodEnd) * frequency)
+	return decimal.NewFromInt(int64(daysBetween(start, end))).Div(decimal.NewFromInt(year))
+}
+
+type Bond struct {
+	FaceValue  decimal.Decimal
+	CouponRate decimal.Decimal
+	Frequency  int
+	DayCount   DayCount
+	Issue      time.Time
+	Maturity   time.Time
+}
+
+func (b Bond) IsZero() bool {
+	return b.FaceValue.IsZero() && b.CouponRate.IsZero() && b.Frequency == 0 && b.DayCount == "" && b.Issue.IsZero() && b.Maturity.IsZero()
+}
+
+func (b Bond) Validate() error {
+	if !b.FaceValue.IsPositive() {
+		return fmt.Errorf("face value of a bond must be positive")
+	}
+	if b.CouponRate.IsNegative() {
+		return fmt.Errorf("coupon rate of a bond can't be negative")
+	}
+	switch b.Frequency {
+	case 0, 1, 2, 4, 12:
+	default:
+		return fmt.Errorf("a bond can pay 1, 2, 4 or 12 coupons a year, not %d", b.Frequency)
+	}
+	if b.CouponRate.IsPositive() != (b.Frequency > 0) {
+		return fmt.Errorf("a bond must have both a coupon rate and a frequency or neither of them")
+	}
+	if _, err := ParseDayCount(string(b.DayCount)); err != nil {
+		return err
+	}
+	if b.Issue.IsZero() || b.Maturity.IsZero() {
+		return fmt.Errorf("issue and maturity of a bond can't be empty")
+	}
+	if !Day.Start(b.Maturity).After(Day.Start(b.Issue)) {
+		return fmt.Errorf("maturity of a bond must be after its issue")
+	}
+	return nil
+}
+
+func (b Bond) Multiplier() decimal.Decimal {
+	return b.FaceValue.Div(decimal.NewFromInt(100))
+}
+
+func (b Bond) Coupon() decimal.Decimal {
+	if b.Frequency == 0 {
+		return decimal.Zero
+	}
+	return b.FaceValue.Mul(b.CouponRate).Div(decimal.NewFromInt(int64(b.Frequency)))
+}
+
+func (b Bond) CouponDates() []time.Time {
+	if b.Frequency == 0 {
+		return nil
+	}
+	issue, maturity := Day.Start(b.Issue), Day.Start(b.Maturity)
+	res := []time.Time{}
+	for n := 0; ; n++ {
+		d := addMonths(maturity, -n*12/b.Frequency)
+		if !d.After(issue) {
+			break
+		}
+		res = append(res, d)
+	}
+	for i, j := 0, len(res)-1; i < j; i, j = i+1, j-1 {
+		res[i], res[j] = res[j], res[i]
+	}
+	return res
+}
+
+func (b Bond) Accrued(date time.Time) decimal.Decimal {
+	day := Day.Start(date)
+	if b.Frequency == 0 || day.Before(Day.Start(b.Issue)) || !day.Before(Day.Start(b.Maturity)) {
+		return decimal.Zero
+	}
+	dates := b.CouponDates()
+	next := dates[sort.Search(len(dates), func(i int) bool { return dates[i].After(day) })]
+	periodStart := addMonths(next, -12/b.Frequency)
+	start := periodStart
+	if issue := Day.Start(b.Issue); issue.After(start) {
+		start = issue
+	}
+	fraction := b.DayCount.fraction(start, day, periodStart, next, b.Frequency)
+	return b.FaceValue.Mul(b.CouponRate).Mul(fraction)
+}
+
+func (b Bond) Yield(price decimal.Decimal, date time.Time) (float64, bool) {
+	day, maturity := Day.Start(date), Day.Start(b.Maturity)
+	if !maturity.After(day) || !price.IsPositive() {
+		return 0, false
+	}
+	type flow struct {
+		years  float64
+		amount float64
+	}
+	flows := []flow{}
+	coupon := b.Coupon().InexactFloat64()
+	for _, d := range b.CouponDates() {
+		if d.After(day) {
+			flows = append(flows, flow{years: float64(daysBetween(day, d)) / 365, amount: coupon})
+		}
+	}
+	flows = append(flows, flow{years: float64(daysBetween(day, maturity)) / 365, amount: b.FaceValue.InexactFloat64()})
+
+	periods := float64(b.Frequency)
+	if b.Frequency == 0 {
+		periods = 1
+	}
+	value := func(rate float64) float64 {
+		res := 0.0
+		for _, f := range flows {
+			res += f.amount / math.Pow(1+rate/periods, periods*f.years)
+		}
+		return res
+	}
+	// the value of the flows falls as the rate grows, so the rate is found by bisection
+	dirty := price.Add(b.Accrued(date)).InexactFloat64()
+	low, high := -0.99*periods, 10.0
+	if value(low) < dirty || value(high) > dirty {
+		return 0, false
+	}
+	for i := 0; i < 100; i++ {
+		mid := (low + high) / 2
+		if value(mid) > dirty {
+			low = mid
+		} else {
+			high = mid
+		}
+	}
+	return (low + high) / 2, true
+}
+
+type Bonds map[string]Bond
+
+func addMonths(t time.Time, months int) time.Time {
+	y, m, d := t.Date()
+	first := time.Date(y, m+time.Month(months), 1, 0, 0, 0, 0, t.Location())
+	if last := first.AddDate(0, 1, -1).Day(); d > last {
+		d = last
+	}
+	return time.Date(first.Year(), first.Month(), d, 0, 0, 0, 0, t.Location())
+}
+
+func daysBetween(from, to time.Time) int {
+	return int(math.Round(Day.Start(to).Sub(Day.Start(from)).Hours() / 24))
+}
+
+type bondPayment struct {
+	date  time.Time
+	kind  Kind
+	asset string
+	bond  Bond
+}
+
+func (p *Portfolio) withPayments(transactions []*Transaction) []*Transaction {
+	payments := p.payments(transactions)
+	if len(payments) == 0 {
+		return transactions
+	}
+	res := make([]*Transaction, 0, len(transactions)+len(payments))
+	return append(append(res, transactions...), payments...)
+}
+
+func (p *Portfolio) payments(transactions []*Transaction) []*Transaction {
+	if len(p.bonds) == 0 {
+		return nil
+	}
+	recorded := map[string]bool{}
+	for _, t := range transactions {
+		if t.kind == Coupon {
+			recorded[t.asset+Day.Start(t.date).Format("20060102")] = true
+		}
+	}
+	due := []bondPayment{}
+	for asset, b := range p.bonds {
+		for _, d := range b.CouponDates() {
+			if !recorded[asset+d.Format("20060102")] {
+				due = append(due, bondPayment{date: d, kind: Coupon, asset: asset, bond: b})
+			}
+		}
+		due = append(due, bondPayment{date: Day.Start(b.Maturity), kind: Redemption, asset: asset, bond: b})
+	}
+	// a coupon due on maturity is paid before the redemption
+	sort.Slice(due, func(i, j int) bool {
+		if !due[i].date.Equal(due[j].date) {
+			return due[i].date.Before(due[j].date)
+		}
+		if due[i].asset != due[j].asset {
+			return due[i].asset < due[j].asset
+		}
+		return due[i].kind < due[j].kind
+	})
+
+	book := p.newBook()
+	events := mergeEvents(p.allActions(), transactions)
+	res := []*Transaction{}
+	i := 0
+	for _, d := range due {
+		for ; i < len(events) && Day.Start(events[i].date).Before(d.date); i++ {
+			book.applyEvent(events[i])
+		}
+		held := book.held(d.asset)
+		if !held.IsPositive() {
+			continue
+		}
+		t, err := d.transaction(p.id, held, book.multiplierOf(d.asset), book.currencyOf(d.asset))
+		if err != nil {
+			continue
+		}
+		book.apply(t)
+		res = append(res, t)
+	}
+	return res
+}
+
+func (d bondPayment) transaction(portfolioID uuid.UUID, held, multiplier decimal.Decimal, currency string) (*Transaction, error) {
+	id := uuid.NewSHA1(portfolioID, []byte(string(d.kind)+"/"+d.asset+"/"+d.date.Format("20060102")))
+	var (
+		t   *Transaction
+		err error
+	)
+	if d.kind == Coupon {
+		t, err = NewIncomeTransaction(id, d.date, Coupon, d.asset, d.bond.Coupon().Mul(held))
+	} else {
+		t, err = NewRedemption(id, d.date, d.asset, held, d.bond.FaceValue.Div(multiplier))
+		if err == nil && !multiplier.Equal(decimal.NewFromInt(1)) {
+			err = t.SetMultiplier(multiplier)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := t.SetCurrency(currency); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
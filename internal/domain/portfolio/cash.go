@@ -0,0 +1,79 @@
+package portfolio
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+type CashEntry struct {
+	TransactionID uuid.UUID
+	Date          time.Time
+	Kind          Kind
+	Action        CorporateActionType
+	Asset         string
+	Amount        decimal.Decimal
+	Balance       decimal.Decimal
+}
+
+type CashLedger struct {
+	ID       uuid.UUID
+	Name     string
+	Currency string
+	From     time.Time
+	To       time.Time
+	Opening  decimal.Decimal
+	Closing  decimal.Decimal
+	Entries  []CashEntry
+}
+
+func (p *Portfolio) CashLedger(from, to time.Time, currency string) (*CashLedger, error) {
+	from, to = Day.Start(from), Day.Start(to)
+	if currency == "" {
+		currency = p.currency
+	}
+	l := &CashLedger{
+		ID:       p.id,
+		Name:     p.name,
+		Currency: currency,
+		From:     from,
+		To:       to,
+		Entries:  []CashEntry{},
+	}
+	book := p.newBook()
+	for _, e := range p.events() {
+		day := Day.Start(e.date)
+		if day.After(to) {
+			break
+		}
+		c, cash, err := book.applyEvent(e)
+		if err != nil {
+			return nil, fmt.Errorf("can't list cash entries: %w", eventError(e, err))
+		}
+		if c != currency || e.washSale != nil {
+			continue
+		}
+		if day.Before(from) {
+			l.Opening = book.cash[currency]
+			continue
+		}
+		entry := CashEntry{Date: e.date, Amount: cash, Balance: book.cash[currency]}
+		if e.action != nil {
+			if cash.IsZero() {
+				continue
+			}
+			entry.TransactionID = e.action.id
+			entry.Action = e.action.kind
+			entry.Asset = e.action.asset
+		} else {
+			entry.TransactionID = e.transaction.id
+			entry.Kind = e.transaction.kind
+			entry.Asset = e.transaction.asset
+		}
+		l.Entries = append(l.Entries, entry)
+	}
+	l.Closing = book.cash[currency]
+	return l, nil
+}
@@ -0,0 +1,178 @@
+package portfolio
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/invine/portfolio/internal/domain/fx"
+	"github.com/invine/portfolio/internal/domain/price"
+	"github.com/shopspring/decimal"
+)
+
+type PortfolioHolding struct {
+	PortfolioID uuid.UUID
+	Name        string
+	Quantity    decimal.Decimal
+	Cost        decimal.Decimal
+	MarketValue decimal.Decimal
+	Share       float64
+}
+
+type ConsolidatedPosition struct {
+	Asset       string
+	Quantity    decimal.Decimal
+	Cost        decimal.Decimal
+	MarketValue decimal.Decimal
+	DayChange   decimal.Decimal
+	Gain        decimal.Decimal
+	Weight      float64
+	Holdings    []PortfolioHolding
+}
+
+type PortfolioSummary struct {
+	ID          uuid.UUID
+	Name        string
+	Balance     decimal.Decimal
+	MarketValue decimal.Decimal
+	DayChange   decimal.Decimal
+	Value       decimal.Decimal
+	Weight      float64
+}
+
+type Consolidated struct {
+	Date        time.Time
+	Currency    string
+	Portfolios  []PortfolioSummary
+	Positions   []*ConsolidatedPosition
+	Cash        map[string]decimal.Decimal
+	Balance     decimal.Decimal
+	MarketValue decimal.Decimal
+	DayChange   decimal.Decimal
+	Value       decimal.Decimal
+	Performance *Performance
+}
+
+func Consolidate(snapshots []*Snapshot, rates *fx.Rates) (*Consolidated, error) {
+	if len(snapshots) == 0 {
+		return nil, fmt.Errorf("can't consolidate portfolios: no portfolios")
+	}
+	c := &Consolidated{
+		Date:        snapshots[0].Date,
+		Currency:    snapshots[0].Currency,
+		Portfolios:  []PortfolioSummary{},
+		Positions:   []*ConsolidatedPosition{},
+		Cash:        map[string]decimal.Decimal{},
+		Balance:     decimal.Zero,
+		MarketValue: decimal.Zero,
+		DayChange:   decimal.Zero,
+	}
+
+	positions := map[string]*ConsolidatedPosition{}
+	for _, s := range snapshots {
+		if s.Currency != c.Currency || !s.Date.Equal(c.Date) {
+			return nil, fmt.Errorf("can't consolidate portfolios: %s isn't reported in %s on %s", s.Name, c.Currency, c.Date.Format("2006-01-02"))
+		}
+		c.Portfolios = append(c.Portfolios, PortfolioSummary{
+			ID:          s.ID,
+			Name:        s.Name,
+			Balance:     s.Balance,
+			MarketValue: s.MarketValue,
+			DayChange:   s.DayChange,
+			Value:       s.MarketValue.Add(s.Balance),
+		})
+		for currency, cash := range s.Cash {
+			c.Cash[currency] = c.Cash[currency].Add(cash)
+		}
+		c.Balance = c.Balance.Add(s.Balance)
+		c.MarketValue = c.MarketValue.Add(s.MarketValue)
+		c.DayChange = c.DayChange.Add(s.DayChange)
+
+		for asset, pos := range s.Positions {
+			rate, err := rates.At(pos.Currency, c.Currency, c.Date)
+			if err != nil {
+				return nil, fmt.Errorf("can't consolidate %s: %w", asset, err)
+			}
+			cost := decimal.Zero
+			for _, l := range pos.Lots {
+				lotCost, err := rates.Convert(l.Cost(), pos.Currency, c.Currency, l.Date)
+				if err != nil {
+					return nil, fmt.Errorf("can't consolidate cost of %s: %w", asset, err)
+				}
+				cost = cost.Add(lotCost)
+			}
+			cp, ok := positions[asset]
+			if !ok {
+				cp = &ConsolidatedPosition{Asset: asset, Holdings: []PortfolioHolding{}}
+				positions[asset] = cp
+				c.Positions = append(c.Positions, cp)
+			}
+			value := pos.MarketValue.Mul(rate)
+			cp.Quantity = cp.Quantity.Add(pos.Quantity)
+			cp.Cost = cp.Cost.Add(cost)
+			cp.MarketValue = cp.MarketValue.Add(value)
+			cp.DayChange = cp.DayChange.Add(pos.DayChange.Mul(rate))
+			cp.Holdings = append(cp.Holdings, PortfolioHolding{
+				PortfolioID: s.ID,
+				Name:        s.Name,
+				Quantity:    pos.Quantity,
+				Cost:        cost,
+				MarketValue: value,
+			})
+		}
+	}
+	c.Value = c.MarketValue.Add(c.Balance)
+
+	for i := range c.Portfolios {
+		if c.Value.IsPositive() {
+			c.Portfolios[i].Weight = c.Portfolios[i].Value.Div(c.Value).InexactFloat64()
+		}
+	}
+	for _, cp := range c.Positions {
+		cp.Gain = cp.MarketValue.Sub(cp.Cost)
+		if !c.MarketValue.IsZero() {
+			cp.Weight = cp.MarketValue.Div(c.MarketValue).InexactFloat64()
+		}
+		for i := range cp.Holdings {
+			if !cp.Quantity.IsZero() {
+				cp.Holdings[i].Share = cp.Holdings[i].Quantity.Div(cp.Quantity).InexactFloat64()
+			}
+		}
+	}
+	sort.SliceStable(c.Positions, func(i, j int) bool {
+		if cmp := c.Positions[i].MarketValue.Cmp(c.Positions[j].MarketValue); cmp != 0 {
+			return cmp > 0
+		}
+		return c.Positions[i].Asset < c.Positions[j].Asset
+	})
+	return c, nil
+}
+
+func ConsolidatedPerformance(portfolios []*Portfolio, from, to time.Time, prices map[string]price.Series, rates *fx.Rates) (*Performance, error) {
+	from, to = Day.Start(from), Day.Start(to)
+	if !to.After(from) {
+		return nil, fmt.Errorf("can't calculate performance: period must be at least one day")
+	}
+	if len(portfolios) == 0 {
+		return nil, fmt.Errorf("can't calculate performance: no portfolios")
+	}
+
+	series := &valueSeries{}
+	for _, p := range portfolios {
+		if p.currency != portfolios[0].currency {
+			return nil, fmt.Errorf("can't calculate performance: %s isn't reported in %s", p.name, portfolios[0].currency)
+		}
+		s, err := p.valueSeries(from, to, prices, rates)
+		if err != nil {
+			return nil, fmt.Errorf("can't calculate performance of %s: %w", p.name, err)
+		}
+		series.add(s)
+	}
+	perf, err := series.performance(from, to)
+	if err != nil {
+		return nil, err
+	}
+	perf.Currency = portfolios[0].currency
+	return perf, nil
+}
@@ -0,0 +1,67 @@
+package portfolio
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type OptionRight string
+
+const (
+	Call OptionRight = "call"
+	Put  OptionRight = "put"
+)
+
+func ParseOptionRight(s string) (OptionRight, error) {
+	r := OptionRight(s)
+	switch r {
+	case Call, Put:
+		return r, nil
+	}
+	return "", fmt.Errorf("unknown option right %q", s)
+}
+
+type Contract struct {
+	Underlying string
+	Multiplier decimal.Decimal
+	Right      OptionRight
+	Strike     decimal.Decimal
+	Expiry     time.Time
+}
+
+func (c Contract) IsOption() bool {
+	return c.Right != ""
+}
+
+func (c Contract) IsZero() bool {
+	return c.Underlying == "" && c.Multiplier.IsZero() && c.Right == "" && c.Strike.IsZero() && c.Expiry.IsZero()
+}
+
+func (c Contract) Validate() error {
+	if c.Underlying == "" {
+		return fmt.Errorf("underlying of a contract can't be empty")
+	}
+	if !c.Multiplier.IsPositive() {
+		return fmt.Errorf("multiplier of a contract must be positive")
+	}
+	if c.Expiry.IsZero() {
+		return fmt.Errorf("expiry of a contract can't be empty")
+	}
+	if !c.IsOption() {
+		if !c.Strike.IsZero() {
+			return fmt.Errorf("a future can't have a strike")
+		}
+		return nil
+	}
+	if _, err := ParseOptionRight(string(c.Right)); err != nil {
+		return err
+	}
+	if !c.Strike.IsPositive() {
+		return fmt.Errorf("strike of an option must be positive")
+	}
+	return nil
+}
+
+type Contracts map[string]Contract
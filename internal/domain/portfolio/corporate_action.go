@@ -0,0 +1,251 @@
+package portfolio
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+type CorporateActionType string
+
+const (
+	Split        CorporateActionType = "split"
+	ReverseSplit CorporateActionType = "reverse_split"
+	SymbolChange CorporateActionType = "symbol_change"
+	Merger       CorporateActionType = "merger"
+	SpinOff      CorporateActionType = "spin_off"
+)
+
+func ParseCorporateActionType(s string) (CorporateActionType, error) {
+	t := CorporateActionType(s)
+	switch t {
+	case Split, ReverseSplit, SymbolChange, Merger, SpinOff:
+		return t, nil
+	}
+	return "", fmt.Errorf("unknown corporate action type %q", s)
+}
+
+type CorporateAction struct {
+	id       uuid.UUID
+	date     time.Time
+	kind     CorporateActionType
+	asset    string
+	newAsset string
+	from     int
+	to       int
+	// costAllocation is the share of the cost basis moved to the spun-off asset
+	costAllocation decimal.Decimal
+	cashPrice      decimal.Decimal
+}
+
+func NewSplit(id uuid.UUID, date time.Time, asset string, from, to int, cashPrice decimal.Decimal) (*CorporateAction, error) {
+	a := &CorporateAction{kind: Split}
+	if err := a.setCommon(id, date, asset, from, to, cashPrice); err != nil {
+		return nil, fmt.Errorf("can't create split: %w", err)
+	}
+	if from == to {
+		return nil, fmt.Errorf("can't create split: ratio can't be 1:1")
+	}
+	if to < from {
+		a.kind = ReverseSplit
+	}
+	return a, nil
+}
+
+func NewSymbolChange(id uuid.UUID, date time.Time, asset, newAsset string) (*CorporateAction, error) {
+	a := &CorporateAction{kind: SymbolChange}
+	if err := a.setCommon(id, date, asset, 1, 1, decimal.Zero); err != nil {
+		return nil, fmt.Errorf("can't create symbol change: %w", err)
+	}
+	if err := a.setNewAsset(newAsset); err != nil {
+		return nil, fmt.Errorf("can't create symbol change: %w", err)
+	}
+	return a, nil
+}
+
+func NewMerger(id uuid.UUID, date time.Time, asset, newAsset string, from, to int, cashPrice decimal.Decimal) (*CorporateAction, error) {
+	a := &CorporateAction{kind: Merger}
+	if err := a.setCommon(id, date, asset, from, to, cashPrice); err != nil {
+		return nil, fmt.Errorf("can't create merger: %w", err)
+	}
+	if err := a.setNewAsset(newAsset); err != nil {
+		return nil, fmt.Errorf("can't create merger: %w", err)
+	}
+	return a, nil
+}
+
+func NewSpinOff(id uuid.UUID, date time.Time, asset, newAsset string, from, to int, costAllocation, cashPrice decimal.Decimal) (*CorporateAction, error) {
+	a := &CorporateAction{kind: SpinOff}
+	if err := a.setCommon(id, date, asset, from, to, cashPrice); err != nil {
+		return nil, fmt.Errorf("can't create spin-off: %w", err)
+	}
+	if err := a.setNewAsset(newAsset); err != nil {
+		return nil, fmt.Errorf("can't create spin-off: %w", err)
+	}
+	if costAllocation.IsNegative() || costAllocation.GreaterThan(decimal.NewFromInt(1)) {
+		return nil, fmt.Errorf("can't create spin-off: cost allocation must be from 0 to 1")
+	}
+	a.costAllocation = costAllocation
+	return a, nil
+}
+
+func (a *CorporateAction) setCommon(id uuid.UUID, date time.Time, asset string, from, to int, cashPrice decimal.Decimal) error {
+	if id == uuid.Nil {
+		return fmt.Errorf("id can't be empty")
+	}
+	if date.IsZero() {
+		return fmt.Errorf("date can't be empty")
+	}
+	if asset == "" {
+		return fmt.Errorf("asset can't be empty")
+	}
+	if from <= 0 || to <= 0 {
+		return fmt.Errorf("ratio must be positive")
+	}
+	if cashPrice.IsNegative() {
+		return fmt.Errorf("cash price can't be negative")
+	}
+	a.id = id
+	a.date = Day.Start(date)
+	a.asset = asset
+	a.from = from
+	a.to = to
+	a.cashPrice = cashPrice
+	return nil
+}
+
+func (a *CorporateAction) setNewAsset(newAsset string) error {
+	if newAsset == "" {
+		return fmt.Errorf("new asset can't be empty")
+	}
+	if newAsset == a.asset {
+		return fmt.Errorf("new asset must differ from asset")
+	}
+	a.newAsset = newAsset
+	return nil
+}
+
+func (a *CorporateAction) ID() uuid.UUID {
+	return a.id
+}
+
+func (a *CorporateAction) Date() time.Time {
+	return a.date
+}
+
+func (a *CorporateAction) Type() CorporateActionType {
+	return a.kind
+}
+
+func (a *CorporateAction) Asset() string {
+	return a.asset
+}
+
+func (a *CorporateAction) NewAsset() string {
+	return a.newAsset
+}
+
+func (a *CorporateAction) Ratio() (from, to int) {
+	return a.from, a.to
+}
+
+func (a *CorporateAction) CostAllocation() decimal.Decimal {
+	return a.costAllocation
+}
+
+func (a *CorporateAction) CashPrice() decimal.Decimal {
+	return a.cashPrice
+}
+
+func (a *CorporateAction) target() string {
+	if a.newAsset != "" {
+		return a.newAsset
+	}
+	return a.asset
+}
+
+func (a *CorporateAction) units(quantity decimal.Decimal) decimal.Decimal {
+	return quantity.Mul(decimal.NewFromInt(int64(a.to))).Div(decimal.NewFromInt(int64(a.from)))
+}
+
+func (b *positionBook) applyAction(a *CorporateAction) decimal.Decimal {
+	// the resulting asset is held in the currency of asset
+	if len(b.lots[a.asset]) > 0 {
+		b.currencies[a.target()] = b.currencyOf(a.asset)
+	}
+	if a.kind == SpinOff {
+		return b.spinOff(a)
+	}
+
+	target := a.target()
+	cash := decimal.Zero
+	converted := []*Lot{}
+	for _, l := range b.lots[a.asset] {
+		units := a.units(l.Quantity)
+		unitCost := l.Cost().Div(units)
+		q := units.Truncate(b.precisions.Of(target))
+		cash = cash.Add(b.closeFraction(a, target, l, units.Sub(q), unitCost))
+		if !q.IsZero() {
+			converted = append(converted, &Lot{TransactionID: l.TransactionID, Date: l.Date, Quantity: q, Price: unitCost, Adjustment: l.Adjustment})
+		}
+	}
+	delete(b.lots, a.asset)
+	b.lots[target] = append(b.lots[target], converted...)
+	sortLots(b.lots[target])
+
+	if pr, ok := b.lastPrices[a.asset]; ok {
+		delete(b.lastPrices, a.asset)
+		b.lastPrices[target] = pr.Mul(decimal.NewFromInt(int64(a.from))).Div(decimal.NewFromInt(int64(a.to)))
+	}
+	return cash
+}
+
+func (b *positionBook) spinOff(a *CorporateAction) decimal.Decimal {
+	cash := decimal.Zero
+	spun := []*Lot{}
+	for _, l := range b.lots[a.asset] {
+		units := a.units(l.Quantity)
+		cost := l.Cost().Mul(a.costAllocation)
+		l.Price = l.Price.Sub(l.Price.Mul(a.costAllocation))
+		if units.IsZero() {
+			continue
+		}
+		unitCost := cost.Div(units)
+		q := units.Truncate(b.precisions.Of(a.newAsset))
+		cash = cash.Add(b.closeFraction(a, a.newAsset, l, units.Sub(q), unitCost))
+		if !q.IsZero() {
+			spun = append(spun, &Lot{TransactionID: l.TransactionID, Date: l.Date, Quantity: q, Price: unitCost})
+		}
+	}
+	b.lots[a.newAsset] = append(b.lots[a.newAsset], spun...)
+	sortLots(b.lots[a.newAsset])
+	return cash
+}
+
+func (b *positionBook) closeFraction(a *CorporateAction, asset string, l *Lot, fraction, unitCost decimal.Decimal) decimal.Decimal {
+	if fraction.IsZero() {
+		return decimal.Zero
+	}
+	proceeds := fraction.Mul(a.cashPrice)
+	b.realized = append(b.realized, RealizedGain{
+		Asset:         asset,
+		Currency:      b.currencyOf(asset),
+		TransactionID: a.id,
+		LotID:         l.TransactionID,
+		Acquired:      l.Date,
+		Sold:          a.date,
+		Quantity:      fraction,
+		Proceeds:      proceeds,
+		Cost:          fraction.Mul(unitCost),
+	})
+	return proceeds
+}
+
+func sortLots(lots []*Lot) {
+	sort.SliceStable(lots, func(i, j int) bool {
+		return lots[i].Date.Before(lots[j].Date)
+	})
+}
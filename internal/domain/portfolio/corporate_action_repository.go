@@ -0,0 +1,13 @@
+package portfolio
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type CorporateActionRepository interface {
+	AddCorporateAction(ctx context.Context, a *CorporateAction) error
+	GetCorporateActions(ctx context.Context) ([]*CorporateAction, error)
+	DeleteCorporateAction(ctx context.Context, id uuid.UUID) error
+}
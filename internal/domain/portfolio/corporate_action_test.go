@@ -0,0 +1,126 @@
+package portfolio
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func must(a *CorporateAction, err error) *CorporateAction {
+	if err != nil {
+		panic(err)
+	}
+	return a
+}
+
+func TestCorporateActions(t *testing.T) {
+	type holding struct {
+		quantity string
+		cost     string
+	}
+	tests := []struct {
+		name         string
+		transactions []*Transaction
+		actions      []*CorporateAction
+		want         map[string]holding
+		cash         string
+		realizedCost string
+		proceeds     string
+	}{
+		{
+			name:         "split",
+			transactions: []*Transaction{newTrade(1, "2021-01-04", "AAPL", "10", "100")},
+			actions:      []*CorporateAction{must(NewSplit(id(50), day("2021-02-01"), "AAPL", 1, 2, decimal.Zero))},
+			want:         map[string]holding{"AAPL": {quantity: "20", cost: "1000"}},
+			cash:         "-1000",
+			realizedCost: "0",
+			proceeds:     "0",
+		},
+		{
+			name:         "reverse split with cash in lieu",
+			transactions: []*Transaction{newTrade(1, "2021-01-04", "AAPL", "5", "100")},
+			actions:      []*CorporateAction{must(NewSplit(id(50), day("2021-02-01"), "AAPL", 2, 1, dec("210")))},
+			want:         map[string]holding{"AAPL": {quantity: "2", cost: "400"}},
+			cash:         "-395",
+			realizedCost: "100",
+			proceeds:     "105",
+		},
+		{
+			name:         "split before the purchase",
+			transactions: []*Transaction{newTrade(1, "2021-03-01", "AAPL", "10", "100")},
+			actions:      []*CorporateAction{must(NewSplit(id(50), day("2021-02-01"), "AAPL", 1, 2, decimal.Zero))},
+			want:         map[string]holding{"AAPL": {quantity: "10", cost: "1000"}},
+			cash:         "-1000",
+			realizedCost: "0",
+			proceeds:     "0",
+		},
+		{
+			name:         "symbol change",
+			transactions: []*Transaction{newTrade(1, "2021-01-04", "FB", "10", "100")},
+			actions:      []*CorporateAction{must(NewSymbolChange(id(50), day("2021-02-01"), "FB", "META"))},
+			want:         map[string]holding{"META": {quantity: "10", cost: "1000"}},
+			cash:         "-1000",
+			realizedCost: "0",
+			proceeds:     "0",
+		},
+		{
+			name:         "merger",
+			transactions: []*Transaction{newTrade(1, "2021-01-04", "XLNX", "10", "100")},
+			actions:      []*CorporateAction{must(NewMerger(id(50), day("2021-02-01"), "XLNX", "AMD", 2, 5, decimal.Zero))},
+			want:         map[string]holding{"AMD": {quantity: "25", cost: "1000"}},
+			cash:         "-1000",
+			realizedCost: "0",
+			proceeds:     "0",
+		},
+		{
+			name:         "spin-off",
+			transactions: []*Transaction{newTrade(1, "2021-01-04", "DHR", "10", "100")},
+			actions:      []*CorporateAction{must(NewSpinOff(id(50), day("2021-02-01"), "DHR", "VNT", 1, 1, dec("0.2"), decimal.Zero))},
+			want:         map[string]holding{"DHR": {quantity: "10", cost: "800"}, "VNT": {quantity: "10", cost: "200"}},
+			cash:         "-1000",
+			realizedCost: "0",
+			proceeds:     "0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newTestPortfolio(t, 1)
+			p.SetCorporateActions(tt.actions)
+			applyAll(t, p, tt.transactions...)
+
+			date := day("2021-12-31")
+			s, err := p.Snapshot(date)
+			if err != nil {
+				t.Fatalf("Snapshot() error = %v", err)
+			}
+			if len(s.Positions) != len(tt.want) {
+				t.Errorf("Snapshot() has %d positions, want %d", len(s.Positions), len(tt.want))
+			}
+			for asset, h := range tt.want {
+				pos, ok := s.Positions[asset]
+				if !ok {
+					t.Errorf("Snapshot() has no position in %s", asset)
+					continue
+				}
+				if !pos.Quantity.Equal(dec(h.quantity)) || !pos.TotalCost.Equal(dec(h.cost)) {
+					t.Errorf("position in %s = %s units costing %s, want %s units costing %s", asset, pos.Quantity, pos.TotalCost, h.quantity, h.cost)
+				}
+			}
+			if cash := s.Cash[DefaultCurrency]; !cash.Equal(dec(tt.cash)) {
+				t.Errorf("Snapshot() cash = %s, want %s", cash, tt.cash)
+			}
+
+			gains, err := p.RealizedGains(date)
+			if err != nil {
+				t.Fatalf("RealizedGains() error = %v", err)
+			}
+			cost, proceeds := decimal.Zero, decimal.Zero
+			for _, g := range gains {
+				cost, proceeds = cost.Add(g.Cost), proceeds.Add(g.Proceeds)
+			}
+			if !cost.Equal(dec(tt.realizedCost)) || !proceeds.Equal(dec(tt.proceeds)) {
+				t.Errorf("realized cost %s and proceeds %s, want %s and %s", cost, proceeds, tt.realizedCost, tt.proceeds)
+			}
+		})
+	}
+}
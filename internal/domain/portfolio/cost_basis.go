@@ -0,0 +1,406 @@
+package portfolio
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+type CostBasisMethod string
+
+const (
+	FIFO        CostBasisMethod = "fifo"
+	LIFO        CostBasisMethod = "lifo"
+	AverageCost CostBasisMethod = "average"
+	SpecificLot CostBasisMethod = "specific"
+)
+
+func ParseCostBasisMethod(s string) (CostBasisMethod, error) {
+	m := CostBasisMethod(s)
+	switch m {
+	case FIFO, LIFO, AverageCost, SpecificLot:
+		return m, nil
+	}
+	return "", fmt.Errorf("unknown cost basis method %q", s)
+}
+
+type Lot struct {
+	TransactionID uuid.UUID
+	Date          time.Time
+	Quantity      decimal.Decimal
+	Price         decimal.Decimal
+	Adjustment    decimal.Decimal
+}
+
+func (l Lot) Cost() decimal.Decimal {
+	return l.Price.Mul(l.Quantity)
+}
+
+func (l *Lot) split(quantity decimal.Decimal) Lot {
+	res := Lot{TransactionID: l.TransactionID, Date: l.Date, Quantity: quantity, Price: l.Price}
+	if !l.Adjustment.IsZero() {
+		res.Adjustment = l.Adjustment.Mul(quantity).Div(l.Quantity)
+		l.Adjustment = l.Adjustment.Sub(res.Adjustment)
+	}
+	l.Quantity = l.Quantity.Sub(quantity)
+	return res
+}
+
+type LotSelection struct {
+	TransactionID uuid.UUID
+	Quantity      decimal.Decimal
+}
+
+type Position struct {
+	Asset       string
+	Currency    string
+	Quantity    decimal.Decimal
+	Multiplier  decimal.Decimal
+	Contract    *Contract
+	TotalCost   decimal.Decimal
+	AverageCost decimal.Decimal
+	// WashSaleAdjustment is the total of the adjustments of the lots
+	WashSaleAdjustment decimal.Decimal
+	Lots               []Lot
+	Price              decimal.Decimal
+	MarketValue        decimal.Decimal
+	DayChange          decimal.Decimal
+	Notional           decimal.Decimal
+	Bond               *Bond
+	Accrued            decimal.Decimal
+	Yield              *float64
+	Weight             float64
+}
+
+type positionBook struct {
+	method     CostBasisMethod
+	precisions Precisions
+	contracts  Contracts
+	bonds      Bonds
+	currency   string
+	// shortSelling lets a sell of more than is held open a short lot
+	shortSelling bool
+	// washSales are the wash sales of the sales recorded in the book
+	washSales   []WashSale
+	lots        map[string][]*Lot
+	realized    []RealizedGain
+	cash        map[string]decimal.Decimal
+	currencies  map[string]string
+	multipliers map[string]decimal.Decimal
+	lastPrices  map[string]decimal.Decimal
+}
+
+func newPositionBook(method CostBasisMethod, precisions Precisions, currency string) *positionBook {
+	return &positionBook{
+		method:      method,
+		precisions:  precisions,
+		currency:    currency,
+		lots:        map[string][]*Lot{},
+		realized:    []RealizedGain{},
+		cash:        map[string]decimal.Decimal{},
+		currencies:  map[string]string{},
+		multipliers: map[string]decimal.Decimal{},
+		lastPrices:  map[string]decimal.Decimal{},
+	}
+}
+
+func (b *positionBook) currencyOf(asset string) string {
+	if c, ok := b.currencies[asset]; ok {
+		return c
+	}
+	return b.currency
+}
+
+func (b *positionBook) multiplierOf(asset string) decimal.Decimal {
+	if m, ok := b.multipliers[asset]; ok {
+		return m
+	}
+	return decimal.NewFromInt(1)
+}
+
+func (b *positionBook) apply(t *Transaction) ([]Lot, error) {
+	currency := t.settledIn(b.currency)
+	if t.movesAsset() && len(b.lots[t.asset]) > 0 && b.currencyOf(t.asset) != currency {
+		return nil, fmt.Errorf("asset %s is held in %s, it can't be traded in %s", t.asset, b.currencyOf(t.asset), currency)
+	}
+	if t.movesAsset() && len(b.lots[t.asset]) > 0 && !b.multiplierOf(t.asset).Equal(t.Multiplier()) {
+		return nil, fmt.Errorf("asset %s is held with multiplier %s, it can't be traded with multiplier %s", t.asset, b.multiplierOf(t.asset), t.Multiplier())
+	}
+	b.cash[currency] = b.cash[currency].Add(t.cash())
+	if !t.movesAsset() {
+		return nil, nil
+	}
+	b.currencies[t.asset] = currency
+	b.multipliers[t.asset] = t.Multiplier()
+	// a transfer is accounted at the cost of its lots, which is the last price only for an asset that hasn't been traded yet
+	if _, ok := b.lastPrices[t.asset]; t.kind.IsTrade() || !ok {
+		b.lastPrices[t.asset] = t.price
+	}
+	switch t.kind {
+	case TransferIn:
+		for _, l := range t.received {
+			b.receiveLot(t.asset, l)
+		}
+		return nil, nil
+	case TransferOut:
+		if b.held(t.asset).IsNegative() {
+			return nil, fmt.Errorf("short position of %s can't be transferred", t.asset)
+		}
+		// lots transferred out keep their cost in the other portfolio, no gain is realized
+		if len(t.lots) > 0 {
+			return b.closeSelected(t)
+		}
+		return b.closeInOrder(t.asset, t.quantity.Neg())
+	case Exercise, Assignment:
+		if b.held(t.asset).Sign() != -t.quantity.Sign() {
+			return nil, fmt.Errorf("%s of %s contracts of %s doesn't match the position held", t.kind, t.quantity.Abs(), t.asset)
+		}
+		// the premium of the closed contracts goes to the trade that delivers the underlying, no gain is realized
+		return b.closeInOrder(t.asset, t.quantity.Abs())
+	}
+
+	// the price of a unit net of fees and of the premium of settled options, paid by a buy and received by a sell
+	unitPrice := t.price.Mul(t.Multiplier()).Add(t.fee().Add(t.premium).Div(t.quantity))
+	// a buy covers short lots and a sell closes long lots, what's left of the trade opens a new lot,
+	// which for a sell is a short lot and requires short selling
+	var (
+		closed  []Lot
+		err     error
+		opening = t.quantity
+	)
+	held := b.held(t.asset)
+	switch {
+	case t.kind == Redemption:
+		closed, err = b.closeInOrder(t.asset, t.quantity.Neg())
+		opening = decimal.Zero
+	case t.kind == Expiry:
+		// contracts expiring worthless realize their whole premium
+		if held.Sign() != -t.quantity.Sign() {
+			return nil, fmt.Errorf("%s of %s contracts of %s doesn't match the position held", t.kind, t.quantity.Abs(), t.asset)
+		}
+		closed, err = b.closeInOrder(t.asset, t.quantity.Abs())
+		opening = decimal.Zero
+	case len(t.lots) > 0 && b.method == SpecificLot:
+		closed, err = b.closeSelected(t)
+		opening = decimal.Zero
+	case t.kind == Sell && !b.shortSelling:
+		closed, err = b.closeInOrder(t.asset, t.quantity.Neg())
+		opening = decimal.Zero
+	case held.Sign() == -t.quantity.Sign():
+		quantity := decimal.Min(t.quantity.Abs(), held.Abs())
+		closed, err = b.closeInOrder(t.asset, quantity)
+		if t.kind == Buy {
+			opening = t.quantity.Sub(quantity)
+		} else {
+			opening = t.quantity.Add(quantity)
+		}
+	}
+	if !opening.IsZero() {
+		b.lots[t.asset] = append(b.lots[t.asset], &Lot{
+			TransactionID: t.id,
+			Date:          t.date,
+			Quantity:      opening,
+			Price:         unitPrice,
+		})
+	}
+	// fees of a trade change the proceeds of every closed lot in proportion to its quantity
+	for _, l := range closed {
+		b.realized = append(b.realized, RealizedGain{
+			Asset:         t.asset,
+			Currency:      currency,
+			TransactionID: t.id,
+			LotID:         l.TransactionID,
+			Acquired:      l.Date,
+			Sold:          t.date,
+			Quantity:      l.Quantity,
+			Proceeds:      unitPrice.Mul(l.Quantity),
+			Cost:          l.Cost(),
+			Disallowed:    b.disallowed(t.id, l.TransactionID),
+		})
+	}
+	return closed, err
+}
+
+func (b *positionBook) held(asset string) decimal.Decimal {
+	res := decimal.Zero
+	for _, l := range b.lots[asset] {
+		res = res.Add(l.Quantity)
+	}
+	return res
+}
+
+func (b *positionBook) receiveLot(asset string, l Lot) {
+	lots := b.lots[asset]
+	i := sort.Search(len(lots), func(i int) bool { return lots[i].Date.After(l.Date) })
+	lots = append(lots, nil)
+	copy(lots[i+1:], lots[i:])
+	lots[i] = &l
+	b.lots[asset] = lots
+}
+
+func (b *positionBook) closeInOrder(asset string, quantity decimal.Decimal) ([]Lot, error) {
+	lots := b.lots[asset]
+	if b.method == AverageCost {
+		averageLots(lots)
+	}
+
+	closed := []Lot{}
+	for quantity.IsPositive() && len(lots) > 0 {
+		i := 0
+		if b.method == LIFO {
+			i = len(lots) - 1
+		}
+		l := lots[i]
+		units := decimal.Min(l.Quantity.Abs(), quantity)
+		q := units
+		if l.Quantity.IsNegative() {
+			q = units.Neg()
+		}
+		closed = append(closed, l.split(q))
+		quantity = quantity.Sub(units)
+		if l.Quantity.IsZero() {
+			lots = append(lots[:i], lots[i+1:]...)
+		}
+	}
+	b.lots[asset] = lots
+
+	if quantity.IsPositive() {
+		return closed, fmt.Errorf("asset quantity can't be less than zero")
+	}
+	return closed, nil
+}
+
+func (b *positionBook) closeSelected(t *Transaction) ([]Lot, error) {
+	lots := b.lots[t.asset]
+	closed := []Lot{}
+	for _, s := range t.lots {
+		i := findLot(lots, s.TransactionID)
+		if i < 0 {
+			return closed, fmt.Errorf("lot %s of asset %s not found", s.TransactionID.String(), t.asset)
+		}
+		l := lots[i]
+		if l.Quantity.LessThan(s.Quantity) {
+			return closed, fmt.Errorf("lot %s has only %s units of asset %s", s.TransactionID.String(), l.Quantity, t.asset)
+		}
+		closed = append(closed, l.split(s.Quantity))
+		if l.Quantity.IsZero() {
+			lots = append(lots[:i], lots[i+1:]...)
+		}
+	}
+	b.lots[t.asset] = lots
+	return closed, nil
+}
+
+func (b *positionBook) applyEvent(e event) (string, decimal.Decimal, error) {
+	if e.washSale != nil {
+		b.adjust(e.washSale)
+		return b.currencyOf(e.washSale.Asset), decimal.Zero, nil
+	}
+	if e.action != nil {
+		currency := b.currencyOf(e.action.asset)
+		cash := b.applyAction(e.action)
+		b.cash[currency] = b.cash[currency].Add(cash)
+		return currency, cash, nil
+	}
+	_, err := b.apply(e.transaction)
+	return e.transaction.settledIn(b.currency), e.transaction.cash(), err
+}
+
+func (b *positionBook) positions() map[string]*Position {
+	res := map[string]*Position{}
+	for asset, lots := range b.lots {
+		if len(lots) == 0 {
+			continue
+		}
+		p := &Position{Asset: asset, Currency: b.currencyOf(asset), Multiplier: b.multiplierOf(asset), Lots: []Lot{}}
+		if c, ok := b.contracts[asset]; ok {
+			p.Contract = &c
+		}
+		if bond, ok := b.bonds[asset]; ok {
+			p.Bond = &bond
+		}
+		for _, l := range lots {
+			p.Quantity = p.Quantity.Add(l.Quantity)
+			p.TotalCost = p.TotalCost.Add(l.Cost())
+			p.WashSaleAdjustment = p.WashSaleAdjustment.Add(l.Adjustment)
+			p.Lots = append(p.Lots, *l)
+		}
+		if !p.Quantity.IsZero() {
+			p.AverageCost = p.TotalCost.Div(p.Quantity.Mul(p.Multiplier))
+		}
+		res[asset] = p
+	}
+	return res
+}
+
+func averageLots(lots []*Lot) {
+	quantity, cost := decimal.Zero, decimal.Zero
+	for _, l := range lots {
+		quantity = quantity.Add(l.Quantity)
+		cost = cost.Add(l.Cost())
+	}
+	if quantity.IsZero() {
+		return
+	}
+	for _, l := range lots {
+		l.Price = cost.Div(quantity)
+	}
+}
+
+func findLot(lots []*Lot, id uuid.UUID) int {
+	for i, l := range lots {
+		if l.TransactionID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+type event struct {
+	date        time.Time
+	transaction *Transaction
+	action      *CorporateAction
+	washSale    *WashSale
+}
+
+func (p *Portfolio) events() []event {
+	washSales, adjustments := p.WashSales(), []*WashSale{}
+	for i := range washSales {
+		if washSales[i].ReplacementPortfolioID == p.id {
+			adjustments = append(adjustments, &washSales[i])
+		}
+	}
+	return withAdjustments(mergeEvents(p.allActions(), p.withPayments(executed(p.transactions))), adjustments)
+}
+
+func mergeEvents(actions []*CorporateAction, transactions []*Transaction) []event {
+	res := []event{}
+	for _, a := range actions {
+		res = append(res, event{date: a.date, action: a})
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].date.Before(res[j].date)
+	})
+	transactions = sortedTransactions(transactions)
+	merged := make([]event, 0, len(res)+len(transactions))
+	i := 0
+	for _, t := range transactions {
+		for ; i < len(res) && !res[i].date.After(t.date); i++ {
+			merged = append(merged, res[i])
+		}
+		merged = append(merged, event{date: t.date, transaction: t})
+	}
+	return append(merged, res[i:]...)
+}
+
+func sortedTransactions(transactions []*Transaction) []*Transaction {
+	res := make([]*Transaction, len(transactions))
+	copy(res, transactions)
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].date.Before(res[j].date)
+	})
+	return res
+}
@@ -0,0 +1,300 @@
+package portfolio
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+func day(s string) time.Time {
+	d, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func dec(s string) decimal.Decimal {
+	return decimal.RequireFromString(s)
+}
+
+func id(n int) uuid.UUID {
+	return uuid.UUID{15: byte(n)}
+}
+
+func newTrade(n int, date, asset, quantity, price string) *Transaction {
+	t, err := NewTransaction(id(n), day(date), asset, dec(quantity), dec(price))
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func newCashFlow(n int, date string, kind Kind, amount string) *Transaction {
+	t, err := NewCashTransaction(id(n), day(date), kind, dec(amount))
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func withLots(t *Transaction, lots ...LotSelection) *Transaction {
+	if err := t.SelectLots(lots); err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func withMultiplier(t *Transaction, multiplier string) *Transaction {
+	if err := t.SetMultiplier(dec(multiplier)); err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func withFee(t *Transaction, amount string) *Transaction {
+	if err := t.SetFees([]Fee{{Type: Commission, Amount: dec(amount)}}); err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func newTestPortfolio(t *testing.T, n int) *Portfolio {
+	t.Helper()
+	p, err := NewPortfolio(id(100+n), id(200), "test", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func applyAll(t *testing.T, p *Portfolio, transactions ...*Transaction) {
+	t.Helper()
+	for _, tr := range transactions {
+		if err := p.ApplyTransaction(tr); err != nil {
+			t.Fatalf("ApplyTransaction() error = %v", err)
+		}
+	}
+}
+
+func TestCostBasis(t *testing.T) {
+	buys := func() []*Transaction {
+		return []*Transaction{
+			newTrade(1, "2021-01-04", "AAPL", "10", "100"),
+			newTrade(2, "2021-01-05", "AAPL", "10", "120"),
+		}
+	}
+	tests := []struct {
+		name         string
+		method       CostBasisMethod
+		shortSelling bool
+		transactions []*Transaction
+		quantity     string
+		cost         string
+		average      string
+		realizedCost string
+		proceeds     string
+	}{
+		{
+			name:         "fifo",
+			method:       FIFO,
+			transactions: append(buys(), newTrade(3, "2021-01-06", "AAPL", "-15", "130")),
+			quantity:     "5",
+			cost:         "600",
+			average:      "120",
+			realizedCost: "1600",
+			proceeds:     "1950",
+		},
+		{
+			name:         "lifo",
+			method:       LIFO,
+			transactions: append(buys(), newTrade(3, "2021-01-06", "AAPL", "-15", "130")),
+			quantity:     "5",
+			cost:         "500",
+			average:      "100",
+			realizedCost: "1700",
+			proceeds:     "1950",
+		},
+		{
+			name:         "average",
+			method:       AverageCost,
+			transactions: append(buys(), newTrade(3, "2021-01-06", "AAPL", "-15", "130")),
+			quantity:     "5",
+			cost:         "550",
+			average:      "110",
+			realizedCost: "1650",
+			proceeds:     "1950",
+		},
+		{
+			name:   "specific lot",
+			method: SpecificLot,
+			transactions: append(buys(), withLots(newTrade(3, "2021-01-06", "AAPL", "-15", "130"),
+				LotSelection{TransactionID: id(2), Quantity: dec("10")},
+				LotSelection{TransactionID: id(1), Quantity: dec("5")},
+			)),
+			quantity:     "5",
+			cost:         "500",
+			average:      "100",
+			realizedCost: "1700",
+			proceeds:     "1950",
+		},
+		{
+			name:         "specific lot without selection",
+			method:       SpecificLot,
+			transactions: append(buys(), newTrade(3, "2021-01-06", "AAPL", "-15", "130")),
+			quantity:     "5",
+			cost:         "600",
+			average:      "120",
+			realizedCost: "1600",
+			proceeds:     "1950",
+		},
+		{
+			name:         "fees",
+			method:       FIFO,
+			transactions: []*Transaction{withFee(newTrade(1, "2021-01-04", "AAPL", "10", "100"), "10"), withFee(newTrade(2, "2021-01-05", "AAPL", "-4", "110"), "4")},
+			quantity:     "6",
+			cost:         "606",
+			average:      "101",
+			realizedCost: "404",
+			proceeds:     "436",
+		},
+		{
+			name:         "short lot",
+			method:       FIFO,
+			shortSelling: true,
+			transactions: []*Transaction{newTrade(1, "2021-01-04", "TSLA", "-10", "50"), newTrade(2, "2021-01-05", "TSLA", "4", "40")},
+			quantity:     "-6",
+			cost:         "-300",
+			average:      "50",
+			realizedCost: "-200",
+			proceeds:     "-160",
+		},
+		{
+			name:         "buy covering a short lot opens a long one",
+			method:       FIFO,
+			shortSelling: true,
+			transactions: []*Transaction{newTrade(1, "2021-01-04", "TSLA", "-10", "50"), newTrade(2, "2021-01-05", "TSLA", "15", "40")},
+			quantity:     "5",
+			cost:         "200",
+			average:      "40",
+			realizedCost: "-500",
+			proceeds:     "-400",
+		},
+		{
+			name:   "multiplier",
+			method: FIFO,
+			transactions: []*Transaction{
+				withMultiplier(newTrade(1, "2021-01-04", "SPY210319C400", "2", "3"), "100"),
+				withMultiplier(newTrade(2, "2021-01-05", "SPY210319C400", "-1", "4"), "100"),
+			},
+			quantity:     "1",
+			cost:         "300",
+			average:      "3",
+			realizedCost: "300",
+			proceeds:     "400",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newTestPortfolio(t, 1)
+			if err := p.ChangeCostBasisMethod(tt.method); err != nil {
+				t.Fatal(err)
+			}
+			if err := p.ChangeShortSelling(tt.shortSelling); err != nil {
+				t.Fatal(err)
+			}
+			applyAll(t, p, tt.transactions...)
+
+			date := day("2021-12-31")
+			s, err := p.Snapshot(date)
+			if err != nil {
+				t.Fatalf("Snapshot() error = %v", err)
+			}
+			if len(s.Positions) != 1 {
+				t.Fatalf("Snapshot() has %d positions, want 1", len(s.Positions))
+			}
+			for _, pos := range s.Positions {
+				if !pos.Quantity.Equal(dec(tt.quantity)) || !pos.TotalCost.Equal(dec(tt.cost)) || !pos.AverageCost.Equal(dec(tt.average)) {
+					t.Errorf("position = %s units costing %s at %s, want %s units costing %s at %s",
+						pos.Quantity, pos.TotalCost, pos.AverageCost, tt.quantity, tt.cost, tt.average)
+				}
+			}
+
+			gains, err := p.RealizedGains(date)
+			if err != nil {
+				t.Fatalf("RealizedGains() error = %v", err)
+			}
+			cost, proceeds := decimal.Zero, decimal.Zero
+			for _, g := range gains {
+				cost, proceeds = cost.Add(g.Cost), proceeds.Add(g.Proceeds)
+			}
+			if !cost.Equal(dec(tt.realizedCost)) || !proceeds.Equal(dec(tt.proceeds)) {
+				t.Errorf("realized cost %s and proceeds %s, want %s and %s", cost, proceeds, tt.realizedCost, tt.proceeds)
+			}
+		})
+	}
+}
+
+func TestApplyTransactionReplaysLaterTransactions(t *testing.T) {
+	tests := []struct {
+		name         string
+		overdraft    bool
+		transactions []*Transaction
+		backdated    *Transaction
+		wantErr      string
+	}{
+		{
+			name:         "sell before a later sell of the whole position",
+			overdraft:    true,
+			transactions: []*Transaction{newTrade(1, "2021-01-04", "AAPL", "10", "100"), newTrade(2, "2021-01-08", "AAPL", "-10", "110")},
+			backdated:    newTrade(3, "2021-01-06", "AAPL", "-5", "105"),
+			wantErr:      "asset quantity can't be less than zero",
+		},
+		{
+			name:         "sell before the buy",
+			overdraft:    true,
+			transactions: []*Transaction{newTrade(1, "2021-01-04", "AAPL", "10", "100")},
+			backdated:    newTrade(2, "2021-01-01", "AAPL", "-5", "105"),
+			wantErr:      "asset quantity can't be less than zero",
+		},
+		{
+			name:         "withdrawal before a later buy",
+			transactions: []*Transaction{newCashFlow(1, "2021-01-04", Deposit, "1000"), newTrade(2, "2021-01-08", "AAPL", "10", "100")},
+			backdated:    newCashFlow(3, "2021-01-06", Withdrawal, "500"),
+			wantErr:      "cash balance can't be less than zero",
+		},
+		{
+			name:         "buy before a later sell",
+			overdraft:    true,
+			transactions: []*Transaction{newTrade(1, "2021-01-04", "AAPL", "10", "100"), newTrade(2, "2021-01-08", "AAPL", "-10", "110")},
+			backdated:    newTrade(3, "2021-01-06", "AAPL", "5", "105"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newTestPortfolio(t, 1)
+			if err := p.ChangeOverdraft(tt.overdraft); err != nil {
+				t.Fatal(err)
+			}
+			applyAll(t, p, tt.transactions...)
+
+			err := p.ApplyTransaction(tt.backdated)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatalf("ApplyTransaction() error = %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Fatalf("ApplyTransaction() error = %v, want %q", err, tt.wantErr)
+			}
+			want := len(tt.transactions)
+			if err == nil {
+				want++
+			}
+			if got := len(p.Transactions()); got != want {
+				t.Errorf("portfolio has %d transactions, want %d", got, want)
+			}
+		})
+	}
+}
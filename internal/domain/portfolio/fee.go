@@ -0,0 +1,132 @@
+package portfolio
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/invine/portfolio/internal/domain/fx"
+	"github.com/shopspring/decimal"
+)
+
+type FeeType string
+
+const (
+	Commission     FeeType = "commission"
+	ExchangeFee    FeeType = "exchange_fee"
+	StampDuty      FeeType = "stamp_duty"
+	TransactionTax FeeType = "transaction_tax"
+	WithholdingTax FeeType = "withholding_tax"
+	StockBorrowFee FeeType = "borrow_fee"
+	OtherFee       FeeType = "other"
+)
+
+func ParseFeeType(s string) (FeeType, error) {
+	t := FeeType(s)
+	switch t {
+	case Commission, ExchangeFee, StampDuty, TransactionTax, WithholdingTax, StockBorrowFee, OtherFee:
+		return t, nil
+	}
+	return "", fmt.Errorf("unknown fee type %q", s)
+}
+
+type Fee struct {
+	Type     FeeType
+	Amount   decimal.Decimal
+	Currency string
+}
+
+type FeeItem struct {
+	TransactionID uuid.UUID
+	Date          time.Time
+	Kind          Kind
+	Asset         string
+	Fee
+}
+
+type FeeTotal struct {
+	Total  decimal.Decimal
+	ByType map[FeeType]decimal.Decimal
+}
+
+func (t *FeeTotal) add(feeType FeeType, amount decimal.Decimal) {
+	if t.ByType == nil {
+		t.ByType = map[FeeType]decimal.Decimal{}
+	}
+	t.Total = t.Total.Add(amount)
+	t.ByType[feeType] = t.ByType[feeType].Add(amount)
+}
+
+type PeriodFees struct {
+	Period string
+	Start  time.Time
+	End    time.Time
+	FeeTotal
+}
+
+type FeesReport struct {
+	ID       uuid.UUID
+	Name     string
+	Currency string
+	From     time.Time
+	To       time.Time
+	FeeTotal
+	Periods []PeriodFees
+	Items   []FeeItem
+}
+
+func (p *Portfolio) Fees(from, to time.Time, period Period, rates *fx.Rates) (*FeesReport, error) {
+	from, to = Day.Start(from), Day.Start(to)
+	res := &FeesReport{
+		ID:       p.id,
+		Name:     p.name,
+		Currency: p.currency,
+		From:     from,
+		To:       to,
+		FeeTotal: FeeTotal{ByType: map[FeeType]decimal.Decimal{}},
+		Periods:  []PeriodFees{},
+		Items:    []FeeItem{},
+	}
+	periods := map[string]*PeriodFees{}
+	for _, t := range sortedTransactions(executed(p.transactions)) {
+		day := Day.Start(t.date)
+		if day.Before(from) || day.After(to) {
+			continue
+		}
+		fees := t.fees
+		switch t.kind {
+		case BorrowFee:
+			fees = append([]Fee{{Type: StockBorrowFee, Amount: t.amount}}, fees...)
+		case AccountFee:
+			fees = append([]Fee{{Type: OtherFee, Amount: t.amount}}, fees...)
+		}
+		for _, f := range fees {
+			if f.Currency == "" {
+				f.Currency = t.settledIn(p.currency)
+			}
+			amount, err := p.convert(rates, f.Amount, f.Currency, t.date)
+			if err != nil {
+				return nil, fmt.Errorf("can't convert fee: %w", err)
+			}
+			key := period.Key(t.date)
+			if _, ok := periods[key]; !ok {
+				periods[key] = &PeriodFees{Period: key, Start: period.Start(t.date), End: period.Next(t.date)}
+			}
+			periods[key].add(f.Type, amount)
+			res.add(f.Type, amount)
+			res.Items = append(res.Items, FeeItem{
+				TransactionID: t.id,
+				Date:          t.date,
+				Kind:          t.kind,
+				Asset:         t.asset,
+				Fee:           f,
+			})
+		}
+	}
+	for _, pf := range periods {
+		res.Periods = append(res.Periods, *pf)
+	}
+	sort.Slice(res.Periods, func(i, j int) bool { return res.Periods[i].Start.Before(res.Periods[j].Start) })
+	return res, nil
+}
@@ -0,0 +1,150 @@
+package portfolio
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/invine/portfolio/internal/domain/fx"
+	"github.com/shopspring/decimal"
+)
+
+type HoldingTerm string
+
+const (
+	ShortTerm HoldingTerm = "short"
+	LongTerm  HoldingTerm = "long"
+)
+
+type HoldingRule struct {
+	Months int
+}
+
+var DefaultHoldingRule = HoldingRule{Months: 12}
+
+func (r HoldingRule) Validate() error {
+	if r.Months < 0 {
+		return fmt.Errorf("holding period can't be negative")
+	}
+	return nil
+}
+
+func (r HoldingRule) Term(g RealizedGain) HoldingTerm {
+	if g.Quantity.IsNegative() {
+		return ShortTerm
+	}
+	if Day.Start(g.Sold).After(addMonths(Day.Start(g.Acquired), r.Months)) {
+		return LongTerm
+	}
+	return ShortTerm
+}
+
+type Disposal struct {
+	Asset         string
+	Currency      string
+	TransactionID uuid.UUID
+	LotID         uuid.UUID
+	Quantity      decimal.Decimal
+	Acquired      time.Time
+	Sold          time.Time
+	Proceeds      decimal.Decimal
+	Cost          decimal.Decimal
+	Adjustment    decimal.Decimal
+	Gain          decimal.Decimal
+	Term          HoldingTerm
+}
+
+type GainsTotal struct {
+	Proceeds   decimal.Decimal
+	Cost       decimal.Decimal
+	Adjustment decimal.Decimal
+	Gain       decimal.Decimal
+}
+
+func (gt *GainsTotal) add(d Disposal) {
+	gt.Proceeds = gt.Proceeds.Add(d.Proceeds)
+	gt.Cost = gt.Cost.Add(d.Cost)
+	gt.Adjustment = gt.Adjustment.Add(d.Adjustment)
+	gt.Gain = gt.Gain.Add(d.Gain)
+}
+
+type GainsReport struct {
+	ID        uuid.UUID
+	Name      string
+	Currency  string
+	From      time.Time
+	To        time.Time
+	Rule      HoldingRule
+	ShortTerm GainsTotal
+	LongTerm  GainsTotal
+	GainsTotal
+	Disposals []Disposal
+}
+
+func (p *Portfolio) Gains(from, to time.Time, rule HoldingRule, rates *fx.Rates) (*GainsReport, error) {
+	if err := rule.Validate(); err != nil {
+		return nil, fmt.Errorf("can't report gains: %w", err)
+	}
+	from, to = Day.Start(from), Day.Start(to)
+	res := &GainsReport{
+		ID:        p.id,
+		Name:      p.name,
+		Currency:  p.currency,
+		From:      from,
+		To:        to,
+		Rule:      rule,
+		Disposals: []Disposal{},
+	}
+
+	book, err := p.replay(to.AddDate(0, 0, 1).Add(-time.Nanosecond))
+	if err != nil {
+		return nil, fmt.Errorf("can't report gains: %w", err)
+	}
+	for _, g := range book.realized {
+		day := Day.Start(g.Sold)
+		if day.Before(from) || day.After(to) {
+			continue
+		}
+		proceeds, err := p.convert(rates, g.Proceeds, g.Currency, g.Sold)
+		if err != nil {
+			return nil, fmt.Errorf("can't report gain on %s: %w", g.Asset, err)
+		}
+		cost, err := p.convert(rates, g.Cost, g.Currency, g.Acquired)
+		if err != nil {
+			return nil, fmt.Errorf("can't report gain on %s: %w", g.Asset, err)
+		}
+		adjustment, err := p.convert(rates, g.Disallowed, g.Currency, g.Acquired)
+		if err != nil {
+			return nil, fmt.Errorf("can't report gain on %s: %w", g.Asset, err)
+		}
+		d := Disposal{
+			Asset:         g.Asset,
+			Currency:      g.Currency,
+			TransactionID: g.TransactionID,
+			LotID:         g.LotID,
+			Quantity:      g.Quantity,
+			Acquired:      g.Acquired,
+			Sold:          g.Sold,
+			Proceeds:      proceeds,
+			Cost:          cost,
+			Adjustment:    adjustment,
+			Gain:          proceeds.Sub(cost).Add(adjustment),
+			Term:          rule.Term(g),
+		}
+		if d.Quantity.IsNegative() {
+			d.Quantity, d.Proceeds, d.Cost = d.Quantity.Neg(), cost.Neg(), proceeds.Neg()
+		}
+		res.Disposals = append(res.Disposals, d)
+		res.add(d)
+		if d.Term == LongTerm {
+			res.LongTerm.add(d)
+		} else {
+			res.ShortTerm.add(d)
+		}
+	}
+	sort.SliceStable(res.Disposals, func(i, j int) bool {
+		return res.Disposals[i].Sold.Before(res.Disposals[j].Sold)
+	})
+	return res, nil
+}
@@ -0,0 +1,119 @@
+package portfolio
+
+import (
+	"testing"
+)
+
+func TestGains(t *testing.T) {
+	tests := []struct {
+		name         string
+		shortSelling bool
+		rule         HoldingRule
+		transactions []*Transaction
+		shortTerm    string
+		longTerm     string
+		disposals    int
+	}{
+		{
+			name:         "short term",
+			rule:         DefaultHoldingRule,
+			transactions: []*Transaction{newTrade(1, "2021-01-04", "AAPL", "10", "100"), newTrade(2, "2021-06-01", "AAPL", "-10", "110")},
+			shortTerm:    "100",
+			longTerm:     "0",
+			disposals:    1,
+		},
+		{
+			name:         "long term",
+			rule:         DefaultHoldingRule,
+			transactions: []*Transaction{newTrade(1, "2020-01-02", "AAPL", "10", "100"), newTrade(2, "2021-03-01", "AAPL", "-10", "90")},
+			shortTerm:    "0",
+			longTerm:     "-100",
+			disposals:    1,
+		},
+		{
+			name:         "held for exactly the holding period",
+			rule:         DefaultHoldingRule,
+			transactions: []*Transaction{newTrade(1, "2020-03-02", "AAPL", "10", "100"), newTrade(2, "2021-03-02", "AAPL", "-10", "110")},
+			shortTerm:    "100",
+			longTerm:     "0",
+			disposals:    1,
+		},
+		{
+			name:         "held a day longer than the holding period",
+			rule:         DefaultHoldingRule,
+			transactions: []*Transaction{newTrade(1, "2020-03-02", "AAPL", "10", "100"), newTrade(2, "2021-03-03", "AAPL", "-10", "110")},
+			shortTerm:    "0",
+			longTerm:     "100",
+			disposals:    1,
+		},
+		{
+			name: "sale of lots of both terms",
+			rule: DefaultHoldingRule,
+			transactions: []*Transaction{
+				newTrade(1, "2020-01-02", "AAPL", "10", "100"),
+				newTrade(2, "2021-01-04", "AAPL", "10", "120"),
+				newTrade(3, "2021-06-01", "AAPL", "-15", "130"),
+			},
+			shortTerm: "50",
+			longTerm:  "300",
+			disposals: 2,
+		},
+		{
+			name:         "custom holding period",
+			rule:         HoldingRule{Months: 3},
+			transactions: []*Transaction{newTrade(1, "2021-01-04", "AAPL", "10", "100"), newTrade(2, "2021-06-01", "AAPL", "-10", "110")},
+			shortTerm:    "0",
+			longTerm:     "100",
+			disposals:    1,
+		},
+		{
+			name:         "short sale",
+			shortSelling: true,
+			rule:         HoldingRule{Months: 0},
+			transactions: []*Transaction{newTrade(1, "2021-01-04", "TSLA", "-10", "50"), newTrade(2, "2021-06-01", "TSLA", "10", "40")},
+			shortTerm:    "100",
+			longTerm:     "0",
+			disposals:    1,
+		},
+		{
+			name:         "sale outside the period",
+			rule:         DefaultHoldingRule,
+			transactions: []*Transaction{newTrade(1, "2020-01-02", "AAPL", "10", "100"), newTrade(2, "2020-06-01", "AAPL", "-10", "110")},
+			shortTerm:    "0",
+			longTerm:     "0",
+			disposals:    0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newTestPortfolio(t, 1)
+			if err := p.ChangeShortSelling(tt.shortSelling); err != nil {
+				t.Fatal(err)
+			}
+			applyAll(t, p, tt.transactions...)
+
+			report, err := p.Gains(day("2021-01-01"), day("2021-12-31"), tt.rule, nil)
+			if err != nil {
+				t.Fatalf("Gains() error = %v", err)
+			}
+			if !report.ShortTerm.Gain.Equal(dec(tt.shortTerm)) || !report.LongTerm.Gain.Equal(dec(tt.longTerm)) {
+				t.Errorf("Gains() short-term %s and long-term %s, want %s and %s", report.ShortTerm.Gain, report.LongTerm.Gain, tt.shortTerm, tt.longTerm)
+			}
+			if len(report.Disposals) != tt.disposals {
+				t.Errorf("Gains() has %d disposals, want %d", len(report.Disposals), tt.disposals)
+			}
+			for _, d := range report.Disposals {
+				if !d.Quantity.IsPositive() || !d.Gain.Equal(d.Proceeds.Sub(d.Cost).Add(d.Adjustment)) {
+					t.Errorf("disposal of %s units with proceeds %s, cost %s and gain %s", d.Quantity, d.Proceeds, d.Cost, d.Gain)
+				}
+			}
+		})
+	}
+}
+
+func TestGainsNegativeHoldingPeriod(t *testing.T) {
+	p := newTestPortfolio(t, 1)
+	if _, err := p.Gains(day("2021-01-01"), day("2021-12-31"), HoldingRule{Months: -1}, nil); err == nil {
+		t.Error("Gains() error = nil, want an error")
+	}
+}
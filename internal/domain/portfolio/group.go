@@ -0,0 +1,147 @@
+package portfolio
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+var ErrGroupNotFound = errors.New("group not found")
+
+type Group struct {
+	id         uuid.UUID
+	userID     uuid.UUID
+	name       string
+	parentID   uuid.UUID
+	portfolios []uuid.UUID
+}
+
+func NewGroup(id, userID uuid.UUID, name string, parentID uuid.UUID, portfolios []uuid.UUID) (*Group, error) {
+	g := &Group{}
+	if id == uuid.Nil {
+		return nil, fmt.Errorf("can't create group: id is mandatory")
+	}
+	g.id = id
+	if userID == uuid.Nil {
+		return nil, fmt.Errorf("can't create group: user id is mandatory")
+	}
+	g.userID = userID
+	if err := g.Rename(name); err != nil {
+		return nil, fmt.Errorf("can't create group: %w", err)
+	}
+	if err := g.Move(parentID); err != nil {
+		return nil, fmt.Errorf("can't create group: %w", err)
+	}
+	if err := g.SetPortfolios(portfolios); err != nil {
+		return nil, fmt.Errorf("can't create group: %w", err)
+	}
+	return g, nil
+}
+
+func (g *Group) Rename(name string) error {
+	if name == "" {
+		return fmt.Errorf("name is mandatory")
+	}
+	g.name = name
+	return nil
+}
+
+func (g *Group) Move(parentID uuid.UUID) error {
+	if parentID == g.id {
+		return fmt.Errorf("group can't be its own parent")
+	}
+	g.parentID = parentID
+	return nil
+}
+
+func (g *Group) SetPortfolios(portfolios []uuid.UUID) error {
+	g.portfolios = []uuid.UUID{}
+	seen := map[uuid.UUID]bool{}
+	for _, id := range portfolios {
+		if id == uuid.Nil {
+			return fmt.Errorf("portfolio id is mandatory")
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		g.portfolios = append(g.portfolios, id)
+	}
+	return nil
+}
+
+func (g *Group) ID() uuid.UUID {
+	return g.id
+}
+
+func (g *Group) UserID() uuid.UUID {
+	return g.userID
+}
+
+func (g *Group) Name() string {
+	return g.name
+}
+
+func (g *Group) ParentID() uuid.UUID {
+	return g.parentID
+}
+
+func (g *Group) Portfolios() []uuid.UUID {
+	return g.portfolios
+}
+
+func CheckGroups(groups []*Group) error {
+	byID := map[uuid.UUID]*Group{}
+	for _, g := range groups {
+		byID[g.id] = g
+	}
+
+	held := map[uuid.UUID]*Group{}
+	for _, g := range groups {
+		for _, id := range g.portfolios {
+			if other, ok := held[id]; ok {
+				return fmt.Errorf("portfolio %s is already in group %s", id.String(), other.name)
+			}
+			held[id] = g
+		}
+
+		visited := map[uuid.UUID]bool{g.id: true}
+		for parentID := g.parentID; parentID != uuid.Nil; {
+			parent, ok := byID[parentID]
+			if !ok {
+				return fmt.Errorf("parent of group %s: %w", g.name, ErrGroupNotFound)
+			}
+			if visited[parentID] {
+				return fmt.Errorf("group %s can't be moved into itself", g.name)
+			}
+			visited[parentID] = true
+			parentID = parent.parentID
+		}
+	}
+	return nil
+}
+
+func Subgroups(groups []*Group, id uuid.UUID) []*Group {
+	res := []*Group{}
+	for _, g := range groups {
+		if g.id == id {
+			res = append(res, g)
+		}
+	}
+	for i := 0; i < len(res); i++ {
+		for _, g := range groups {
+			if g.parentID == res[i].id {
+				res = append(res, g)
+			}
+		}
+	}
+	return res
+}
+
+type GroupReport struct {
+	Group        *Group
+	Subgroups    []*Group
+	Portfolios   []uuid.UUID
+	Consolidated *Consolidated
+}
@@ -0,0 +1,15 @@
+package portfolio
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type GroupRepository interface {
+	AddGroup(ctx context.Context, g *Group) error
+	GetGroups(ctx context.Context, userID uuid.UUID) ([]*Group, error)
+	GetGroup(ctx context.Context, userID, id uuid.UUID) (*Group, error)
+	UpdateGroup(ctx context.Context, userID, id uuid.UUID, updateFn func(g *Group) error) error
+	DeleteGroup(ctx context.Context, userID, id uuid.UUID) error
+}
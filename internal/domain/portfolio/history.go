@@ -0,0 +1,91 @@
+package portfolio
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/invine/portfolio/internal/domain/fx"
+	"github.com/invine/portfolio/internal/domain/price"
+	"github.com/shopspring/decimal"
+)
+
+type HistoryPoint struct {
+	Date        time.Time
+	Assets      Assets
+	Balance     decimal.Decimal
+	Invested    decimal.Decimal
+	MarketValue decimal.Decimal
+}
+
+type History struct {
+	ID       uuid.UUID
+	Name     string
+	Currency string
+	Interval Period
+	Points   []HistoryPoint
+}
+
+func (p *Portfolio) History(from, to time.Time, interval Period, prices map[string]price.Series, rates *fx.Rates) (*History, error) {
+	h := &History{
+		ID:       p.id,
+		Name:     p.name,
+		Currency: p.currency,
+		Interval: interval,
+		Points:   []HistoryPoint{},
+	}
+
+	events := p.events()
+	book := p.newBook()
+	i := 0
+	for start := interval.Start(from); !start.After(to); start = interval.Next(start) {
+		date := interval.Next(start).AddDate(0, 0, -1)
+		if date.After(to) {
+			date = to
+		}
+		end := date.AddDate(0, 0, 1)
+		for ; i < len(events) && events[i].date.Before(end); i++ {
+			if _, _, err := book.applyEvent(events[i]); err != nil {
+				return nil, fmt.Errorf("can't build history: %w", eventError(events[i], err))
+			}
+		}
+
+		point := HistoryPoint{
+			Date:   date,
+			Assets: Assets{},
+		}
+		for currency, cash := range book.cash {
+			converted, err := p.convert(rates, cash, currency, date)
+			if err != nil {
+				return nil, fmt.Errorf("can't value cash: %w", err)
+			}
+			point.Balance = point.Balance.Add(converted)
+		}
+		for asset, pos := range book.positions() {
+			point.Assets[asset] = pos.Quantity
+			for _, l := range pos.Lots {
+				cost, err := p.convert(rates, l.Cost(), pos.Currency, l.Date)
+				if err != nil {
+					return nil, fmt.Errorf("can't value cost of %s: %w", asset, err)
+				}
+				point.Invested = point.Invested.Add(cost)
+			}
+			last := book.lastPrices[asset]
+			if pr := prices[asset].At(price.LastCloseDate(date)); pr != nil {
+				rate, err := priceRate(pr, pos.Currency, rates, date)
+				if err != nil {
+					return nil, fmt.Errorf("can't value %s: %w", asset, err)
+				}
+				last = pr.Close().Mul(rate)
+			}
+			value, err := p.convert(rates, last.Mul(pos.Quantity).Mul(pos.Multiplier), pos.Currency, date)
+			if err != nil {
+				return nil, fmt.Errorf("can't value %s: %w", asset, err)
+			}
+			point.MarketValue = point.MarketValue.Add(value)
+		}
+		h.Points = append(h.Points, point)
+	}
+
+	return h, nil
+}
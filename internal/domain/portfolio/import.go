@@ -0,0 +1,241 @@
+package portfolio
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+type ImportRecord struct {
+	Kind     Kind
+	Asset    string
+	Date     time.Time
+	Quantity decimal.Decimal
+	Price    decimal.Decimal
+	Amount   decimal.Decimal
+	Currency string
+	Fee      decimal.Decimal
+	Fees     []Fee
+	Accrued  decimal.Decimal
+}
+
+func (r ImportRecord) Transaction(id uuid.UUID) (*Transaction, error) {
+	kind := r.Kind
+	if kind == "" {
+		kind = Buy
+		if r.Quantity.IsNegative() {
+			kind = Sell
+		}
+	}
+	var (
+		t   *Transaction
+		err error
+	)
+	switch {
+	case kind.IsTrade():
+		quantity := r.Quantity.Abs()
+		if kind == Sell {
+			quantity = quantity.Neg()
+		}
+		t, err = NewTransaction(id, r.Date, r.Asset, quantity, r.Price)
+	case kind.IsIncome():
+		t, err = NewIncomeTransaction(id, r.Date, kind, r.Asset, r.Amount.Abs())
+	case kind.IsCashFlow():
+		t, err = NewCashTransaction(id, r.Date, kind, r.Amount.Abs())
+	case kind == BorrowFee:
+		t, err = NewBorrowFee(id, r.Date, r.Asset, r.Amount.Abs())
+	case kind == AccountFee:
+		t, err = NewAccountFee(id, r.Date, r.Asset, r.Amount.Abs())
+	default:
+		return nil, fmt.Errorf("can't import transaction: %s can't be imported", kind)
+	}
+	if err != nil {
+		return nil, err
+	}
+	fees := []Fee{}
+	if !r.Fee.IsZero() {
+		fees = append(fees, Fee{Type: Commission, Amount: r.Fee.Abs()})
+	}
+	for _, f := range r.Fees {
+		if !f.Amount.IsZero() {
+			f.Amount = f.Amount.Abs()
+			fees = append(fees, f)
+		}
+	}
+	if err := t.SetFees(fees); err != nil {
+		return nil, err
+	}
+	if !r.Accrued.IsZero() && kind.IsTrade() {
+		if err := t.SetAccrued(r.Accrued.Abs()); err != nil {
+			return nil, err
+		}
+	}
+	if err := t.SetCurrency(r.Currency); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+type ImportStatus string
+
+const (
+	ImportAccepted  ImportStatus = "accepted"
+	ImportDuplicate ImportStatus = "duplicate"
+	ImportRejected  ImportStatus = "rejected"
+)
+
+type ImportRow struct {
+	Line        int
+	Transaction *Transaction
+	Action      *CorporateAction
+	Err         error
+	Status      ImportStatus
+	DuplicateOf uuid.UUID
+}
+
+func (r ImportRow) date() time.Time {
+	if r.Action != nil {
+		return r.Action.date
+	}
+	return r.Transaction.date
+}
+
+type StatementPosition struct {
+	Asset    string
+	Date     time.Time
+	Quantity decimal.Decimal
+}
+
+type Statement struct {
+	Rows      []ImportRow
+	Positions []StatementPosition
+}
+
+type PositionCheck struct {
+	StatementPosition
+	Holds decimal.Decimal
+}
+
+func (pc PositionCheck) Matches() bool {
+	return pc.Quantity.Equal(pc.Holds)
+}
+
+type ImportPreview struct {
+	ID         uuid.UUID
+	Name       string
+	Rows       []ImportRow
+	Accepted   int
+	Duplicates int
+	Rejected   int
+	Positions  []PositionCheck
+}
+
+func (ip *ImportPreview) Transactions() []*Transaction {
+	res := []*Transaction{}
+	for _, r := range ip.Rows {
+		if r.Status == ImportAccepted && r.Transaction != nil {
+			res = append(res, r.Transaction)
+		}
+	}
+	return res
+}
+
+func (ip *ImportPreview) Actions() []*CorporateAction {
+	res := []*CorporateAction{}
+	for _, r := range ip.Rows {
+		if r.Status == ImportAccepted && r.Action != nil {
+			res = append(res, r.Action)
+		}
+	}
+	return res
+}
+
+func (p *Portfolio) Import(rows []ImportRow) *ImportPreview {
+	res := &ImportPreview{ID: p.id, Name: p.name, Rows: make([]ImportRow, len(rows))}
+	copy(res.Rows, rows)
+
+	order := []int{}
+	for i, r := range res.Rows {
+		if r.Err == nil && r.Transaction == nil && r.Action == nil {
+			res.Rows[i].Err = fmt.Errorf("row has no transaction")
+		}
+		if res.Rows[i].Err != nil {
+			res.Rows[i].Status = ImportRejected
+			continue
+		}
+		order = append(order, i)
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return res.Rows[order[i]].date().Before(res.Rows[order[j]].date())
+	})
+
+	recorded := executed(p.transactions)
+	for _, i := range order {
+		r := &res.Rows[i]
+		if r.Action != nil {
+			if a := findAction(p.allActions(), r.Action); a != nil {
+				r.Status, r.DuplicateOf = ImportDuplicate, a.id
+				continue
+			}
+			p.actions = append(append([]*CorporateAction{}, p.actions...), r.Action)
+			r.Status = ImportAccepted
+			continue
+		}
+		if j := findDuplicate(recorded, r.Transaction); j >= 0 {
+			r.Status, r.DuplicateOf = ImportDuplicate, recorded[j].id
+			recorded = append(recorded[:j], recorded[j+1:]...)
+			continue
+		}
+		if err := p.ApplyTransaction(r.Transaction); err != nil {
+			r.Status, r.Err = ImportRejected, err
+			continue
+		}
+		r.Status = ImportAccepted
+	}
+
+	for _, r := range res.Rows {
+		switch r.Status {
+		case ImportAccepted:
+			res.Accepted++
+		case ImportDuplicate:
+			res.Duplicates++
+		default:
+			res.Rejected++
+		}
+	}
+	return res
+}
+
+func findDuplicate(transactions []*Transaction, t *Transaction) int {
+	for i, r := range transactions {
+		if r.kind == t.kind && r.asset == t.asset && Day.Start(r.date).Equal(Day.Start(t.date)) &&
+			r.quantity.Equal(t.quantity) && r.price.Equal(t.price) && r.amount.Equal(t.amount) {
+			return i
+		}
+	}
+	return -1
+}
+
+func findAction(actions []*CorporateAction, a *CorporateAction) *CorporateAction {
+	for _, r := range actions {
+		if r.kind == a.kind && r.asset == a.asset && Day.Start(r.date).Equal(Day.Start(a.date)) && r.from == a.from && r.to == a.to {
+			return r
+		}
+	}
+	return nil
+}
+
+func (p *Portfolio) Reconcile(positions []StatementPosition) ([]PositionCheck, error) {
+	res := []PositionCheck{}
+	for _, pos := range positions {
+		book, err := p.replay(Day.Start(pos.Date).AddDate(0, 0, 1).Add(-time.Nanosecond))
+		if err != nil {
+			return nil, fmt.Errorf("can't reconcile positions: %w", err)
+		}
+		res = append(res, PositionCheck{StatementPosition: pos, Holds: book.held(pos.Asset)})
+	}
+	return res, nil
+}
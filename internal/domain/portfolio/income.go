@@ -0,0 +1,124 @@
+package portfolio
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/invine/portfolio/internal/domain/fx"
+	"github.com/shopspring/decimal"
+)
+
+type IncomeItem struct {
+	TransactionID uuid.UUID
+	Date          time.Time
+	Kind          Kind
+	Asset         string
+	Amount        decimal.Decimal
+	Currency      string
+}
+
+type IncomeTotal struct {
+	Total  decimal.Decimal
+	ByKind map[Kind]decimal.Decimal
+}
+
+func (it *IncomeTotal) add(kind Kind, amount decimal.Decimal) {
+	it.Total = it.Total.Add(amount)
+	it.ByKind[kind] = it.ByKind[kind].Add(amount)
+}
+
+type AssetIncome struct {
+	Asset string
+	IncomeTotal
+}
+
+type PeriodIncome struct {
+	Period string
+	Start  time.Time
+	End    time.Time
+	IncomeTotal
+}
+
+type IncomeReport struct {
+	ID       uuid.UUID
+	Name     string
+	Currency string
+	From     time.Time
+	To       time.Time
+	IncomeTotal
+	Assets  []AssetIncome
+	Periods []PeriodIncome
+	Items   []IncomeItem
+}
+
+func (p *Portfolio) Income(from, to time.Time, period Period, rates *fx.Rates) (*IncomeReport, error) {
+	from, to = Day.Start(from), Day.Start(to)
+	res := &IncomeReport{
+		ID:          p.id,
+		Name:        p.name,
+		Currency:    p.currency,
+		From:        from,
+		To:          to,
+		IncomeTotal: IncomeTotal{ByKind: map[Kind]decimal.Decimal{}},
+		Assets:      []AssetIncome{},
+		Periods:     []PeriodIncome{},
+		Items:       []IncomeItem{},
+	}
+
+	assets := map[string]*AssetIncome{}
+	periods := map[string]*PeriodIncome{}
+	for _, t := range sortedTransactions(p.withPayments(executed(p.transactions))) {
+		day := Day.Start(t.date)
+		income := t.income()
+		if income.IsZero() || day.Before(from) || day.After(to) {
+			continue
+		}
+		kind := t.kind
+		if kind.IsTrade() {
+			kind = Coupon
+		}
+
+		res.Items = append(res.Items, IncomeItem{
+			TransactionID: t.id,
+			Date:          t.date,
+			Kind:          kind,
+			Asset:         t.asset,
+			Amount:        income,
+			Currency:      t.settledIn(p.currency),
+		})
+		amount, err := p.convert(rates, income, t.settledIn(p.currency), t.date)
+		if err != nil {
+			return nil, fmt.Errorf("can't convert income: %w", err)
+		}
+		res.add(kind, amount)
+
+		if _, ok := assets[t.asset]; !ok {
+			assets[t.asset] = &AssetIncome{Asset: t.asset, IncomeTotal: IncomeTotal{ByKind: map[Kind]decimal.Decimal{}}}
+		}
+		assets[t.asset].add(kind, amount)
+
+		key := period.Key(t.date)
+		if _, ok := periods[key]; !ok {
+			periods[key] = &PeriodIncome{
+				Period:      key,
+				Start:       period.Start(t.date),
+				End:         period.Next(t.date),
+				IncomeTotal: IncomeTotal{ByKind: map[Kind]decimal.Decimal{}},
+			}
+		}
+		periods[key].add(kind, amount)
+	}
+
+	for _, a := range assets {
+		res.Assets = append(res.Assets, *a)
+	}
+	sort.Slice(res.Assets, func(i, j int) bool { return res.Assets[i].Asset < res.Assets[j].Asset })
+	for _, pi := range periods {
+		res.Periods = append(res.Periods, *pi)
+	}
+	sort.Slice(res.Periods, func(i, j int) bool { return res.Periods[i].Start.Before(res.Periods[j].Start) })
+
+	return res, nil
+}
@@ -0,0 +1,103 @@
+package portfolio
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+type OptionSettlement struct {
+	Date         time.Time
+	Kind         Kind
+	Asset        string
+	InstrumentID uuid.UUID
+	Quantity     decimal.Decimal
+	Contract     Contract
+	UnderlyingID uuid.UUID
+}
+
+func (p *Portfolio) SettleOption(settlementID, tradeID uuid.UUID, s OptionSettlement) error {
+	if !s.Contract.IsOption() {
+		return fmt.Errorf("can't settle option: %s isn't an option", s.Asset)
+	}
+	if !s.Quantity.IsPositive() {
+		return fmt.Errorf("can't settle option: quantity must be positive")
+	}
+	expiry := Day.Start(s.Contract.Expiry)
+	if s.Kind == Expiry && Day.Start(s.Date).Before(expiry) {
+		return fmt.Errorf("can't settle option: %s expires on %s", s.Asset, expiry.Format("2006-01-02"))
+	}
+	if s.Kind != Expiry && Day.Start(s.Date).After(expiry) {
+		return fmt.Errorf("can't settle option: %s expired on %s", s.Asset, expiry.Format("2006-01-02"))
+	}
+
+	book, err := p.replay(s.Date)
+	if err != nil {
+		return fmt.Errorf("can't settle option: %w", err)
+	}
+	quantity := s.Quantity
+	if s.Kind == Exercise || s.Kind == Expiry && book.held(s.Asset).IsPositive() {
+		quantity = quantity.Neg()
+	}
+	settlement, err := NewOptionSettlement(settlementID, s.Date, s.Kind, s.Asset, quantity)
+	if err != nil {
+		return fmt.Errorf("can't settle option: %w", err)
+	}
+	currency := book.currencyOf(s.Asset)
+	if err := settlement.SetCurrency(currency); err != nil {
+		return fmt.Errorf("can't settle option: %w", err)
+	}
+	if err := settlement.SetMultiplier(s.Contract.Multiplier); err != nil {
+		return fmt.Errorf("can't settle option: %w", err)
+	}
+	if s.InstrumentID != uuid.Nil {
+		if err := settlement.SetInstrument(s.InstrumentID, s.Asset); err != nil {
+			return fmt.Errorf("can't settle option: %w", err)
+		}
+	}
+	if s.Kind == Expiry {
+		if err := p.ApplyTransaction(settlement); err != nil {
+			return fmt.Errorf("can't settle option: %w", err)
+		}
+		return nil
+	}
+
+	// the contracts are closed on a replayed book to find their premium, the portfolio stays as it is until both are applied
+	closed, err := book.apply(settlement)
+	if err != nil {
+		return fmt.Errorf("can't settle option: %w", err)
+	}
+	premium := decimal.Zero
+	for _, l := range closed {
+		premium = premium.Add(l.Cost())
+	}
+	units := s.Quantity.Mul(s.Contract.Multiplier)
+	if (s.Contract.Right == Call) != (s.Kind == Exercise) {
+		units = units.Neg()
+	}
+	trade, err := NewTransaction(tradeID, s.Date, s.Contract.Underlying, units, s.Contract.Strike)
+	if err != nil {
+		return fmt.Errorf("can't settle option: %w", err)
+	}
+	if err := trade.SetPremium(premium); err != nil {
+		return fmt.Errorf("can't settle option: %w", err)
+	}
+	if err := trade.SetCurrency(currency); err != nil {
+		return fmt.Errorf("can't settle option: %w", err)
+	}
+	if s.UnderlyingID != uuid.Nil {
+		if err := trade.SetInstrument(s.UnderlyingID, s.Contract.Underlying); err != nil {
+			return fmt.Errorf("can't settle option: %w", err)
+		}
+	}
+
+	if err := p.ApplyTransaction(settlement); err != nil {
+		return fmt.Errorf("can't settle option: %w", err)
+	}
+	if err := p.ApplyTransaction(trade); err != nil {
+		return fmt.Errorf("can't settle option: %w", err)
+	}
+	return nil
+}
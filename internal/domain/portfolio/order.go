@@ -0,0 +1,87 @@
+package portfolio
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+func (p *Portfolio) PlaceOrders(orders []*Transaction) error {
+	filled := make([]*Transaction, 0, len(orders))
+	for _, t := range orders {
+		if !t.kind.IsTrade() {
+			return fmt.Errorf("can't place order: %s isn't a trade", t.kind)
+		}
+		if err := p.validate(t); err != nil {
+			return fmt.Errorf("can't place order: %w", err)
+		}
+		t.pending = true
+		f := *t
+		f.pending = false
+		filled = append(filled, &f)
+	}
+
+	// the orders are checked as if they were filled, along with the transactions made after them
+	transactions := p.transactions
+	p.transactions = append(transactions[:len(transactions):len(transactions)], filled...)
+	err := p.check()
+	p.transactions = transactions
+	if err != nil {
+		return fmt.Errorf("can't place order: %w", err)
+	}
+	for _, t := range orders {
+		p.settle(t)
+	}
+	p.transactions = append(transactions[:len(transactions):len(transactions)], orders...)
+	return nil
+}
+
+func (p *Portfolio) FillOrder(id uuid.UUID, date time.Time, quantity, price decimal.Decimal, fees []Fee) error {
+	i := p.findOrder(id)
+	if i < 0 {
+		return fmt.Errorf("can't fill order: pending order %s not found", id)
+	}
+	if !quantity.IsPositive() {
+		return fmt.Errorf("can't fill order: quantity must be positive")
+	}
+	filled := *p.transactions[i]
+	filled.pending = false
+	if filled.kind == Sell {
+		quantity = quantity.Neg()
+	}
+	if err := filled.UpdateTransaction(date, filled.asset, quantity, price); err != nil {
+		return fmt.Errorf("can't fill order: %w", err)
+	}
+	if fees != nil {
+		if err := filled.SetFees(fees); err != nil {
+			return fmt.Errorf("can't fill order: %w", err)
+		}
+	}
+	transactions := p.transactions
+	p.transactions = append(append([]*Transaction{}, transactions[:i]...), transactions[i+1:]...)
+	if err := p.ApplyTransaction(&filled); err != nil {
+		p.transactions = transactions
+		return fmt.Errorf("can't fill order: %w", err)
+	}
+	return nil
+}
+
+func (p *Portfolio) CancelOrder(id uuid.UUID) error {
+	i := p.findOrder(id)
+	if i < 0 {
+		return fmt.Errorf("can't cancel order: pending order %s not found", id)
+	}
+	p.transactions = append(append([]*Transaction{}, p.transactions[:i]...), p.transactions[i+1:]...)
+	return nil
+}
+
+func (p *Portfolio) findOrder(id uuid.UUID) int {
+	for i, t := range p.transactions {
+		if t.id == id && t.pending {
+			return i
+		}
+	}
+	return -1
+}
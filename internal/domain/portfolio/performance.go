@@ -0,0 +1,258 @@
+package portfolio
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/invine/portfolio/internal/domain/fx"
+	"github.com/invine/portfolio/internal/domain/price"
+	"github.com/shopspring/decimal"
+)
+
+type CashFlow struct {
+	Date   time.Time
+	Amount decimal.Decimal
+}
+
+type Performance struct {
+	ID          uuid.UUID
+	Name        string
+	Currency    string
+	From        time.Time
+	To          time.Time
+	StartValue  decimal.Decimal
+	EndValue    decimal.Decimal
+	NetCashFlow decimal.Decimal
+	// TimeWeighted is the cumulative time-weighted return, TimeWeightedAnnualized is the same return per year.
+	// It isn't set when the value of the portfolio changes its sign, e.g. with short positions.
+	TimeWeighted           float64
+	TimeWeightedAnnualized *float64
+	// MoneyWeighted is the annual internal rate of return (XIRR), MoneyWeightedCumulative is the same return over the period.
+	// They aren't set when the rate isn't a number.
+	MoneyWeighted           *float64
+	MoneyWeightedCumulative *float64
+}
+
+func (p *Portfolio) CashFlows(from, to time.Time, rates *fx.Rates) ([]CashFlow, error) {
+	ledger := p.holdsCash()
+	book := p.newBook()
+	flows := []CashFlow{}
+	for _, e := range p.events() {
+		day := Day.Start(e.date)
+		if day.After(to) {
+			break
+		}
+		currency, cash, err := book.applyEvent(e)
+		if err != nil {
+			return nil, fmt.Errorf("can't list cash flows: %w", eventError(e, err))
+		}
+		if day.Before(from) {
+			continue
+		}
+		t := e.transaction
+		flow := cash.Neg()
+		switch {
+		case t != nil && t.kind.IsTransfer() && t.asset != "":
+			flow = t.quantity.Mul(t.price).Mul(t.Multiplier())
+		case ledger && (t == nil || !t.isExternal()):
+			continue
+		case ledger:
+			flow = cash
+		}
+		if flow.IsZero() {
+			continue
+		}
+		amount, err := p.convert(rates, flow, currency, day)
+		if err != nil {
+			return nil, fmt.Errorf("can't convert cash flow: %w", err)
+		}
+		flows = append(flows, CashFlow{Date: day, Amount: amount})
+	}
+	return flows, nil
+}
+
+func (p *Portfolio) holdsCash() bool {
+	for _, t := range p.transactions {
+		if t.isExternal() && t.asset == "" {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Portfolio) Performance(from, to time.Time, prices map[string]price.Series, rates *fx.Rates) (*Performance, error) {
+	from, to = Day.Start(from), Day.Start(to)
+	if !to.After(from) {
+		return nil, fmt.Errorf("can't calculate performance: period must be at least one day")
+	}
+
+	series, err := p.valueSeries(from, to, prices, rates)
+	if err != nil {
+		return nil, fmt.Errorf("can't calculate performance: %w", err)
+	}
+	perf, err := series.performance(from, to)
+	if err != nil {
+		return nil, err
+	}
+	perf.ID = p.id
+	perf.Name = p.name
+	perf.Currency = p.currency
+	return perf, nil
+}
+
+type valueSeries struct {
+	dates  []time.Time
+	values []decimal.Decimal
+	flows  []CashFlow
+}
+
+func (p *Portfolio) valueSeries(from, to time.Time, prices map[string]price.Series, rates *fx.Rates) (*valueSeries, error) {
+	ledger := p.holdsCash()
+	history, err := p.History(from, to, Day, prices, rates)
+	if err != nil {
+		return nil, err
+	}
+	series := &valueSeries{}
+	for _, point := range history.Points {
+		value := point.MarketValue
+		if ledger {
+			value = value.Add(point.Balance)
+		}
+		series.dates = append(series.dates, point.Date)
+		series.values = append(series.values, value)
+	}
+	series.flows, err = p.CashFlows(from.AddDate(0, 0, 1), to, rates)
+	if err != nil {
+		return nil, err
+	}
+	return series, nil
+}
+
+func (series *valueSeries) add(other *valueSeries) {
+	if series.dates == nil {
+		series.dates = other.dates
+		series.values = make([]decimal.Decimal, len(other.values))
+	}
+	for i, v := range other.values {
+		series.values[i] = series.values[i].Add(v)
+	}
+	series.flows = append(series.flows, other.flows...)
+}
+
+func (series *valueSeries) performance(from, to time.Time) (*Performance, error) {
+	values := series.values
+	flows := map[time.Time]decimal.Decimal{}
+	perf := &Performance{
+		From:       from,
+		To:         to,
+		StartValue: values[0],
+		EndValue:   values[len(values)-1],
+	}
+	xirrFlows := []CashFlow{{Date: from, Amount: perf.StartValue.Neg()}}
+	for _, f := range series.flows {
+		flows[f.Date] = flows[f.Date].Add(f.Amount)
+		perf.NetCashFlow = perf.NetCashFlow.Add(f.Amount)
+		xirrFlows = append(xirrFlows, CashFlow{Date: f.Date, Amount: f.Amount.Neg()})
+	}
+	sort.SliceStable(xirrFlows, func(i, j int) bool { return xirrFlows[i].Date.Before(xirrFlows[j].Date) })
+	xirrFlows = append(xirrFlows, CashFlow{Date: to, Amount: perf.EndValue})
+
+	// returns are ratios, so they are calculated in floating point
+	growth := 1.0
+	for i := 1; i < len(values); i++ {
+		if values[i-1].IsZero() {
+			continue
+		}
+		growth *= values[i].Sub(flows[series.dates[i]]).InexactFloat64() / values[i-1].InexactFloat64()
+	}
+	years := to.Sub(from).Hours() / 24 / 365
+	perf.TimeWeighted = growth - 1
+	if growth > 0 {
+		perf.TimeWeightedAnnualized = finite(math.Pow(growth, 1/years) - 1)
+	}
+
+	rate, err := xirr(xirrFlows)
+	if err != nil {
+		return nil, fmt.Errorf("can't calculate money-weighted return: %w", err)
+	}
+	if perf.MoneyWeighted = finite(rate); perf.MoneyWeighted != nil && rate > -1 {
+		perf.MoneyWeightedCumulative = finite(math.Pow(1+rate, years) - 1)
+	}
+
+	return perf, nil
+}
+
+func finite(x float64) *float64 {
+	if math.IsNaN(x) || math.IsInf(x, 0) {
+		return nil
+	}
+	return &x
+}
+
+func xirr(flows []CashFlow) (float64, error) {
+	hasPositive, hasNegative := false, false
+	amounts := make([]float64, len(flows))
+	for i, f := range flows {
+		hasPositive = hasPositive || f.Amount.IsPositive()
+		hasNegative = hasNegative || f.Amount.IsNegative()
+		amounts[i] = f.Amount.InexactFloat64()
+	}
+	if !hasPositive || !hasNegative {
+		return math.NaN(), nil
+	}
+
+	start := flows[0].Date
+	npv := func(rate float64) (float64, float64) {
+		value, derivative := 0.0, 0.0
+		for i, f := range flows {
+			years := f.Date.Sub(start).Hours() / 24 / 365
+			discount := math.Pow(1+rate, years)
+			value += amounts[i] / discount
+			derivative -= years * amounts[i] / (discount * (1 + rate))
+		}
+		return value, derivative
+	}
+
+	const tolerance = 1e-9
+	rate := 0.1
+	for i := 0; i < 100; i++ {
+		value, derivative := npv(rate)
+		if math.Abs(value) < tolerance {
+			return rate, nil
+		}
+		if derivative == 0 {
+			break
+		}
+		next := rate - value/derivative
+		if next <= -1 || math.IsNaN(next) || math.IsInf(next, 0) {
+			break
+		}
+		rate = next
+	}
+
+	low, high := -0.999999, 1.0
+	for v, _ := npv(high); v > 0 && high < 1e6; v, _ = npv(high) {
+		high *= 2
+	}
+	vLow, _ := npv(low)
+	vHigh, _ := npv(high)
+	if vLow*vHigh > 0 {
+		return 0, fmt.Errorf("rate doesn't converge")
+	}
+	for i := 0; i < 200; i++ {
+		mid := (low + high) / 2
+		vMid, _ := npv(mid)
+		if math.Abs(vMid) < tolerance {
+			return mid, nil
+		}
+		if vLow*vMid < 0 {
+			high = mid
+		} else {
+			low, vLow = mid, vMid
+		}
+	}
+	return (low + high) / 2, nil
+}
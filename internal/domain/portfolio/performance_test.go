@@ -0,0 +1,110 @@
+package portfolio
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestXIRR(t *testing.T) {
+	flow := func(date, amount string) CashFlow {
+		return CashFlow{Date: day(date), Amount: dec(amount)}
+	}
+	tests := []struct {
+		name  string
+		flows []CashFlow
+		want  float64
+	}{
+		{name: "one year", flows: []CashFlow{flow("2021-01-01", "-1000"), flow("2022-01-01", "1100")}, want: 0.1},
+		{name: "loss", flows: []CashFlow{flow("2021-01-01", "-1000"), flow("2022-01-01", "900")}, want: -0.1},
+		{name: "nothing earned", flows: []CashFlow{flow("2021-01-01", "-1000"), flow("2021-07-01", "1000")}, want: 0},
+		{
+			name:  "deposit in the middle",
+			flows: []CashFlow{flow("2021-01-01", "-1000"), flow("2021-07-02", "-1000"), flow("2022-01-01", "2148.81")},
+			want:  0.1,
+		},
+		{name: "only outflows", flows: []CashFlow{flow("2021-01-01", "-1000"), flow("2022-01-01", "-100")}, want: math.NaN()},
+		{name: "only inflows", flows: []CashFlow{flow("2021-01-01", "1000"), flow("2022-01-01", "100")}, want: math.NaN()},
+		{name: "no flows", flows: []CashFlow{flow("2021-01-01", "0"), flow("2022-01-01", "0")}, want: math.NaN()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := xirr(tt.flows)
+			if err != nil {
+				t.Fatalf("xirr() error = %v", err)
+			}
+			if math.IsNaN(tt.want) != math.IsNaN(got) || !math.IsNaN(got) && math.Abs(got-tt.want) > 1e-3 {
+				t.Errorf("xirr() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTimeWeightedReturn(t *testing.T) {
+	from := day("2021-01-01")
+	dates := func(n int) []time.Time {
+		res := []time.Time{}
+		for i := 0; i < n; i++ {
+			res = append(res, from.AddDate(0, 0, i))
+		}
+		return res
+	}
+	values := func(s ...string) []decimal.Decimal {
+		res := []decimal.Decimal{}
+		for _, v := range s {
+			res = append(res, dec(v))
+		}
+		return res
+	}
+	tests := []struct {
+		name          string
+		values        []decimal.Decimal
+		flows         []CashFlow
+		timeWeighted  float64
+		moneyWeighted bool
+	}{
+		{
+			name:          "growth",
+			values:        values("100", "110", "121"),
+			timeWeighted:  0.21,
+			moneyWeighted: true,
+		},
+		{
+			name:          "deposit isn't a return",
+			values:        values("100", "160"),
+			flows:         []CashFlow{{Date: from.AddDate(0, 0, 1), Amount: dec("50")}},
+			timeWeighted:  0.1,
+			moneyWeighted: true,
+		},
+		{
+			name:          "withdrawal isn't a loss",
+			values:        values("100", "110", "60"),
+			flows:         []CashFlow{{Date: from.AddDate(0, 0, 2), Amount: dec("-50")}},
+			timeWeighted:  0.1,
+			moneyWeighted: true,
+		},
+		{
+			name:         "empty portfolio",
+			values:       values("0", "0"),
+			timeWeighted: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			series := &valueSeries{dates: dates(len(tt.values)), values: tt.values, flows: tt.flows}
+			to := series.dates[len(series.dates)-1]
+			perf, err := series.performance(from, to)
+			if err != nil {
+				t.Fatalf("performance() error = %v", err)
+			}
+			if math.Abs(perf.TimeWeighted-tt.timeWeighted) > 1e-9 {
+				t.Errorf("performance() time-weighted = %v, want %v", perf.TimeWeighted, tt.timeWeighted)
+			}
+			if got := perf.MoneyWeighted != nil; got != tt.moneyWeighted {
+				t.Errorf("performance() money-weighted set = %v, want %v", got, tt.moneyWeighted)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package portfolio
+
+import (
+	"fmt"
+	"time"
+)
+
+type Period string
+
+const (
+	Day     Period = "day"
+	Week    Period = "week"
+	Month   Period = "month"
+	Quarter Period = "quarter"
+	Year    Period = "year"
+)
+
+func ParsePeriod(s string) (Period, error) {
+	p := Period(s)
+	switch p {
+	case Day, Week, Month, Quarter, Year:
+		return p, nil
+	}
+	return "", fmt.Errorf("unknown period %q", s)
+}
+
+func (p Period) Start(t time.Time) time.Time {
+	switch p {
+	case Year:
+		return time.Date(t.Year(), 1, 1, 0, 0, 0, 0, t.Location())
+	case Quarter:
+		return time.Date(t.Year(), (t.Month()-1)/3*3+1, 1, 0, 0, 0, 0, t.Location())
+	case Month:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	case Week:
+		d := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+		return d.AddDate(0, 0, -(int(d.Weekday())+6)%7)
+	default:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	}
+}
+
+func (p Period) Next(t time.Time) time.Time {
+	switch p {
+	case Year:
+		return p.Start(t).AddDate(1, 0, 0)
+	case Quarter:
+		return p.Start(t).AddDate(0, 3, 0)
+	case Month:
+		return p.Start(t).AddDate(0, 1, 0)
+	case Week:
+		return p.Start(t).AddDate(0, 0, 7)
+	default:
+		return p.Start(t).AddDate(0, 0, 1)
+	}
+}
+
+func (p Period) Key(t time.Time) string {
+	switch p {
+	case Year:
+		return fmt.Sprintf("%d", t.Year())
+	case Quarter:
+		return fmt.Sprintf("%d-Q%d", t.Year(), (t.Month()-1)/3+1)
+	case Month:
+		return t.Format("2006-01")
+	case Week:
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	default:
+		return t.Format("2006-01-02")
+	}
+}
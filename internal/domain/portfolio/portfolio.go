@@ -2,29 +2,170 @@ package portfolio
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/invine/portfolio/internal/domain/fx"
+	"github.com/invine/portfolio/internal/domain/price"
+	"github.com/shopspring/decimal"
 )
 
-type Assets map[string]int
+const DefaultCurrency = "USD"
+
+type Assets map[string]decimal.Decimal
 
 type Snapshot struct {
-	ID      uuid.UUID
-	Name    string
-	Assets  Assets
-	Balance float64
+	ID          uuid.UUID
+	Name        string
+	Date        time.Time
+	Currency    string
+	Assets      Assets
+	Positions   map[string]*Position
+	Cash        map[string]decimal.Decimal
+	Balance     decimal.Decimal
+	MarketValue decimal.Decimal
+	DayChange   decimal.Decimal
+	Notional    decimal.Decimal
+	Accrued     decimal.Decimal
+}
+
+func (s *Snapshot) Value(prices map[string]*price.Price, rates *fx.Rates) error {
+	s.Balance = decimal.Zero
+	for currency, cash := range s.Cash {
+		converted, err := rates.Convert(cash, currency, s.Currency, s.Date)
+		if err != nil {
+			return fmt.Errorf("can't value cash: %w", err)
+		}
+		s.Balance = s.Balance.Add(converted)
+	}
+
+	s.MarketValue = decimal.Zero
+	s.DayChange = decimal.Zero
+	values := map[string]decimal.Decimal{}
+	for asset, pos := range s.Positions {
+		pr, ok := prices[asset]
+		if !ok {
+			continue
+		}
+		rate, err := priceRate(pr, pos.Currency, rates, s.Date)
+		if err != nil {
+			return fmt.Errorf("can't value %s: %w", asset, err)
+		}
+		pos.Price = pr.Close().Mul(rate)
+		pos.MarketValue = pos.Price.Mul(pos.Quantity).Mul(pos.Multiplier)
+		pos.DayChange = pr.DayChange().Mul(rate).Mul(pos.Quantity).Mul(pos.Multiplier)
+
+		rate, err = rates.At(pos.Currency, s.Currency, s.Date)
+		if err != nil {
+			return fmt.Errorf("can't value %s: %w", asset, err)
+		}
+		values[asset] = pos.MarketValue.Mul(rate)
+		s.MarketValue = s.MarketValue.Add(values[asset])
+		s.DayChange = s.DayChange.Add(pos.DayChange.Mul(rate))
+	}
+	s.Notional = decimal.Zero
+	for asset, pos := range s.Positions {
+		if pos.Contract == nil {
+			continue
+		}
+		notional, err := s.notional(pos, prices, rates)
+		if err != nil {
+			return fmt.Errorf("can't value exposure of %s: %w", asset, err)
+		}
+		pos.Notional = notional
+		rate, err := rates.At(pos.Currency, s.Currency, s.Date)
+		if err != nil {
+			return fmt.Errorf("can't value exposure of %s: %w", asset, err)
+		}
+		s.Notional = s.Notional.Add(notional.Mul(rate))
+	}
+	s.Accrued = decimal.Zero
+	for asset, pos := range s.Positions {
+		if pos.Bond == nil {
+			continue
+		}
+		pos.Accrued = pos.Bond.Accrued(s.Date).Mul(pos.Quantity)
+		pos.Yield = nil
+		if _, ok := prices[asset]; ok {
+			if y, ok := pos.Bond.Yield(pos.Price.Mul(pos.Multiplier), s.Date); ok {
+				pos.Yield = &y
+			}
+		}
+		rate, err := rates.At(pos.Currency, s.Currency, s.Date)
+		if err != nil {
+			return fmt.Errorf("can't value accrued interest of %s: %w", asset, err)
+		}
+		s.Accrued = s.Accrued.Add(pos.Accrued.Mul(rate))
+	}
+	for asset, pos := range s.Positions {
+		pos.Weight = 0
+		if !s.MarketValue.IsZero() {
+			pos.Weight = values[asset].Div(s.MarketValue).InexactFloat64()
+		}
+	}
+	return nil
+}
+
+func (s *Snapshot) notional(pos *Position, prices map[string]*price.Price, rates *fx.Rates) (decimal.Decimal, error) {
+	c := pos.Contract
+	unit := pos.Price
+	if c.IsOption() {
+		unit = c.Strike
+	}
+	if pr, ok := prices[c.Underlying]; ok {
+		rate, err := priceRate(pr, pos.Currency, rates, s.Date)
+		if err != nil {
+			return decimal.Zero, err
+		}
+		unit = pr.Close().Mul(rate)
+	}
+	notional := unit.Mul(pos.Quantity).Mul(pos.Multiplier)
+	if c.Right == Put {
+		notional = notional.Neg()
+	}
+	return notional, nil
+}
+
+func priceRate(pr *price.Price, currency string, rates *fx.Rates, date time.Time) (decimal.Decimal, error) {
+	c := strings.TrimSpace(pr.Currency())
+	if c == "" {
+		return decimal.NewFromInt(1), nil
+	}
+	// a mixed case code like GBp is a minor unit, which would be taken for the main one
+	if c != strings.ToUpper(c) && c != strings.ToLower(c) {
+		return decimal.Zero, fmt.Errorf("price of %s is in unsupported currency %q", pr.Asset(), c)
+	}
+	quoted, err := fx.ParseCurrency(c)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("price of %s: %w", pr.Asset(), err)
+	}
+	return rates.At(quoted, currency, date)
 }
 
 type Portfolio struct {
-	id           uuid.UUID
-	userID       uuid.UUID
-	name         string
-	transactions []*Transaction
+	id              uuid.UUID
+	userID          uuid.UUID
+	name            string
+	costBasisMethod CostBasisMethod
+	overdraft       bool
+	shortSelling    bool
+	washSaleRule    bool
+	currency        string
+	precisions      Precisions
+	contracts       Contracts
+	bonds           Bonds
+	policy          RebalancePolicy
+	washSales       []WashSale
+	transactions    []*Transaction
+	// corporateActions are the actions of all assets, the ones of assets never held have no effect
+	corporateActions []*CorporateAction
+	// actions apply to this portfolio only, like the splits reported by its statements
+	actions []*CorporateAction
 }
 
 func NewPortfolio(id, userID uuid.UUID, name string, transactions []*Transaction) (*Portfolio, error) {
-	p := &Portfolio{}
+	p := &Portfolio{costBasisMethod: FIFO, overdraft: true, currency: DefaultCurrency, policy: RebalancePolicy{Targets: []Target{}}}
 	if err := p.setID(id); err != nil {
 		return nil, fmt.Errorf("can't create portfolio: %w", err)
 	}
@@ -41,28 +182,201 @@ func NewPortfolio(id, userID uuid.UUID, name string, transactions []*Transaction
 	return p, nil
 }
 
-func (p *Portfolio) Snapshot(date time.Time) *Snapshot {
-	assets := map[string]int{}
-	balance := 0.0
-	for _, t := range p.transactions {
-		if !t.date.After(date) {
-			assets[t.Asset()] += t.Quantity()
-			balance -= t.price * float64(t.quantity)
-		}
+func (p *Portfolio) Snapshot(date time.Time) (*Snapshot, error) {
+	book, err := p.replay(date)
+	if err != nil {
+		return nil, fmt.Errorf("can't take snapshot: %w", err)
 	}
-	return &Snapshot{
-		ID:      p.ID(),
-		Name:    p.Name(),
-		Assets:  assets,
-		Balance: balance,
+	positions := book.positions()
+	assets := Assets{}
+	for asset, pos := range positions {
+		assets[asset] = pos.Quantity
 	}
+	return &Snapshot{
+		ID:        p.ID(),
+		Name:      p.Name(),
+		Date:      date,
+		Currency:  p.currency,
+		Assets:    assets,
+		Positions: positions,
+		Cash:      book.cash,
+	}, nil
 }
 
 func (p *Portfolio) ApplyTransaction(t *Transaction) error {
-	if p.Snapshot(t.Date()).Assets[t.Asset()]+t.Quantity() < 0 {
-		return fmt.Errorf("can't apply transaction: asset quantity can't be less than zero")
+	if err := p.validate(t); err != nil {
+		return fmt.Errorf("can't apply transaction: %w", err)
+	}
+	transactions := p.transactions
+	p.transactions = append(transactions[:len(transactions):len(transactions)], t)
+	if err := p.check(); err != nil {
+		p.transactions = transactions
+		return fmt.Errorf("can't apply transaction: %w", err)
+	}
+	p.settle(t)
+	return nil
+}
+
+func (p *Portfolio) validate(t *Transaction) error {
+	if len(t.lots) > 0 && p.costBasisMethod != SpecificLot && (t.kind != TransferOut || p.costBasisMethod == AverageCost) {
+		return fmt.Errorf("lots can be selected only with %s cost basis method", SpecificLot)
+	}
+	if t.movesAsset() && !p.precisions.Fits(t.asset, t.quantity) {
+		return fmt.Errorf("quantity of %s can't have more than %d decimal places", t.asset, p.precisions.Of(t.asset))
+	}
+	currency := t.settledIn(p.currency)
+	for _, f := range t.fees {
+		if f.Currency != "" && f.Currency != currency {
+			return fmt.Errorf("fees must be paid in %s, the currency of the transaction", currency)
+		}
+	}
+	return nil
+}
+
+func (p *Portfolio) settle(t *Transaction) {
+	currency := t.settledIn(p.currency)
+	t.currency = currency
+	for i, f := range t.fees {
+		if f.Currency == "" {
+			t.fees[i].Currency = currency
+		}
+	}
+}
+
+func (p *Portfolio) replay(date time.Time) (*positionBook, error) {
+	book := p.newBook()
+	for _, e := range p.events() {
+		if e.date.After(date) {
+			break
+		}
+		if _, _, err := book.applyEvent(e); err != nil {
+			return nil, eventError(e, err)
+		}
+	}
+	return book, nil
+}
+
+func (p *Portfolio) check() error {
+	book := p.newBook()
+	for _, e := range p.events() {
+		currency, _, err := book.applyEvent(e)
+		if err != nil {
+			return eventError(e, err)
+		}
+		if balance := book.cash[currency]; !p.overdraft && balance.IsNegative() {
+			return fmt.Errorf("cash balance can't be less than zero: %s %s on %s", balance, currency, e.date.Format("2006-01-02"))
+		}
 	}
-	p.transactions = append(p.transactions, t)
+	return nil
+}
+
+func eventError(e event, err error) error {
+	if e.transaction == nil {
+		return err
+	}
+	t := e.transaction
+	return fmt.Errorf("%s %s on %s: %w", t.kind, t.id, t.date.Format("2006-01-02"), err)
+}
+
+func (p *Portfolio) newBook() *positionBook {
+	book := newPositionBook(p.costBasisMethod, p.precisions, p.currency)
+	book.shortSelling = p.shortSelling
+	book.contracts = p.contracts
+	book.bonds = p.bonds
+	for _, ws := range p.WashSales() {
+		if ws.PortfolioID == p.id {
+			book.washSales = append(book.washSales, ws)
+		}
+	}
+	return book
+}
+
+func (p *Portfolio) convert(rates *fx.Rates, amount decimal.Decimal, currency string, date time.Time) (decimal.Decimal, error) {
+	return rates.Convert(amount, currency, p.currency, date)
+}
+
+func (p *Portfolio) SetPrecisions(precisions Precisions) {
+	p.precisions = precisions
+}
+
+func (p *Portfolio) SetContracts(contracts Contracts) {
+	p.contracts = contracts
+}
+
+func (p *Portfolio) SetBonds(bonds Bonds) {
+	p.bonds = bonds
+}
+
+func (p *Portfolio) SetCorporateActions(actions []*CorporateAction) {
+	p.corporateActions = actions
+}
+
+func (p *Portfolio) SetPortfolioActions(actions []*CorporateAction) {
+	p.actions = actions
+}
+
+func (p *Portfolio) PortfolioActions() []*CorporateAction {
+	return p.actions
+}
+
+func (p *Portfolio) AddPortfolioAction(a *CorporateAction) error {
+	if d := findAction(p.allActions(), a); d != nil {
+		return fmt.Errorf("can't add %s of %s: it's already recorded as %s", a.kind, a.asset, d.id)
+	}
+	p.actions = append(append([]*CorporateAction{}, p.actions...), a)
+	return nil
+}
+
+func (p *Portfolio) allActions() []*CorporateAction {
+	if len(p.actions) == 0 {
+		return p.corporateActions
+	}
+	return append(append([]*CorporateAction{}, p.corporateActions...), p.actions...)
+}
+
+func (p *Portfolio) ChangeCostBasisMethod(method CostBasisMethod) error {
+	if _, err := ParseCostBasisMethod(string(method)); err != nil {
+		return fmt.Errorf("can't change cost basis method: %w", err)
+	}
+	p.costBasisMethod = method
+	return nil
+}
+
+func (p *Portfolio) ChangeOverdraft(allowed bool) error {
+	if !allowed && p.overdraft {
+		p.overdraft = false
+		if err := p.check(); err != nil {
+			p.overdraft = true
+			return fmt.Errorf("can't forbid overdraft: %w", err)
+		}
+	}
+	p.overdraft = allowed
+	return nil
+}
+
+func (p *Portfolio) ChangeShortSelling(allowed bool) error {
+	if !allowed && p.shortSelling {
+		p.shortSelling = false
+		if err := p.check(); err != nil {
+			p.shortSelling = true
+			return fmt.Errorf("can't forbid short selling: %w", err)
+		}
+	}
+	p.shortSelling = allowed
+	return nil
+}
+
+func (p *Portfolio) ChangeCurrency(currency string) error {
+	c, err := fx.ParseCurrency(currency)
+	if err != nil {
+		return fmt.Errorf("can't change currency: %w", err)
+	}
+	for _, t := range p.transactions {
+		if t.currency == "" {
+			t.currency = p.currency
+		}
+	}
+	p.currency = c
 	return nil
 }
 
@@ -99,6 +413,51 @@ func (p *Portfolio) Transactions() []*Transaction {
 	return p.transactions
 }
 
+func executed(transactions []*Transaction) []*Transaction {
+	res := make([]*Transaction, 0, len(transactions))
+	for _, t := range transactions {
+		if !t.pending {
+			res = append(res, t)
+		}
+	}
+	return res
+}
+
+func (p *Portfolio) Assets() []string {
+	res, seen := []string{}, map[string]bool{}
+	add := func(asset string) {
+		if asset != "" && !seen[asset] {
+			seen[asset] = true
+			res = append(res, asset)
+		}
+	}
+	for _, e := range p.events() {
+		switch {
+		case e.transaction != nil:
+			add(e.transaction.asset)
+		case e.action != nil && seen[e.action.asset]:
+			add(e.action.newAsset)
+		}
+	}
+	return res
+}
+
+func (p *Portfolio) CostBasisMethod() CostBasisMethod {
+	return p.costBasisMethod
+}
+
+func (p *Portfolio) Overdraft() bool {
+	return p.overdraft
+}
+
+func (p *Portfolio) ShortSelling() bool {
+	return p.shortSelling
+}
+
+func (p *Portfolio) Currency() string {
+	return p.currency
+}
+
 func (p *Portfolio) Name() string {
 	return p.name
 }
@@ -11,5 +11,7 @@ type PortfolioRepository interface {
 	GetAllPortfolios(ctx context.Context, userID uuid.UUID) ([]*Portfolio, error)
 	GetPortfolio(ctx context.Context, userID, id uuid.UUID) (*Portfolio, error)
 	UpdatePortfolio(ctx context.Context, userID, id uuid.UUID, updateFn func(p *Portfolio) error) error
+	// UpdatePortfolios updates several portfolios of the user atomically, updateFn gets them in the order of ids.
+	UpdatePortfolios(ctx context.Context, userID uuid.UUID, ids []uuid.UUID, updateFn func(ps []*Portfolio) error) error
 	DeletePortfolio(ctx context.Context, userID, id uuid.UUID) error
 }
@@ -0,0 +1,31 @@
+package portfolio
+
+import (
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+const DefaultPrecision int32 = 0
+
+const MaxPrecision int32 = 18
+
+type Precisions map[string]int32
+
+func (p Precisions) Of(asset string) int32 {
+	if places, ok := p[asset]; ok {
+		return places
+	}
+	return DefaultPrecision
+}
+
+func (p Precisions) Fits(asset string, quantity decimal.Decimal) bool {
+	return quantity.Equal(quantity.Truncate(p.Of(asset)))
+}
+
+func ValidatePrecision(places int32) error {
+	if places < 0 || places > MaxPrecision {
+		return fmt.Errorf("precision must be from 0 to %d decimal places", MaxPrecision)
+	}
+	return nil
+}
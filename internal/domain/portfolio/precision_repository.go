@@ -0,0 +1,10 @@
+package portfolio
+
+import (
+	"context"
+)
+
+type PrecisionRepository interface {
+	GetPrecisions(ctx context.Context) (Precisions, error)
+	SetPrecision(ctx context.Context, asset string, places int32) error
+}
@@ -0,0 +1,199 @@
+package portfolio
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/invine/portfolio/internal/domain/fx"
+	"github.com/shopspring/decimal"
+)
+
+type RealizedGain struct {
+	Asset         string
+	Currency      string
+	TransactionID uuid.UUID
+	LotID         uuid.UUID
+	Acquired      time.Time
+	Sold          time.Time
+	Quantity      decimal.Decimal
+	Proceeds      decimal.Decimal
+	Cost          decimal.Decimal
+	// Disallowed is the loss disallowed by wash sales, it's added to the cost of the replacement lots instead
+	Disallowed decimal.Decimal
+}
+
+func (g RealizedGain) Gain() decimal.Decimal {
+	return g.Proceeds.Sub(g.Cost).Add(g.Disallowed)
+}
+
+type AssetProfitLoss struct {
+	Asset        string
+	Currency     string
+	Quantity     decimal.Decimal
+	Cost         decimal.Decimal
+	Price        decimal.Decimal
+	MarketValue  decimal.Decimal
+	Realized     decimal.Decimal
+	Unrealized   decimal.Decimal
+	RealizedFX   decimal.Decimal
+	UnrealizedFX decimal.Decimal
+	Income       decimal.Decimal
+	BorrowFees   decimal.Decimal
+}
+
+type PeriodProfitLoss struct {
+	Period     string
+	Start      time.Time
+	End        time.Time
+	Realized   decimal.Decimal
+	RealizedFX decimal.Decimal
+	Income     decimal.Decimal
+	BorrowFees decimal.Decimal
+}
+
+type ProfitLoss struct {
+	ID           uuid.UUID
+	Name         string
+	Currency     string
+	Realized     decimal.Decimal
+	Unrealized   decimal.Decimal
+	RealizedFX   decimal.Decimal
+	UnrealizedFX decimal.Decimal
+	Income       decimal.Decimal
+	BorrowFees   decimal.Decimal
+	Assets       []AssetProfitLoss
+	Periods      []PeriodProfitLoss
+}
+
+func (p *Portfolio) RealizedGains(date time.Time) ([]RealizedGain, error) {
+	book, err := p.replay(date)
+	if err != nil {
+		return nil, err
+	}
+	return book.realized, nil
+}
+
+func (p *Portfolio) ProfitLoss(date time.Time, prices map[string]decimal.Decimal, period Period, rates *fx.Rates) (*ProfitLoss, error) {
+	book, err := p.replay(date)
+	if err != nil {
+		return nil, fmt.Errorf("can't calculate profit and loss: %w", err)
+	}
+
+	lastPrices := book.lastPrices
+	for asset, price := range prices {
+		lastPrices[asset] = price
+	}
+
+	assets := map[string]*AssetProfitLoss{}
+	assetPL := func(asset string) *AssetProfitLoss {
+		if _, ok := assets[asset]; !ok {
+			assets[asset] = &AssetProfitLoss{Asset: asset, Currency: book.currencyOf(asset), Price: lastPrices[asset]}
+		}
+		return assets[asset]
+	}
+
+	res := &ProfitLoss{
+		ID:       p.id,
+		Name:     p.name,
+		Currency: p.currency,
+		Assets:   []AssetProfitLoss{},
+		Periods:  []PeriodProfitLoss{},
+	}
+	periods := map[string]*PeriodProfitLoss{}
+	periodPL := func(date time.Time) *PeriodProfitLoss {
+		key := period.Key(date)
+		if _, ok := periods[key]; !ok {
+			periods[key] = &PeriodProfitLoss{Period: key, Start: period.Start(date), End: period.Next(date)}
+		}
+		return periods[key]
+	}
+
+	for _, g := range book.realized {
+		gain, fxGain, err := p.splitGain(rates, g.Proceeds, g.Cost.Sub(g.Disallowed), g.Currency, g.Acquired, g.Sold)
+		if err != nil {
+			return nil, fmt.Errorf("can't calculate realized gain of %s: %w", g.Asset, err)
+		}
+		a, pp := assetPL(g.Asset), periodPL(g.Sold)
+		a.Realized = a.Realized.Add(gain)
+		a.RealizedFX = a.RealizedFX.Add(fxGain)
+		pp.Realized = pp.Realized.Add(gain)
+		pp.RealizedFX = pp.RealizedFX.Add(fxGain)
+		res.Realized = res.Realized.Add(gain)
+		res.RealizedFX = res.RealizedFX.Add(fxGain)
+	}
+	for _, t := range p.transactions {
+		if t.date.After(date) || t.kind != BorrowFee {
+			continue
+		}
+		amount, err := p.convert(rates, t.amount, t.settledIn(p.currency), t.date)
+		if err != nil {
+			return nil, fmt.Errorf("can't convert borrow fee: %w", err)
+		}
+		a, pp := assetPL(t.asset), periodPL(t.date)
+		a.BorrowFees = a.BorrowFees.Add(amount)
+		pp.BorrowFees = pp.BorrowFees.Add(amount)
+		res.BorrowFees = res.BorrowFees.Add(amount)
+	}
+	for _, t := range p.withPayments(executed(p.transactions)) {
+		if t.date.After(date) || t.income().IsZero() {
+			continue
+		}
+		amount, err := p.convert(rates, t.income(), t.settledIn(p.currency), t.date)
+		if err != nil {
+			return nil, fmt.Errorf("can't convert income: %w", err)
+		}
+		if t.asset != "" {
+			a := assetPL(t.asset)
+			a.Income = a.Income.Add(amount)
+		}
+		pp := periodPL(t.date)
+		pp.Income = pp.Income.Add(amount)
+		res.Income = res.Income.Add(amount)
+	}
+	for asset, pos := range book.positions() {
+		a := assetPL(asset)
+		a.Quantity = pos.Quantity
+		for _, l := range pos.Lots {
+			cost, err := p.convert(rates, l.Cost(), pos.Currency, l.Date)
+			if err != nil {
+				return nil, fmt.Errorf("can't calculate unrealized gain of %s: %w", asset, err)
+			}
+			value := a.Price.Mul(l.Quantity).Mul(pos.Multiplier)
+			gain, fxGain, err := p.splitGain(rates, value, l.Cost(), pos.Currency, l.Date, date)
+			if err != nil {
+				return nil, fmt.Errorf("can't calculate unrealized gain of %s: %w", asset, err)
+			}
+			a.Cost = a.Cost.Add(cost)
+			a.MarketValue = a.MarketValue.Add(cost.Add(gain).Add(fxGain))
+			a.Unrealized = a.Unrealized.Add(gain)
+			a.UnrealizedFX = a.UnrealizedFX.Add(fxGain)
+		}
+		res.Unrealized = res.Unrealized.Add(a.Unrealized)
+		res.UnrealizedFX = res.UnrealizedFX.Add(a.UnrealizedFX)
+	}
+
+	for _, a := range assets {
+		res.Assets = append(res.Assets, *a)
+	}
+	sort.Slice(res.Assets, func(i, j int) bool { return res.Assets[i].Asset < res.Assets[j].Asset })
+	for _, pp := range periods {
+		res.Periods = append(res.Periods, *pp)
+	}
+	sort.Slice(res.Periods, func(i, j int) bool { return res.Periods[i].Start.Before(res.Periods[j].Start) })
+
+	return res, nil
+}
+
+func (p *Portfolio) splitGain(rates *fx.Rates, value, cost decimal.Decimal, currency string, paid, valued time.Time) (decimal.Decimal, decimal.Decimal, error) {
+	rate, err := rates.At(currency, p.currency, valued)
+	if err != nil {
+		return decimal.Zero, decimal.Zero, err
+	}
+	paidRate, err := rates.At(currency, p.currency, paid)
+	if err != nil {
+		return decimal.Zero, decimal.Zero, err
+	}
+	return value.Sub(cost).Mul(rate), cost.Mul(rate.Sub(paidRate)), nil
+}
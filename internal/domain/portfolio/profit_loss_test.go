@@ -0,0 +1,126 @@
+package portfolio
+
+import (
+	"testing"
+
+	"github.com/invine/portfolio/internal/domain/fx"
+	"github.com/shopspring/decimal"
+)
+
+func newIncome(n int, date string, kind Kind, asset, amount string) *Transaction {
+	t, err := NewIncomeTransaction(id(n), day(date), kind, asset, dec(amount))
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func inCurrency(t *Transaction, currency string) *Transaction {
+	if err := t.SetCurrency(currency); err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func newRate(base, quote, date, rate string) *fx.Rate {
+	r, err := fx.NewRate(base, quote, day(date), dec(rate), "test")
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+func TestProfitLoss(t *testing.T) {
+	tests := []struct {
+		name         string
+		shortSelling bool
+		transactions []*Transaction
+		prices       map[string]decimal.Decimal
+		rates        *fx.Rates
+		realized     string
+		unrealized   string
+		unrealizedFX string
+		income       string
+		periods      int
+	}{
+		{
+			name:         "realized and unrealized",
+			transactions: []*Transaction{newTrade(1, "2021-01-04", "AAPL", "10", "100"), newTrade(2, "2021-02-04", "AAPL", "-4", "130")},
+			prices:       map[string]decimal.Decimal{"AAPL": dec("150")},
+			realized:     "120",
+			unrealized:   "300",
+			unrealizedFX: "0",
+			income:       "0",
+			periods:      1,
+		},
+		{
+			name:         "last trade price without a price",
+			transactions: []*Transaction{newTrade(1, "2021-01-04", "AAPL", "10", "100"), newTrade(2, "2021-01-05", "AAPL", "5", "110")},
+			realized:     "0",
+			unrealized:   "100",
+			unrealizedFX: "0",
+			income:       "0",
+			periods:      0,
+		},
+		{
+			name: "income",
+			transactions: []*Transaction{
+				newTrade(1, "2021-01-04", "AAPL", "10", "100"),
+				newIncome(2, "2021-02-10", Dividend, "AAPL", "20"),
+				newIncome(3, "2021-03-10", Dividend, "AAPL", "25"),
+			},
+			prices:       map[string]decimal.Decimal{"AAPL": dec("100")},
+			realized:     "0",
+			unrealized:   "0",
+			unrealizedFX: "0",
+			income:       "45",
+			periods:      2,
+		},
+		{
+			name:         "short position",
+			shortSelling: true,
+			transactions: []*Transaction{newTrade(1, "2021-01-04", "TSLA", "-10", "50"), newTrade(2, "2021-01-05", "TSLA", "4", "60")},
+			prices:       map[string]decimal.Decimal{"TSLA": dec("55")},
+			realized:     "-40",
+			unrealized:   "-30",
+			unrealizedFX: "0",
+			income:       "0",
+			periods:      1,
+		},
+		{
+			name:         "currency gain",
+			transactions: []*Transaction{inCurrency(newTrade(1, "2021-01-04", "SAP", "10", "100"), "EUR")},
+			prices:       map[string]decimal.Decimal{"SAP": dec("110")},
+			rates:        fx.NewRates([]*fx.Rate{newRate("EUR", "USD", "2021-01-01", "1.1"), newRate("EUR", "USD", "2021-06-01", "1.2")}),
+			realized:     "0",
+			unrealized:   "120",
+			unrealizedFX: "100",
+			income:       "0",
+			periods:      0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newTestPortfolio(t, 1)
+			if err := p.ChangeShortSelling(tt.shortSelling); err != nil {
+				t.Fatal(err)
+			}
+			applyAll(t, p, tt.transactions...)
+
+			pl, err := p.ProfitLoss(day("2021-12-31"), tt.prices, Month, tt.rates)
+			if err != nil {
+				t.Fatalf("ProfitLoss() error = %v", err)
+			}
+			got := []decimal.Decimal{pl.Realized, pl.Unrealized, pl.UnrealizedFX, pl.Income}
+			want := []string{tt.realized, tt.unrealized, tt.unrealizedFX, tt.income}
+			for i, name := range []string{"realized", "unrealized", "unrealized fx", "income"} {
+				if !got[i].Equal(dec(want[i])) {
+					t.Errorf("ProfitLoss() %s = %s, want %s", name, got[i], want[i])
+				}
+			}
+			if len(pl.Periods) != tt.periods {
+				t.Errorf("ProfitLoss() has %d periods, want %d", len(pl.Periods), tt.periods)
+			}
+		})
+	}
+}
@@ -0,0 +1,330 @@
+package portfolio
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/invine/portfolio/internal/domain/fx"
+	"github.com/invine/portfolio/internal/domain/price"
+	"github.com/shopspring/decimal"
+)
+
+type Target struct {
+	Key    AllocationKey
+	Name   string
+	Weight decimal.Decimal
+}
+
+type RebalancePolicy struct {
+	Targets   []Target
+	Tolerance decimal.Decimal
+	MinTrade  decimal.Decimal
+}
+
+func (policy RebalancePolicy) validate() (RebalancePolicy, error) {
+	res := RebalancePolicy{Targets: []Target{}, Tolerance: policy.Tolerance, MinTrade: policy.MinTrade}
+	if policy.Tolerance.IsNegative() || policy.Tolerance.GreaterThanOrEqual(decimal.NewFromInt(1)) {
+		return RebalancePolicy{}, fmt.Errorf("tolerance must be from 0 to 1")
+	}
+	if policy.MinTrade.IsNegative() {
+		return RebalancePolicy{}, fmt.Errorf("minimum trade can't be negative")
+	}
+
+	sum := decimal.Zero
+	seen := map[string]bool{}
+	for _, t := range policy.Targets {
+		name := strings.TrimSpace(t.Name)
+		switch t.Key {
+		case ByAsset:
+		case ByClass:
+			name = strings.ToLower(name)
+			if name == CashClass {
+				return RebalancePolicy{}, fmt.Errorf("cash has the weight the targets leave")
+			}
+		default:
+			return RebalancePolicy{}, fmt.Errorf("targets can be set only per %s or per %s", ByAsset, ByClass)
+		}
+		if name == "" {
+			return RebalancePolicy{}, fmt.Errorf("target name can't be empty")
+		}
+		if len(res.Targets) > 0 && res.Targets[0].Key != t.Key {
+			return RebalancePolicy{}, fmt.Errorf("targets must be all per %s or all per %s", ByAsset, ByClass)
+		}
+		if t.Weight.IsNegative() || t.Weight.GreaterThan(decimal.NewFromInt(1)) {
+			return RebalancePolicy{}, fmt.Errorf("weight of %s must be from 0 to 1", name)
+		}
+		if seen[name] {
+			return RebalancePolicy{}, fmt.Errorf("target %s is set twice", name)
+		}
+		seen[name] = true
+		sum = sum.Add(t.Weight)
+		res.Targets = append(res.Targets, Target{Key: t.Key, Name: name, Weight: t.Weight})
+	}
+	if sum.GreaterThan(decimal.NewFromInt(1)) {
+		return RebalancePolicy{}, fmt.Errorf("target weights add up to %s, more than 1", sum.String())
+	}
+	return res, nil
+}
+
+func (p *Portfolio) SetRebalancePolicy(policy RebalancePolicy) error {
+	res, err := policy.validate()
+	if err != nil {
+		return fmt.Errorf("can't set rebalance policy: %w", err)
+	}
+	p.policy = res
+	return nil
+}
+
+func (p *Portfolio) RebalancePolicy() RebalancePolicy {
+	res := p.policy
+	res.Targets = append([]Target{}, p.policy.Targets...)
+	return res
+}
+
+type TargetDrift struct {
+	Target    Target
+	Value     decimal.Decimal
+	Weight    float64
+	Drift     float64
+	Rebalance bool
+	Unfilled  decimal.Decimal
+}
+
+type Order struct {
+	Kind     Kind
+	Asset    string
+	Quantity decimal.Decimal
+	Price    decimal.Decimal
+	Currency string
+	Value    decimal.Decimal
+}
+
+func (o Order) Transaction(id uuid.UUID, date time.Time) (*Transaction, error) {
+	quantity := o.Quantity
+	if o.Kind == Sell {
+		quantity = quantity.Neg()
+	}
+	t, err := NewTransaction(id, date, o.Asset, quantity, o.Price)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.SetCurrency(o.Currency); err != nil {
+		return nil, fmt.Errorf("can't create transaction: %w", err)
+	}
+	return t, nil
+}
+
+type Rebalance struct {
+	ID        uuid.UUID
+	Name      string
+	Date      time.Time
+	Currency  string
+	Value     decimal.Decimal
+	Cash      decimal.Decimal
+	CashAfter decimal.Decimal
+	Tolerance decimal.Decimal
+	Targets   []TargetDrift
+	Orders    []Order
+}
+
+type trade struct {
+	drift    *TargetDrift
+	asset    string
+	currency string
+	price    decimal.Decimal
+	rate     decimal.Decimal
+	held     decimal.Decimal
+	value    decimal.Decimal
+}
+
+func (p *Portfolio) Rebalance(s *Snapshot, prices map[string]*price.Price, classes map[string]Classification, rates *fx.Rates) (*Rebalance, error) {
+	holdings, err := s.holdings(classes, rates)
+	if err != nil {
+		return nil, fmt.Errorf("can't rebalance portfolio: %w", err)
+	}
+
+	key := ByAsset
+	if len(p.policy.Targets) > 0 {
+		key = p.policy.Targets[0].Key
+	}
+	drifts := []*TargetDrift{}
+	byName := map[string]*TargetDrift{}
+	for _, t := range p.policy.Targets {
+		d := &TargetDrift{Target: t, Value: decimal.Zero, Unfilled: decimal.Zero}
+		drifts = append(drifts, d)
+		byName[t.Name] = d
+	}
+	untargeted := []*TargetDrift{}
+	members := map[string][]holding{}
+	total := decimal.Zero
+	for _, h := range holdings {
+		total = total.Add(h.value)
+		if h.cash {
+			continue
+		}
+		name := h.attribute(key)
+		d, ok := byName[name]
+		if !ok {
+			d = &TargetDrift{Target: Target{Key: key, Name: name, Weight: decimal.Zero}, Value: decimal.Zero, Unfilled: decimal.Zero}
+			untargeted = append(untargeted, d)
+			byName[name] = d
+		}
+		d.Value = d.Value.Add(h.value)
+		members[name] = append(members[name], h)
+	}
+	sort.Slice(untargeted, func(i, j int) bool { return untargeted[i].Target.Name < untargeted[j].Target.Name })
+	drifts = append(drifts, untargeted...)
+
+	trades := []*trade{}
+	wanted := map[*TargetDrift]decimal.Decimal{}
+	for _, d := range drifts {
+		// weights of a portfolio that isn't worth anything can't be rebalanced
+		if !total.IsPositive() {
+			continue
+		}
+		weight := d.Value.Div(total)
+		d.Weight = weight.InexactFloat64()
+		d.Drift = weight.Sub(d.Target.Weight).InexactFloat64()
+		d.Rebalance = weight.Sub(d.Target.Weight).Abs().GreaterThan(p.policy.Tolerance)
+		if !d.Rebalance {
+			continue
+		}
+		want := d.Target.Weight.Mul(total).Sub(d.Value)
+		wanted[d] = want.Abs()
+
+		assets := []holding{}
+		if key == ByAsset {
+			assets = append(assets, holding{asset: d.Target.Name, value: d.Value})
+		} else {
+			assets = append(assets, members[d.Target.Name]...)
+			sort.Slice(assets, func(i, j int) bool { return assets[i].asset < assets[j].asset })
+		}
+		for _, h := range assets {
+			share := decimal.NewFromInt(1).Div(decimal.NewFromInt(int64(len(assets))))
+			if !d.Value.IsZero() {
+				share = h.value.Div(d.Value)
+			}
+			t, err := p.newTrade(s, d, h.asset, want.Mul(share), prices, classes, rates)
+			if err != nil {
+				return nil, fmt.Errorf("can't rebalance portfolio: %w", err)
+			}
+			if t != nil {
+				trades = append(trades, t)
+			}
+		}
+	}
+
+	r := &Rebalance{
+		ID:        s.ID,
+		Name:      s.Name,
+		Date:      s.Date,
+		Currency:  s.Currency,
+		Value:     total,
+		Cash:      s.Balance,
+		Tolerance: p.policy.Tolerance,
+		Targets:   []TargetDrift{},
+		Orders:    []Order{},
+	}
+
+	filled := map[*TargetDrift]decimal.Decimal{}
+	available := s.Balance
+	for _, t := range trades {
+		if !t.value.IsNegative() {
+			continue
+		}
+		quantity := t.value.Neg().Div(t.price.Mul(t.rate)).Truncate(p.precisions.Of(t.asset))
+		if quantity.GreaterThan(t.held) {
+			quantity = t.held
+		}
+		if o, ok := p.order(Sell, t, quantity); ok {
+			r.Orders = append(r.Orders, o)
+			filled[t.drift] = filled[t.drift].Add(o.Value)
+			available = available.Add(o.Value)
+		}
+	}
+	buys := decimal.Zero
+	for _, t := range trades {
+		if t.value.IsPositive() {
+			buys = buys.Add(t.value)
+		}
+	}
+	scale := decimal.NewFromInt(1)
+	if !available.IsPositive() {
+		scale = decimal.Zero
+	} else if buys.GreaterThan(available) {
+		scale = available.Div(buys)
+	}
+	for _, t := range trades {
+		if !t.value.IsPositive() {
+			continue
+		}
+		quantity := t.value.Mul(scale).Div(t.price.Mul(t.rate)).Truncate(p.precisions.Of(t.asset))
+		if o, ok := p.order(Buy, t, quantity); ok {
+			r.Orders = append(r.Orders, o)
+			filled[t.drift] = filled[t.drift].Add(o.Value)
+			available = available.Sub(o.Value)
+		}
+	}
+	r.CashAfter = available
+
+	for _, d := range drifts {
+		if want, ok := wanted[d]; ok {
+			d.Unfilled = want.Sub(filled[d])
+			if d.Unfilled.IsNegative() {
+				d.Unfilled = decimal.Zero
+			}
+		}
+		r.Targets = append(r.Targets, *d)
+	}
+	return r, nil
+}
+
+func (p *Portfolio) newTrade(s *Snapshot, d *TargetDrift, asset string, value decimal.Decimal, prices map[string]*price.Price, classes map[string]Classification, rates *fx.Rates) (*trade, error) {
+	t := &trade{drift: d, asset: asset, value: value, held: decimal.Zero}
+	if pos, ok := s.Positions[asset]; ok {
+		t.currency = pos.Currency
+		t.price = pos.Price
+		t.held = pos.Quantity
+	} else {
+		pr, ok := prices[asset]
+		if !ok || value.IsNegative() {
+			return nil, nil
+		}
+		t.currency = classes[asset].Currency
+		if t.currency == "" {
+			t.currency = s.Currency
+		}
+		rate, err := priceRate(pr, t.currency, rates, s.Date)
+		if err != nil {
+			return nil, fmt.Errorf("can't price %s: %w", asset, err)
+		}
+		t.price = pr.Close().Mul(rate)
+	}
+	if !t.price.IsPositive() {
+		return nil, nil
+	}
+	rate, err := rates.At(t.currency, s.Currency, s.Date)
+	if err != nil {
+		return nil, fmt.Errorf("can't price %s: %w", t.asset, err)
+	}
+	t.rate = rate
+	return t, nil
+}
+
+func (p *Portfolio) order(kind Kind, t *trade, quantity decimal.Decimal) (Order, bool) {
+	value := quantity.Mul(t.price).Mul(t.rate)
+	if !quantity.IsPositive() || value.LessThan(p.policy.MinTrade) {
+		return Order{}, false
+	}
+	return Order{
+		Kind:     kind,
+		Asset:    t.asset,
+		Quantity: quantity,
+		Price:    t.price,
+		Currency: t.currency,
+		Value:    value,
+	}, true
+}
@@ -5,17 +5,95 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/invine/portfolio/internal/domain/fx"
+	"github.com/shopspring/decimal"
 )
 
+type Kind string
+
+const (
+	Buy         Kind = "buy"
+	Sell        Kind = "sell"
+	Dividend    Kind = "dividend"
+	Interest    Kind = "interest"
+	Coupon      Kind = "coupon"
+	CapitalGain Kind = "capital_gain"
+	Deposit     Kind = "deposit"
+	Withdrawal  Kind = "withdrawal"
+	TransferIn  Kind = "transfer_in"
+	TransferOut Kind = "transfer_out"
+	BorrowFee   Kind = "borrow_fee"
+	AccountFee  Kind = "account_fee"
+	Exercise    Kind = "exercise"
+	Assignment  Kind = "assignment"
+	Expiry      Kind = "expiry"
+	Redemption  Kind = "redemption"
+)
+
+func ParseKind(s string) (Kind, error) {
+	k := Kind(s)
+	switch k {
+	case Buy, Sell, Dividend, Interest, Coupon, CapitalGain, Deposit, Withdrawal, TransferIn, TransferOut, BorrowFee, AccountFee, Exercise, Assignment, Expiry, Redemption:
+		return k, nil
+	}
+	return "", fmt.Errorf("unknown transaction kind %q", s)
+}
+
+func (k Kind) IsTrade() bool {
+	return k == Buy || k == Sell
+}
+
+func (k Kind) IsIncome() bool {
+	switch k {
+	case Dividend, Interest, Coupon, CapitalGain:
+		return true
+	}
+	return false
+}
+
+func (k Kind) IsCashFlow() bool {
+	return k == Deposit || k == Withdrawal
+}
+
+func (k Kind) IsTransfer() bool {
+	return k == TransferIn || k == TransferOut
+}
+
+func (k Kind) IsOptionSettlement() bool {
+	return k == Exercise || k == Assignment || k == Expiry
+}
+
+type TransferLink struct {
+	PortfolioID   uuid.UUID
+	TransactionID uuid.UUID
+}
+
 type Transaction struct {
-	id       uuid.UUID
-	date     time.Time
-	asset    string
-	quantity int
-	price    float64
+	id           uuid.UUID
+	date         time.Time
+	kind         Kind
+	asset        string
+	instrumentID uuid.UUID
+	quantity     decimal.Decimal
+	price        decimal.Decimal
+	amount       decimal.Decimal
+	currency     string
+	// multiplier is the number of units of the underlying in a contract of a derivative, zero for other assets
+	multiplier decimal.Decimal
+	// premium is the cost of the option lots closed by the exercise or assignment a trade delivers the underlying for
+	premium decimal.Decimal
+	// accrued is the interest accrued on the bonds of a trade, paid by the buyer to the seller on top of the price
+	accrued decimal.Decimal
+	lots    []LotSelection
+	fees    []Fee
+	// received are the lots a transfer-in brings with their acquisition dates and costs
+	received []Lot
+	link     TransferLink
+	// pending orders are placed but not filled yet, they don't change holdings or cash
+	pending bool
 }
 
-func NewTransaction(id uuid.UUID, date time.Time, asset string, quantity int, price float64) (*Transaction, error) {
+func NewTransaction(id uuid.UUID, date time.Time, asset string, quantity, price decimal.Decimal) (*Transaction, error) {
 	t := &Transaction{}
 
 	if err := t.setID(id); err != nil {
@@ -37,7 +115,181 @@ func NewTransaction(id uuid.UUID, date time.Time, asset string, quantity int, pr
 	return t, nil
 }
 
-func (t *Transaction) UpdateTransaction(date time.Time, asset string, quantity int, price float64) error {
+func NewIncomeTransaction(id uuid.UUID, date time.Time, kind Kind, asset string, amount decimal.Decimal) (*Transaction, error) {
+	t := &Transaction{}
+
+	if err := t.setID(id); err != nil {
+		return nil, fmt.Errorf("can't create transaction: %w", err)
+	}
+	if err := t.setDate(date); err != nil {
+		return nil, fmt.Errorf("can't create transaction: %w", err)
+	}
+	if !kind.IsIncome() {
+		return nil, fmt.Errorf("can't create transaction: %s isn't an income", kind)
+	}
+	t.kind = kind
+	if asset != "" || kind != Interest {
+		if err := t.setAsset(asset); err != nil {
+			return nil, fmt.Errorf("can't create transaction: %w", err)
+		}
+	}
+	if err := t.setAmount(amount); err != nil {
+		return nil, fmt.Errorf("can't create transaction: %w", err)
+	}
+
+	return t, nil
+}
+
+func NewCashTransaction(id uuid.UUID, date time.Time, kind Kind, amount decimal.Decimal) (*Transaction, error) {
+	t := &Transaction{}
+
+	if err := t.setID(id); err != nil {
+		return nil, fmt.Errorf("can't create transaction: %w", err)
+	}
+	if err := t.setDate(date); err != nil {
+		return nil, fmt.Errorf("can't create transaction: %w", err)
+	}
+	if !kind.IsCashFlow() {
+		return nil, fmt.Errorf("can't create transaction: %s isn't a deposit or a withdrawal", kind)
+	}
+	t.kind = kind
+	if err := t.setAmount(amount); err != nil {
+		return nil, fmt.Errorf("can't create transaction: %w", err)
+	}
+
+	return t, nil
+}
+
+func NewBorrowFee(id uuid.UUID, date time.Time, asset string, amount decimal.Decimal) (*Transaction, error) {
+	t := &Transaction{kind: BorrowFee}
+
+	if err := t.setID(id); err != nil {
+		return nil, fmt.Errorf("can't create transaction: %w", err)
+	}
+	if err := t.setDate(date); err != nil {
+		return nil, fmt.Errorf("can't create transaction: %w", err)
+	}
+	if err := t.setAsset(asset); err != nil {
+		return nil, fmt.Errorf("can't create transaction: %w", err)
+	}
+	if err := t.setAmount(amount); err != nil {
+		return nil, fmt.Errorf("can't create transaction: %w", err)
+	}
+
+	return t, nil
+}
+
+func NewAccountFee(id uuid.UUID, date time.Time, asset string, amount decimal.Decimal) (*Transaction, error) {
+	t := &Transaction{kind: AccountFee}
+
+	if err := t.setID(id); err != nil {
+		return nil, fmt.Errorf("can't create transaction: %w", err)
+	}
+	if err := t.setDate(date); err != nil {
+		return nil, fmt.Errorf("can't create transaction: %w", err)
+	}
+	if asset != "" {
+		if err := t.setAsset(asset); err != nil {
+			return nil, fmt.Errorf("can't create transaction: %w", err)
+		}
+	}
+	if err := t.setAmount(amount); err != nil {
+		return nil, fmt.Errorf("can't create transaction: %w", err)
+	}
+
+	return t, nil
+}
+
+func NewTransfer(id uuid.UUID, date time.Time, kind Kind, asset string, quantity, price, amount decimal.Decimal) (*Transaction, error) {
+	t := &Transaction{}
+
+	if err := t.setID(id); err != nil {
+		return nil, fmt.Errorf("can't create transaction: %w", err)
+	}
+	if err := t.setDate(date); err != nil {
+		return nil, fmt.Errorf("can't create transaction: %w", err)
+	}
+	if !kind.IsTransfer() {
+		return nil, fmt.Errorf("can't create transaction: %s isn't a transfer", kind)
+	}
+	if asset == "" {
+		t.kind = kind
+		if err := t.setAmount(amount); err != nil {
+			return nil, fmt.Errorf("can't create transaction: %w", err)
+		}
+		return t, nil
+	}
+
+	if err := t.setAsset(asset); err != nil {
+		return nil, fmt.Errorf("can't create transaction: %w", err)
+	}
+	if !quantity.IsPositive() {
+		return nil, fmt.Errorf("can't create transaction: quantity must be positive")
+	}
+	if kind == TransferOut {
+		quantity = quantity.Neg()
+	}
+	t.quantity = quantity
+	t.kind = kind
+	if err := t.setPrice(price); err != nil {
+		return nil, fmt.Errorf("can't create transaction: %w", err)
+	}
+	return t, nil
+}
+
+func NewOptionSettlement(id uuid.UUID, date time.Time, kind Kind, asset string, quantity decimal.Decimal) (*Transaction, error) {
+	t := &Transaction{}
+
+	if err := t.setID(id); err != nil {
+		return nil, fmt.Errorf("can't create transaction: %w", err)
+	}
+	if err := t.setDate(date); err != nil {
+		return nil, fmt.Errorf("can't create transaction: %w", err)
+	}
+	if err := t.setAsset(asset); err != nil {
+		return nil, fmt.Errorf("can't create transaction: %w", err)
+	}
+	switch {
+	case !kind.IsOptionSettlement():
+		return nil, fmt.Errorf("can't create transaction: %s isn't an option settlement", kind)
+	case quantity.IsZero():
+		return nil, fmt.Errorf("can't create transaction: quantity can't be zero")
+	case kind == Exercise && quantity.IsPositive():
+		return nil, fmt.Errorf("can't create transaction: exercise must close long contracts")
+	case kind == Assignment && quantity.IsNegative():
+		return nil, fmt.Errorf("can't create transaction: assignment must close short contracts")
+	}
+	t.kind = kind
+	t.quantity = quantity
+	return t, nil
+}
+
+func NewRedemption(id uuid.UUID, date time.Time, asset string, quantity, price decimal.Decimal) (*Transaction, error) {
+	t := &Transaction{kind: Redemption}
+
+	if err := t.setID(id); err != nil {
+		return nil, fmt.Errorf("can't create transaction: %w", err)
+	}
+	if err := t.setDate(date); err != nil {
+		return nil, fmt.Errorf("can't create transaction: %w", err)
+	}
+	if err := t.setAsset(asset); err != nil {
+		return nil, fmt.Errorf("can't create transaction: %w", err)
+	}
+	if !quantity.IsPositive() {
+		return nil, fmt.Errorf("can't create transaction: quantity must be positive")
+	}
+	t.quantity = quantity.Neg()
+	if err := t.setPrice(price); err != nil {
+		return nil, fmt.Errorf("can't create transaction: %w", err)
+	}
+	return t, nil
+}
+
+func (t *Transaction) UpdateTransaction(date time.Time, asset string, quantity, price decimal.Decimal) error {
+	if !t.kind.IsTrade() {
+		return fmt.Errorf("can't update transaction: %s isn't a trade", t.kind)
+	}
 	if err := t.setDate(date); err != nil {
 		return fmt.Errorf("can't update transaction: %w", err)
 	}
@@ -53,6 +305,200 @@ func (t *Transaction) UpdateTransaction(date time.Time, asset string, quantity i
 	return nil
 }
 
+func (t *Transaction) SetPending(pending bool) error {
+	if pending && !t.kind.IsTrade() {
+		return fmt.Errorf("can't set pending: %s isn't a trade", t.kind)
+	}
+	t.pending = pending
+	return nil
+}
+
+func (t *Transaction) SelectLots(lots []LotSelection) error {
+	if len(lots) == 0 {
+		t.lots = nil
+		return nil
+	}
+	if t.kind != Sell && !(t.kind == TransferOut && t.asset != "") {
+		return fmt.Errorf("can't select lots: lots can be selected only for sell transactions and transfers of securities")
+	}
+	total := decimal.Zero
+	for _, l := range lots {
+		if l.TransactionID == uuid.Nil {
+			return fmt.Errorf("can't select lots: lot id can't be empty")
+		}
+		if !l.Quantity.IsPositive() {
+			return fmt.Errorf("can't select lots: lot quantity must be positive")
+		}
+		total = total.Add(l.Quantity)
+	}
+	if !total.Equal(t.quantity.Neg()) {
+		return fmt.Errorf("can't select lots: selected quantity %s doesn't match sold quantity %s", total, t.quantity.Neg())
+	}
+	t.lots = lots
+	return nil
+}
+
+func (t *Transaction) ReceiveLots(lots []Lot) error {
+	if len(lots) == 0 {
+		t.received = nil
+		return nil
+	}
+	if t.kind != TransferIn || t.asset == "" {
+		return fmt.Errorf("can't receive lots: lots can be received only by transfers of securities")
+	}
+	total := decimal.Zero
+	for _, l := range lots {
+		if l.TransactionID == uuid.Nil {
+			return fmt.Errorf("can't receive lots: lot id can't be empty")
+		}
+		if l.Date.IsZero() {
+			return fmt.Errorf("can't receive lots: lot date can't be empty")
+		}
+		if !l.Quantity.IsPositive() {
+			return fmt.Errorf("can't receive lots: lot quantity must be positive")
+		}
+		if l.Price.IsNegative() {
+			return fmt.Errorf("can't receive lots: lot price can't be negative")
+		}
+		total = total.Add(l.Quantity)
+	}
+	if !total.Equal(t.quantity) {
+		return fmt.Errorf("can't receive lots: received quantity %s doesn't match transferred quantity %s", total, t.quantity)
+	}
+	t.received = lots
+	return nil
+}
+
+func (t *Transaction) LinkTransfer(link TransferLink) error {
+	if !t.kind.IsTransfer() {
+		return fmt.Errorf("can't link transfer: %s isn't a transfer", t.kind)
+	}
+	t.link = link
+	return nil
+}
+
+func (t *Transaction) SetMultiplier(multiplier decimal.Decimal) error {
+	if !t.movesAsset() {
+		return fmt.Errorf("can't set multiplier: %s doesn't move an asset", t.kind)
+	}
+	if !multiplier.IsPositive() {
+		return fmt.Errorf("can't set multiplier: multiplier must be positive")
+	}
+	t.multiplier = multiplier
+	return nil
+}
+
+func (t *Transaction) Multiplier() decimal.Decimal {
+	if t.multiplier.IsZero() {
+		return decimal.NewFromInt(1)
+	}
+	return t.multiplier
+}
+
+func (t *Transaction) SetPremium(premium decimal.Decimal) error {
+	if !t.kind.IsTrade() && !premium.IsZero() {
+		return fmt.Errorf("can't set premium: %s isn't a trade", t.kind)
+	}
+	t.premium = premium
+	return nil
+}
+
+func (t *Transaction) Premium() decimal.Decimal {
+	return t.premium
+}
+
+func (t *Transaction) SetAccrued(accrued decimal.Decimal) error {
+	if !t.kind.IsTrade() && !accrued.IsZero() {
+		return fmt.Errorf("can't set accrued interest: %s isn't a trade", t.kind)
+	}
+	if accrued.IsNegative() {
+		return fmt.Errorf("can't set accrued interest: accrued interest can't be negative")
+	}
+	t.accrued = accrued
+	return nil
+}
+
+func (t *Transaction) Accrued() decimal.Decimal {
+	return t.accrued
+}
+
+func (t *Transaction) SetFees(fees []Fee) error {
+	res := make([]Fee, 0, len(fees))
+	for _, f := range fees {
+		if _, err := ParseFeeType(string(f.Type)); err != nil {
+			return fmt.Errorf("can't set fees: %w", err)
+		}
+		if !f.Amount.IsPositive() {
+			return fmt.Errorf("can't set fees: %s amount must be positive", f.Type)
+		}
+		if f.Currency != "" {
+			c, err := fx.ParseCurrency(f.Currency)
+			if err != nil {
+				return fmt.Errorf("can't set fees: %w", err)
+			}
+			f.Currency = c
+		}
+		res = append(res, f)
+	}
+	t.fees = res
+	if len(fees) == 0 {
+		t.fees = nil
+	}
+	return nil
+}
+
+func (t *Transaction) SetCurrency(currency string) error {
+	if currency == "" {
+		t.currency = ""
+		return nil
+	}
+	c, err := fx.ParseCurrency(currency)
+	if err != nil {
+		return fmt.Errorf("can't set currency: %w", err)
+	}
+	t.currency = c
+	return nil
+}
+
+func (t *Transaction) Currency() string {
+	return t.currency
+}
+
+func (t *Transaction) settledIn(fallback string) string {
+	if t.currency != "" {
+		return t.currency
+	}
+	return fallback
+}
+
+func (t *Transaction) SetInstrument(id uuid.UUID, symbol string) error {
+	if t.kind.IsCashFlow() || t.kind.IsTransfer() && t.asset == "" {
+		return fmt.Errorf("can't set instrument: %s has no asset", t.kind)
+	}
+	if err := t.setAsset(symbol); err != nil {
+		return fmt.Errorf("can't set instrument: %w", err)
+	}
+	t.instrumentID = id
+	return nil
+}
+
+func (t *Transaction) SetAsset(asset string) error {
+	if t.instrumentID != uuid.Nil {
+		return fmt.Errorf("can't set asset: transaction is linked to instrument %s", t.instrumentID.String())
+	}
+	if t.asset == "" {
+		return fmt.Errorf("can't set asset: %s has no asset", t.kind)
+	}
+	if err := t.setAsset(asset); err != nil {
+		return fmt.Errorf("can't set asset: %w", err)
+	}
+	return nil
+}
+
+func (t *Transaction) InstrumentID() uuid.UUID {
+	return t.instrumentID
+}
+
 func (t *Transaction) setID(id uuid.UUID) error {
 	if id == uuid.Nil {
 		return fmt.Errorf("id can't be empty")
@@ -77,31 +523,91 @@ func (t *Transaction) setAsset(asset string) error {
 	return nil
 }
 
-func (t *Transaction) setQuantity(quantity int) error {
-	if quantity == 0 {
+func (t *Transaction) setQuantity(quantity decimal.Decimal) error {
+	if quantity.IsZero() {
 		return fmt.Errorf("quantity can't be zero")
 	}
 	t.quantity = quantity
+	t.kind = Buy
+	if quantity.IsNegative() {
+		t.kind = Sell
+	}
+	return nil
+}
+
+func (t *Transaction) setAmount(amount decimal.Decimal) error {
+	if !amount.IsPositive() {
+		return fmt.Errorf("amount must be positive")
+	}
+	t.amount = amount
 	return nil
 }
 
-func (t *Transaction) setPrice(price float64) error {
-	if price < 0 {
+func (t *Transaction) setPrice(price decimal.Decimal) error {
+	if price.IsNegative() {
 		return fmt.Errorf("price can't be negative")
 	}
 	t.price = price
 	return nil
 }
 
+func (t *Transaction) Kind() Kind {
+	return t.kind
+}
+
+func (t *Transaction) Amount() decimal.Decimal {
+	return t.amount
+}
+
+func (t *Transaction) cash() decimal.Decimal {
+	switch {
+	case t.kind.IsTrade() || t.kind == Redemption:
+		return t.price.Mul(t.quantity).Mul(t.Multiplier()).Neg().Add(t.income()).Sub(t.fee())
+	case t.movesAsset():
+		return t.fee().Neg()
+	case t.kind == Withdrawal || t.kind == TransferOut || t.kind == BorrowFee || t.kind == AccountFee:
+		return t.amount.Neg().Sub(t.fee())
+	}
+	return t.amount.Sub(t.fee())
+}
+
+func (t *Transaction) income() decimal.Decimal {
+	switch {
+	case t.kind.IsIncome():
+		return t.amount
+	case t.kind == Buy:
+		return t.accrued.Neg()
+	case t.kind == Sell:
+		return t.accrued
+	}
+	return decimal.Zero
+}
+
+func (t *Transaction) isExternal() bool {
+	return t.kind.IsCashFlow() || t.kind.IsTransfer()
+}
+
+func (t *Transaction) movesAsset() bool {
+	return t.kind.IsTrade() || t.kind.IsOptionSettlement() || t.kind == Redemption || t.kind.IsTransfer() && t.asset != ""
+}
+
+func (t *Transaction) fee() decimal.Decimal {
+	res := decimal.Zero
+	for _, f := range t.fees {
+		res = res.Add(f.Amount)
+	}
+	return res
+}
+
 func (t *Transaction) Asset() string {
 	return t.asset
 }
 
-func (t *Transaction) Price() float64 {
+func (t *Transaction) Price() decimal.Decimal {
 	return t.price
 }
 
-func (t *Transaction) Quantity() int {
+func (t *Transaction) Quantity() decimal.Decimal {
 	return t.quantity
 }
 
@@ -109,6 +615,26 @@ func (t *Transaction) Date() time.Time {
 	return t.date
 }
 
+func (t *Transaction) Pending() bool {
+	return t.pending
+}
+
 func (t *Transaction) ID() uuid.UUID {
 	return t.id
 }
+
+func (t *Transaction) Lots() []LotSelection {
+	return t.lots
+}
+
+func (t *Transaction) Fees() []Fee {
+	return t.fees
+}
+
+func (t *Transaction) ReceivedLots() []Lot {
+	return t.received
+}
+
+func (t *Transaction) Transfer() TransferLink {
+	return t.link
+}
@@ -0,0 +1,143 @@
+package portfolio
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+type Transfer struct {
+	Date         time.Time
+	Asset        string
+	InstrumentID uuid.UUID
+	Quantity     decimal.Decimal
+	Price        decimal.Decimal
+	Amount       decimal.Decimal
+	Currency     string
+	Lots         []LotSelection
+}
+
+func (p *Portfolio) TransferTo(to *Portfolio, outID, inID uuid.UUID, tr Transfer) error {
+	if p.id == to.id {
+		return fmt.Errorf("can't transfer: portfolios must be different")
+	}
+	if p.userID != to.userID {
+		return fmt.Errorf("can't transfer: portfolios must belong to the same user")
+	}
+
+	var out, in *Transaction
+	var err error
+	if tr.Asset == "" {
+		if out, err = NewTransfer(outID, tr.Date, TransferOut, "", decimal.Zero, decimal.Zero, tr.Amount); err != nil {
+			return fmt.Errorf("can't transfer: %w", err)
+		}
+		if in, err = NewTransfer(inID, tr.Date, TransferIn, "", decimal.Zero, decimal.Zero, tr.Amount); err != nil {
+			return fmt.Errorf("can't transfer: %w", err)
+		}
+		currency := tr.Currency
+		if currency == "" {
+			currency = p.currency
+		}
+		if err := out.SetCurrency(currency); err != nil {
+			return fmt.Errorf("can't transfer: %w", err)
+		}
+		if err := in.SetCurrency(currency); err != nil {
+			return fmt.Errorf("can't transfer: %w", err)
+		}
+	} else {
+		if out, in, err = p.transferSecurities(outID, inID, tr); err != nil {
+			return fmt.Errorf("can't transfer: %w", err)
+		}
+	}
+
+	if err := out.LinkTransfer(TransferLink{PortfolioID: to.id, TransactionID: inID}); err != nil {
+		return fmt.Errorf("can't transfer: %w", err)
+	}
+	if err := in.LinkTransfer(TransferLink{PortfolioID: p.id, TransactionID: outID}); err != nil {
+		return fmt.Errorf("can't transfer: %w", err)
+	}
+	if err := p.ApplyTransaction(out); err != nil {
+		return fmt.Errorf("can't transfer out of portfolio %s: %w", p.id.String(), err)
+	}
+	if err := to.ApplyTransaction(in); err != nil {
+		return fmt.Errorf("can't transfer into portfolio %s: %w", to.id.String(), err)
+	}
+	return nil
+}
+
+func (p *Portfolio) transferSecurities(outID, inID uuid.UUID, tr Transfer) (*Transaction, *Transaction, error) {
+	if len(tr.Lots) > 0 && p.costBasisMethod == AverageCost {
+		return nil, nil, fmt.Errorf("lots can't be selected with %s cost basis method", AverageCost)
+	}
+	book, err := p.replay(tr.Date)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(book.lots[tr.Asset]) == 0 {
+		return nil, nil, fmt.Errorf("asset %s isn't held on %s", tr.Asset, tr.Date.Format("2006-01-02"))
+	}
+	currency := book.currencyOf(tr.Asset)
+	multiplier := book.multiplierOf(tr.Asset)
+
+	// the lots are closed on a replayed book, so that the portfolio stays as it is until the transfer is applied
+	probe, err := NewTransfer(outID, tr.Date, TransferOut, tr.Asset, tr.Quantity, tr.Price, decimal.Zero)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := probe.SelectLots(tr.Lots); err != nil {
+		return nil, nil, err
+	}
+	probe.currency = currency
+	probe.multiplier = multiplier
+	lots, err := book.apply(probe)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	price := tr.Price
+	if price.IsZero() {
+		cost := decimal.Zero
+		for _, l := range lots {
+			cost = cost.Add(l.Cost())
+		}
+		price = cost.Div(tr.Quantity.Mul(multiplier))
+	}
+	out, err := NewTransfer(outID, tr.Date, TransferOut, tr.Asset, tr.Quantity, price, decimal.Zero)
+	if err != nil {
+		return nil, nil, err
+	}
+	in, err := NewTransfer(inID, tr.Date, TransferIn, tr.Asset, tr.Quantity, price, decimal.Zero)
+	if err != nil {
+		return nil, nil, err
+	}
+	// the lots leaving p are recorded, unless they are pooled at the average cost, so that both sides
+	// keep moving the same lots when earlier transactions change
+	if p.costBasisMethod != AverageCost {
+		selected := []LotSelection{}
+		for _, l := range lots {
+			selected = append(selected, LotSelection{TransactionID: l.TransactionID, Quantity: l.Quantity})
+		}
+		if err := out.SelectLots(selected); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := in.ReceiveLots(lots); err != nil {
+		return nil, nil, err
+	}
+	for _, t := range []*Transaction{out, in} {
+		if err := t.SetCurrency(currency); err != nil {
+			return nil, nil, err
+		}
+		if err := t.SetMultiplier(multiplier); err != nil {
+			return nil, nil, err
+		}
+		if tr.InstrumentID != uuid.Nil {
+			if err := t.SetInstrument(tr.InstrumentID, tr.Asset); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	return out, in, nil
+}
@@ -0,0 +1,220 @@
+package portfolio
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+const WashSaleDays = 30
+
+type WashSale struct {
+	Asset                  string
+	PortfolioID            uuid.UUID
+	TransactionID          uuid.UUID
+	LotID                  uuid.UUID
+	Sold                   time.Time
+	Quantity               decimal.Decimal
+	Disallowed             decimal.Decimal
+	ReplacementPortfolioID uuid.UUID
+	ReplacementID          uuid.UUID
+	Replaced               time.Time
+}
+
+func (ws WashSale) date() time.Time {
+	if ws.Replaced.After(ws.Sold) {
+		return ws.Replaced
+	}
+	return ws.Sold
+}
+
+type loss struct {
+	p        *Portfolio
+	gain     RealizedGain
+	quantity decimal.Decimal
+}
+
+type purchase struct {
+	p *Portfolio
+	t *Transaction
+}
+
+type portfolioEvent struct {
+	event
+	p *Portfolio
+}
+
+func DetectWashSales(portfolios []*Portfolio) ([]WashSale, error) {
+	books := map[uuid.UUID]*positionBook{}
+	timeline := []portfolioEvent{}
+	for _, p := range portfolios {
+		book := p.newBook()
+		book.washSales = nil
+		books[p.id] = book
+		for _, e := range mergeEvents(p.allActions(), p.withPayments(executed(p.transactions))) {
+			timeline = append(timeline, portfolioEvent{event: e, p: p})
+		}
+	}
+	sort.SliceStable(timeline, func(i, j int) bool {
+		return timeline[i].date.Before(timeline[j].date)
+	})
+
+	res := []WashSale{}
+	open, purchases := []*loss{}, []purchase{}
+	used := map[uuid.UUID]decimal.Decimal{}
+	for i := 0; i < len(timeline); {
+		date := timeline[i].date
+		losses, bought := []*loss{}, []purchase{}
+		for ; i < len(timeline) && timeline[i].date.Equal(date); i++ {
+			pe := timeline[i]
+			book := books[pe.p.id]
+			realized := len(book.realized)
+			if _, _, err := book.applyEvent(pe.event); err != nil {
+				return nil, fmt.Errorf("can't detect wash sales in portfolio %s: %w", pe.p.id, eventError(pe.event, err))
+			}
+			switch t := pe.transaction; {
+			case t == nil:
+			case t.kind == Buy:
+				bought = append(bought, purchase{p: pe.p, t: t})
+			case t.kind == Sell && pe.p.washSaleRule:
+				for _, g := range book.realized[realized:] {
+					if g.Quantity.IsPositive() && g.Proceeds.LessThan(g.Cost) {
+						losses = append(losses, &loss{p: pe.p, gain: g, quantity: g.Quantity})
+					}
+				}
+			}
+		}
+
+		// earlier losses can only be replaced by the purchases just made, new ones by any purchase made so far;
+		// adjustments are made after all events of the date as in Portfolio.events
+		purchases = append(purchases, bought...)
+		found := []WashSale{}
+		for _, l := range open {
+			found = append(found, l.replace(bought, books, used)...)
+		}
+		for _, l := range losses {
+			found = append(found, l.replace(purchases, books, used)...)
+		}
+		for j := range found {
+			books[found[j].ReplacementPortfolioID].adjust(&found[j])
+		}
+		res = append(res, found...)
+
+		pending := []*loss{}
+		for _, l := range append(open, losses...) {
+			if l.quantity.IsPositive() && !Day.Start(date).After(Day.Start(l.gain.Sold).AddDate(0, 0, WashSaleDays)) {
+				pending = append(pending, l)
+			}
+		}
+		open = pending
+	}
+	return res, nil
+}
+
+func (l *loss) replace(purchases []purchase, books map[uuid.UUID]*positionBook, used map[uuid.UUID]decimal.Decimal) []WashSale {
+	g := l.gain
+	sold := Day.Start(g.Sold)
+	from, to := sold.AddDate(0, 0, -WashSaleDays), sold.AddDate(0, 0, WashSaleDays)
+	res := []WashSale{}
+	for _, pu := range purchases {
+		if !l.quantity.IsPositive() {
+			break
+		}
+		t, day := pu.t, Day.Start(pu.t.date)
+		if t.asset != g.Asset || t.id == g.LotID || t.settledIn(pu.p.currency) != g.Currency || day.Before(from) || day.After(to) {
+			continue
+		}
+		available := books[pu.p.id].remaining(t).Sub(used[t.id])
+		if !available.IsPositive() {
+			continue
+		}
+		q := decimal.Min(l.quantity, available)
+		used[t.id] = used[t.id].Add(q)
+		l.quantity = l.quantity.Sub(q)
+		res = append(res, WashSale{
+			Asset:                  g.Asset,
+			PortfolioID:            l.p.id,
+			TransactionID:          g.TransactionID,
+			LotID:                  g.LotID,
+			Sold:                   g.Sold,
+			Quantity:               q,
+			Disallowed:             g.Cost.Sub(g.Proceeds).Mul(q).Div(g.Quantity),
+			ReplacementPortfolioID: pu.p.id,
+			ReplacementID:          t.id,
+			Replaced:               t.date,
+		})
+	}
+	return res
+}
+
+func (b *positionBook) remaining(t *Transaction) decimal.Decimal {
+	res := decimal.Zero
+	for _, l := range b.lots[t.asset] {
+		if l.TransactionID == t.id && l.Quantity.IsPositive() {
+			res = res.Add(l.Quantity)
+		}
+	}
+	return res
+}
+
+func withAdjustments(events []event, washSales []*WashSale) []event {
+	if len(washSales) == 0 {
+		return events
+	}
+	sort.SliceStable(washSales, func(i, j int) bool {
+		return washSales[i].date().Before(washSales[j].date())
+	})
+	res := make([]event, 0, len(events)+len(washSales))
+	i := 0
+	for _, ws := range washSales {
+		for ; i < len(events) && !events[i].date.After(ws.date()); i++ {
+			res = append(res, events[i])
+		}
+		res = append(res, event{date: ws.date(), washSale: ws})
+	}
+	return append(res, events[i:]...)
+}
+
+func (b *positionBook) adjust(ws *WashSale) {
+	for _, l := range b.lots[ws.Asset] {
+		if l.TransactionID == ws.ReplacementID && l.Quantity.IsPositive() {
+			l.Adjustment = l.Adjustment.Add(ws.Disallowed)
+			l.Price = l.Price.Add(ws.Disallowed.Div(l.Quantity))
+			return
+		}
+	}
+}
+
+func (b *positionBook) disallowed(transactionID, lotID uuid.UUID) decimal.Decimal {
+	res := decimal.Zero
+	for _, ws := range b.washSales {
+		if ws.TransactionID == transactionID && ws.LotID == lotID {
+			res = res.Add(ws.Disallowed)
+		}
+	}
+	return res
+}
+
+func (p *Portfolio) SetWashSales(washSales []WashSale) {
+	p.washSales = []WashSale{}
+	for _, ws := range washSales {
+		if ws.PortfolioID == p.id || ws.ReplacementPortfolioID == p.id {
+			p.washSales = append(p.washSales, ws)
+		}
+	}
+}
+
+func (p *Portfolio) WashSales() []WashSale {
+	return p.washSales
+}
+
+func (p *Portfolio) ChangeWashSaleRule(applied bool) {
+	p.washSaleRule = applied
+}
+
+func (p *Portfolio) WashSaleRule() bool {
+	return p.washSaleRule
+}
@@ -0,0 +1,157 @@
+package portfolio
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestWashSales(t *testing.T) {
+	tests := []struct {
+		name        string
+		sellerRule  bool
+		otherRule   bool
+		seller      []*Transaction
+		other       []*Transaction
+		washSales   int
+		disallowed  string
+		cost        string
+		adjustment  string
+		realizedNet string
+	}{
+		{
+			name:        "bought back in the same portfolio",
+			sellerRule:  true,
+			seller:      []*Transaction{newTrade(1, "2021-01-04", "AAPL", "10", "100"), newTrade(2, "2021-02-01", "AAPL", "-10", "80"), newTrade(3, "2021-02-10", "AAPL", "10", "85")},
+			washSales:   1,
+			disallowed:  "200",
+			cost:        "1050",
+			adjustment:  "200",
+			realizedNet: "0",
+		},
+		{
+			name:       "bought before the sale",
+			sellerRule: true,
+			seller: []*Transaction{
+				newTrade(1, "2021-01-04", "AAPL", "10", "100"),
+				newTrade(2, "2021-01-20", "AAPL", "10", "90"),
+				newTrade(3, "2021-02-01", "AAPL", "-10", "80"),
+			},
+			washSales:   1,
+			disallowed:  "200",
+			cost:        "1100",
+			adjustment:  "200",
+			realizedNet: "0",
+		},
+		{
+			name:        "partly bought back",
+			sellerRule:  true,
+			seller:      []*Transaction{newTrade(1, "2021-01-04", "AAPL", "10", "100"), newTrade(2, "2021-02-01", "AAPL", "-10", "80"), newTrade(3, "2021-02-10", "AAPL", "4", "85")},
+			washSales:   1,
+			disallowed:  "80",
+			cost:        "420",
+			adjustment:  "80",
+			realizedNet: "-120",
+		},
+		{
+			name:        "bought back in a portfolio without the rule",
+			sellerRule:  true,
+			seller:      []*Transaction{newTrade(1, "2021-01-04", "AAPL", "10", "100"), newTrade(2, "2021-02-01", "AAPL", "-10", "80")},
+			other:       []*Transaction{newTrade(11, "2021-02-10", "AAPL", "10", "85")},
+			washSales:   1,
+			disallowed:  "200",
+			cost:        "1050",
+			adjustment:  "200",
+			realizedNet: "0",
+		},
+		{
+			name:        "sold in a portfolio without the rule",
+			otherRule:   true,
+			seller:      []*Transaction{newTrade(1, "2021-01-04", "AAPL", "10", "100"), newTrade(2, "2021-02-01", "AAPL", "-10", "80")},
+			other:       []*Transaction{newTrade(11, "2021-02-10", "AAPL", "10", "85")},
+			washSales:   0,
+			disallowed:  "0",
+			cost:        "850",
+			adjustment:  "0",
+			realizedNet: "-200",
+		},
+		{
+			name:        "bought back after 30 days",
+			sellerRule:  true,
+			seller:      []*Transaction{newTrade(1, "2021-01-04", "AAPL", "10", "100"), newTrade(2, "2021-02-01", "AAPL", "-10", "80"), newTrade(3, "2021-03-04", "AAPL", "10", "85")},
+			washSales:   0,
+			disallowed:  "0",
+			cost:        "850",
+			adjustment:  "0",
+			realizedNet: "-200",
+		},
+		{
+			name:        "sold at a gain",
+			sellerRule:  true,
+			seller:      []*Transaction{newTrade(1, "2021-01-04", "AAPL", "10", "100"), newTrade(2, "2021-02-01", "AAPL", "-10", "120"), newTrade(3, "2021-02-10", "AAPL", "10", "115")},
+			washSales:   0,
+			disallowed:  "0",
+			cost:        "1150",
+			adjustment:  "0",
+			realizedNet: "200",
+		},
+		{
+			name:        "other asset bought",
+			sellerRule:  true,
+			seller:      []*Transaction{newTrade(1, "2021-01-04", "AAPL", "10", "100"), newTrade(2, "2021-02-01", "AAPL", "-10", "80")},
+			other:       []*Transaction{newTrade(11, "2021-02-10", "MSFT", "10", "85")},
+			washSales:   0,
+			disallowed:  "0",
+			cost:        "850",
+			adjustment:  "0",
+			realizedNet: "-200",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seller, other := newTestPortfolio(t, 1), newTestPortfolio(t, 2)
+			seller.ChangeWashSaleRule(tt.sellerRule)
+			other.ChangeWashSaleRule(tt.otherRule)
+			applyAll(t, seller, tt.seller...)
+			applyAll(t, other, tt.other...)
+
+			washSales, err := DetectWashSales([]*Portfolio{seller, other})
+			if err != nil {
+				t.Fatalf("DetectWashSales() error = %v", err)
+			}
+			disallowed := decimal.Zero
+			for _, ws := range washSales {
+				disallowed = disallowed.Add(ws.Disallowed)
+			}
+			if len(washSales) != tt.washSales || !disallowed.Equal(dec(tt.disallowed)) {
+				t.Fatalf("DetectWashSales() = %d wash sales disallowing %s, want %d disallowing %s", len(washSales), disallowed, tt.washSales, tt.disallowed)
+			}
+
+			date := day("2021-12-31")
+			cost, adjustment, realized := decimal.Zero, decimal.Zero, decimal.Zero
+			for _, p := range []*Portfolio{seller, other} {
+				p.SetWashSales(washSales)
+				s, err := p.Snapshot(date)
+				if err != nil {
+					t.Fatalf("Snapshot() error = %v", err)
+				}
+				for _, pos := range s.Positions {
+					cost, adjustment = cost.Add(pos.TotalCost), adjustment.Add(pos.WashSaleAdjustment)
+				}
+				gains, err := p.RealizedGains(date)
+				if err != nil {
+					t.Fatalf("RealizedGains() error = %v", err)
+				}
+				for _, g := range gains {
+					realized = realized.Add(g.Gain())
+				}
+			}
+			if !cost.Equal(dec(tt.cost)) || !adjustment.Equal(dec(tt.adjustment)) {
+				t.Errorf("positions cost %s with adjustment %s, want %s with %s", cost, adjustment, tt.cost, tt.adjustment)
+			}
+			if !realized.Equal(dec(tt.realizedNet)) {
+				t.Errorf("realized gain = %s, want %s", realized, tt.realizedNet)
+			}
+		})
+	}
+}
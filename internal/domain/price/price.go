@@ -0,0 +1,97 @@
+package price
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+var ErrPriceNotFound = errors.New("price not found")
+
+type Price struct {
+	asset         string
+	date          time.Time
+	close         decimal.Decimal
+	previousClose decimal.Decimal
+	currency      string
+	source        string
+}
+
+func NewPrice(asset string, date time.Time, close, previousClose decimal.Decimal, currency, source string) (*Price, error) {
+	p := &Price{}
+	if err := p.setAsset(asset); err != nil {
+		return nil, fmt.Errorf("can't create price: %w", err)
+	}
+	if err := p.setDate(date); err != nil {
+		return nil, fmt.Errorf("can't create price: %w", err)
+	}
+	if err := p.setClose(close, previousClose); err != nil {
+		return nil, fmt.Errorf("can't create price: %w", err)
+	}
+	p.currency = currency
+	p.source = source
+	return p, nil
+}
+
+func (p *Price) setAsset(asset string) error {
+	if asset == "" {
+		return fmt.Errorf("asset can't be empty")
+	}
+	p.asset = asset
+	return nil
+}
+
+func (p *Price) setDate(date time.Time) error {
+	if date.IsZero() {
+		return fmt.Errorf("date can't be empty")
+	}
+	p.date = date
+	return nil
+}
+
+func (p *Price) setClose(close, previousClose decimal.Decimal) error {
+	if close.IsNegative() || previousClose.IsNegative() {
+		return fmt.Errorf("price can't be negative")
+	}
+	p.close = close
+	p.previousClose = previousClose
+	return nil
+}
+
+func (p *Price) Asset() string {
+	return p.asset
+}
+
+func (p *Price) Date() time.Time {
+	return p.date
+}
+
+func (p *Price) Close() decimal.Decimal {
+	return p.close
+}
+
+func (p *Price) PreviousClose() decimal.Decimal {
+	return p.previousClose
+}
+
+func (p *Price) Currency() string {
+	return p.currency
+}
+
+func (p *Price) Source() string {
+	return p.source
+}
+
+func (p *Price) DayChange() decimal.Decimal {
+	if p.previousClose.IsZero() {
+		return decimal.Zero
+	}
+	return p.close.Sub(p.previousClose)
+}
+
+func LastCloseDate(t time.Time) time.Time {
+	d := t.AddDate(0, 0, -1)
+	return time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, time.UTC)
+}
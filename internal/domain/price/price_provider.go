@@ -0,0 +1,16 @@
+package price
+
+import (
+	"context"
+	"time"
+)
+
+type PriceProvider interface {
+	GetPrice(ctx context.Context, asset string) (*Price, error)
+}
+
+type PriceHistoryRepository interface {
+	GetPriceAt(ctx context.Context, asset string, date time.Time) (*Price, error)
+	GetPriceHistory(ctx context.Context, asset string, from, to time.Time) (Series, error)
+	AddPrices(ctx context.Context, prices []*Price) error
+}
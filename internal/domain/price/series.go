@@ -0,0 +1,23 @@
+package price
+
+import (
+	"sort"
+	"time"
+)
+
+type Series []*Price
+
+func NewSeries(prices []*Price) Series {
+	s := make(Series, len(prices))
+	copy(s, prices)
+	sort.Slice(s, func(i, j int) bool { return s[i].date.Before(s[j].date) })
+	return s
+}
+
+func (s Series) At(date time.Time) *Price {
+	i := sort.Search(len(s), func(i int) bool { return s[i].date.After(date) })
+	if i == 0 {
+		return nil
+	}
+	return s[i-1]
+}
@@ -0,0 +1,130 @@
+package ports
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/invine/portfolio/internal/app/query"
+	"github.com/invine/portfolio/internal/domain/portfolio"
+	"github.com/shopspring/decimal"
+)
+
+type allocationGroupModel struct {
+	Key    string                 `json:"key"`
+	Name   string                 `json:"name"`
+	Value  decimal.Decimal        `json:"value"`
+	Weight float64                `json:"weight"`
+	Share  float64                `json:"share"`
+	Groups []allocationGroupModel `json:"groups,omitempty"`
+}
+
+type allocationModel struct {
+	ID       string                 `json:"id"`
+	Name     string                 `json:"name"`
+	Date     time.Time              `json:"date"`
+	Currency string                 `json:"currency"`
+	By       []string               `json:"by"`
+	Value    decimal.Decimal        `json:"value"`
+	Groups   []allocationGroupModel `json:"groups"`
+}
+
+func (s *Server) GetAllocationHandler(rw http.ResponseWriter, r *http.Request) {
+	u, err := UserFromCtx(r.Context())
+	if err != nil {
+		log.Printf("get allocation: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		log.Printf("get allocation: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	t, err := dateFromQuery(r)
+	if err != nil {
+		log.Printf("get allocation: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	by := []portfolio.AllocationKey{portfolio.ByClass}
+	if bs := r.URL.Query().Get("by"); bs != "" {
+		by = []portfolio.AllocationKey{}
+		for _, k := range strings.Split(bs, ",") {
+			key, err := portfolio.ParseAllocationKey(k)
+			if err != nil {
+				log.Printf("get allocation: %v", err)
+				rw.WriteHeader(400)
+				return
+			}
+			by = append(by, key)
+		}
+	}
+
+	allocation, err := s.app.Queries.Allocation.Handle(
+		r.Context(),
+		query.Allocation{
+			UserID: u.ID,
+			ID:     id,
+			Date:   t,
+			By:     by,
+		},
+	)
+	if err != nil {
+		log.Printf("get allocation: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	bytes, err := json.Marshal(allocationToAllocationModel(allocation))
+	if err != nil {
+		log.Printf("get allocation: %v", err)
+		rw.WriteHeader(500)
+		return
+	}
+	if _, err := rw.Write(bytes); err != nil {
+		log.Printf("get allocation: %v", err)
+	}
+}
+
+func allocationToAllocationModel(a *portfolio.Allocation) allocationModel {
+	am := allocationModel{
+		ID:       a.ID.String(),
+		Name:     a.Name,
+		Date:     a.Date,
+		Currency: a.Currency,
+		By:       []string{},
+		Value:    a.Value,
+		Groups:   allocationGroupsToModel(a.Groups),
+	}
+	for _, k := range a.By {
+		am.By = append(am.By, string(k))
+	}
+	return am
+}
+
+func allocationGroupsToModel(groups []*portfolio.AllocationGroup) []allocationGroupModel {
+	res := []allocationGroupModel{}
+	for _, g := range groups {
+		gm := allocationGroupModel{
+			Key:    string(g.Key),
+			Name:   g.Name,
+			Value:  g.Value,
+			Weight: g.Weight,
+			Share:  g.Share,
+		}
+		if len(g.Groups) > 0 {
+			gm.Groups = allocationGroupsToModel(g.Groups)
+		}
+		res = append(res, gm)
+	}
+	return res
+}
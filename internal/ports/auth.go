@@ -23,7 +23,8 @@ type UserClaims struct {
 }
 
 type User struct {
-	ID uuid.UUID
+	ID    uuid.UUID
+	Login string
 }
 
 type ctxKey int
@@ -65,7 +66,7 @@ func (s *Server) AuthenticateMiddleware(next http.Handler) http.Handler {
 			rw.WriteHeader(401)
 			return
 		}
-		ctx = context.WithValue(ctx, userCtxKey, User{ID: id})
+		ctx = context.WithValue(ctx, userCtxKey, User{ID: id, Login: claims.Login})
 
 		r = r.WithContext(ctx)
 
@@ -73,6 +74,24 @@ func (s *Server) AuthenticateMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+func (s *Server) AdminMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		u, err := UserFromCtx(r.Context())
+		if err != nil {
+			log.Printf("admin required: %v", err)
+			rw.WriteHeader(401)
+			return
+		}
+		if !s.admins[u.Login] {
+			log.Printf("user %s is not an admin", u.Login)
+			rw.WriteHeader(403)
+			return
+		}
+
+		next.ServeHTTP(rw, r)
+	})
+}
+
 func (s *Server) UserSignInHandler(rw http.ResponseWriter, r *http.Request) {
 	bytes, err := io.ReadAll(r.Body)
 	if err != nil {
@@ -0,0 +1,126 @@
+package ports
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/invine/portfolio/internal/app/query"
+	"github.com/invine/portfolio/internal/domain/fx"
+	"github.com/invine/portfolio/internal/domain/portfolio"
+	"github.com/shopspring/decimal"
+)
+
+type cashEntryModel struct {
+	TransactionID string          `json:"transactionId"`
+	Date          time.Time       `json:"date"`
+	Kind          string          `json:"kind,omitempty"`
+	Action        string          `json:"action,omitempty"`
+	Asset         string          `json:"asset,omitempty"`
+	Amount        decimal.Decimal `json:"amount"`
+	Balance       decimal.Decimal `json:"balance"`
+}
+
+type cashLedgerModel struct {
+	ID       string           `json:"id"`
+	Name     string           `json:"name"`
+	Currency string           `json:"currency"`
+	From     time.Time        `json:"from"`
+	To       time.Time        `json:"to"`
+	Opening  decimal.Decimal  `json:"opening"`
+	Closing  decimal.Decimal  `json:"closing"`
+	Entries  []cashEntryModel `json:"entries"`
+}
+
+func (s *Server) GetCashLedgerHandler(rw http.ResponseWriter, r *http.Request) {
+	u, err := UserFromCtx(r.Context())
+	if err != nil {
+		log.Printf("get cash ledger: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		log.Printf("get cash ledger: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	to, err := dayFromQuery(r, "to", time.Now())
+	if err != nil {
+		log.Printf("get cash ledger: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+	from, err := dayFromQuery(r, "from", to.AddDate(-1, 0, 0))
+	if err != nil {
+		log.Printf("get cash ledger: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	currency := r.URL.Query().Get("currency")
+	if currency != "" {
+		currency, err = fx.ParseCurrency(currency)
+		if err != nil {
+			log.Printf("get cash ledger: %v", err)
+			rw.WriteHeader(400)
+			return
+		}
+	}
+
+	ledger, err := s.app.Queries.CashLedger.Handle(
+		r.Context(),
+		query.CashLedger{
+			UserID:   u.ID,
+			ID:       id,
+			From:     from,
+			To:       to,
+			Currency: currency,
+		},
+	)
+	if err != nil {
+		log.Printf("get cash ledger: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	bytes, err := json.Marshal(cashLedgerToCashLedgerModel(ledger))
+	if err != nil {
+		log.Printf("get cash ledger: %v", err)
+		rw.WriteHeader(500)
+		return
+	}
+	if _, err := rw.Write(bytes); err != nil {
+		log.Printf("get cash ledger: %v", err)
+	}
+}
+
+func cashLedgerToCashLedgerModel(l *portfolio.CashLedger) cashLedgerModel {
+	lm := cashLedgerModel{
+		ID:       l.ID.String(),
+		Name:     l.Name,
+		Currency: l.Currency,
+		From:     l.From,
+		To:       l.To,
+		Opening:  l.Opening,
+		Closing:  l.Closing,
+		Entries:  []cashEntryModel{},
+	}
+	for _, e := range l.Entries {
+		lm.Entries = append(lm.Entries, cashEntryModel{
+			TransactionID: e.TransactionID.String(),
+			Date:          e.Date,
+			Kind:          string(e.Kind),
+			Action:        string(e.Action),
+			Asset:         e.Asset,
+			Amount:        e.Amount,
+			Balance:       e.Balance,
+		})
+	}
+	return lm
+}
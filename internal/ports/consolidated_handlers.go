@@ -0,0 +1,188 @@
+package ports
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/invine/portfolio/internal/app/query"
+	"github.com/invine/portfolio/internal/domain/fx"
+	"github.com/invine/portfolio/internal/domain/portfolio"
+	"github.com/shopspring/decimal"
+)
+
+type portfolioHoldingModel struct {
+	PortfolioID string          `json:"portfolioId"`
+	Name        string          `json:"name"`
+	Quantity    decimal.Decimal `json:"quantity"`
+	Cost        decimal.Decimal `json:"cost"`
+	MarketValue decimal.Decimal `json:"marketValue"`
+	Share       float64         `json:"share"`
+}
+
+type consolidatedPositionModel struct {
+	Asset       string                  `json:"asset"`
+	Quantity    decimal.Decimal         `json:"quantity"`
+	Cost        decimal.Decimal         `json:"cost"`
+	MarketValue decimal.Decimal         `json:"marketValue"`
+	DayChange   decimal.Decimal         `json:"dayChange"`
+	Gain        decimal.Decimal         `json:"gain"`
+	Weight      float64                 `json:"weight"`
+	Portfolios  []portfolioHoldingModel `json:"portfolios"`
+}
+
+type portfolioSummaryModel struct {
+	ID          string          `json:"id"`
+	Name        string          `json:"name"`
+	Balance     decimal.Decimal `json:"balance"`
+	MarketValue decimal.Decimal `json:"marketValue"`
+	DayChange   decimal.Decimal `json:"dayChange"`
+	Value       decimal.Decimal `json:"value"`
+	Weight      float64         `json:"weight"`
+}
+
+type consolidatedModel struct {
+	Date        time.Time                   `json:"date"`
+	Currency    string                      `json:"currency"`
+	Portfolios  []portfolioSummaryModel     `json:"portfolios"`
+	Assets      []consolidatedPositionModel `json:"assets"`
+	Cash        map[string]decimal.Decimal  `json:"cash"`
+	Balance     decimal.Decimal             `json:"balance"`
+	MarketValue decimal.Decimal             `json:"marketValue"`
+	DayChange   decimal.Decimal             `json:"dayChange"`
+	Value       decimal.Decimal             `json:"value"`
+	Performance performanceModel            `json:"performance"`
+}
+
+func (s *Server) GetConsolidatedHandler(rw http.ResponseWriter, r *http.Request) {
+	u, err := UserFromCtx(r.Context())
+	if err != nil {
+		log.Printf("get consolidated: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	ids := []uuid.UUID{}
+	if idsString := r.URL.Query().Get("portfolio"); idsString != "" {
+		for _, idString := range strings.Split(idsString, ",") {
+			id, err := uuid.Parse(strings.TrimSpace(idString))
+			if err != nil {
+				log.Printf("get consolidated: %v", err)
+				rw.WriteHeader(400)
+				return
+			}
+			ids = append(ids, id)
+		}
+	}
+
+	currency := r.URL.Query().Get("currency")
+	if currency != "" {
+		if currency, err = fx.ParseCurrency(currency); err != nil {
+			log.Printf("get consolidated: %v", err)
+			rw.WriteHeader(400)
+			return
+		}
+	}
+
+	t, err := dateFromQuery(r)
+	if err != nil {
+		log.Printf("get consolidated: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+	to, err := dayFromQuery(r, "to", time.Now())
+	if err != nil {
+		log.Printf("get consolidated: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+	from, err := dayFromQuery(r, "from", to.AddDate(-1, 0, 0))
+	if err != nil {
+		log.Printf("get consolidated: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	c, err := s.app.Queries.Consolidated.Handle(
+		r.Context(),
+		query.Consolidated{
+			UserID:   u.ID,
+			IDs:      ids,
+			Date:     t,
+			From:     from,
+			To:       to,
+			Currency: currency,
+		},
+	)
+	if err != nil {
+		log.Printf("get consolidated: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	bytes, err := json.Marshal(consolidatedToConsolidatedModel(c))
+	if err != nil {
+		log.Printf("get consolidated: %v", err)
+		rw.WriteHeader(500)
+		return
+	}
+	if _, err := rw.Write(bytes); err != nil {
+		log.Printf("get consolidated: %v", err)
+	}
+}
+
+func consolidatedToConsolidatedModel(c *portfolio.Consolidated) consolidatedModel {
+	cm := consolidatedModel{
+		Date:        c.Date,
+		Currency:    c.Currency,
+		Portfolios:  []portfolioSummaryModel{},
+		Assets:      []consolidatedPositionModel{},
+		Cash:        c.Cash,
+		Balance:     c.Balance,
+		MarketValue: c.MarketValue,
+		DayChange:   c.DayChange,
+		Value:       c.Value,
+	}
+	for _, p := range c.Portfolios {
+		cm.Portfolios = append(cm.Portfolios, portfolioSummaryModel{
+			ID:          p.ID.String(),
+			Name:        p.Name,
+			Balance:     p.Balance,
+			MarketValue: p.MarketValue,
+			DayChange:   p.DayChange,
+			Value:       p.Value,
+			Weight:      p.Weight,
+		})
+	}
+	for _, pos := range c.Positions {
+		pm := consolidatedPositionModel{
+			Asset:       pos.Asset,
+			Quantity:    pos.Quantity,
+			Cost:        pos.Cost,
+			MarketValue: pos.MarketValue,
+			DayChange:   pos.DayChange,
+			Gain:        pos.Gain,
+			Weight:      pos.Weight,
+			Portfolios:  []portfolioHoldingModel{},
+		}
+		for _, h := range pos.Holdings {
+			pm.Portfolios = append(pm.Portfolios, portfolioHoldingModel{
+				PortfolioID: h.PortfolioID.String(),
+				Name:        h.Name,
+				Quantity:    h.Quantity,
+				Cost:        h.Cost,
+				MarketValue: h.MarketValue,
+				Share:       h.Share,
+			})
+		}
+		cm.Assets = append(cm.Assets, pm)
+	}
+	if c.Performance != nil {
+		cm.Performance = performanceToPerformanceModel(c.Performance)
+		cm.Performance.ID = ""
+	}
+	return cm
+}
@@ -0,0 +1,141 @@
+package ports
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/invine/portfolio/internal/app/command"
+	"github.com/invine/portfolio/internal/app/query"
+	"github.com/invine/portfolio/internal/domain/portfolio"
+	"github.com/shopspring/decimal"
+)
+
+type corporateActionModel struct {
+	ID             string          `json:"id,omitempty"`
+	Type           string          `json:"type"`
+	Date           time.Time       `json:"date"`
+	Asset          string          `json:"asset"`
+	NewAsset       string          `json:"newAsset,omitempty"`
+	From           int             `json:"from"`
+	To             int             `json:"to"`
+	CostAllocation decimal.Decimal `json:"costAllocation"`
+	CashPrice      decimal.Decimal `json:"cashPrice"`
+}
+
+func (s *Server) AddCorporateActionHandler(rw http.ResponseWriter, r *http.Request) {
+	bytes, err := io.ReadAll(r.Body)
+	if err != nil {
+		log.Printf("add corporate action: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	var am corporateActionModel
+	if err := json.Unmarshal(bytes, &am); err != nil {
+		log.Printf("add corporate action: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	a, err := corporateActionModelToCorporateAction(uuid.New(), am)
+	if err != nil {
+		log.Printf("add corporate action: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	if err := s.app.Commands.AddCorporateAction.Handle(r.Context(), command.AddCorporateAction{Action: a}); err != nil {
+		log.Printf("add corporate action: %v", err)
+		rw.WriteHeader(500)
+		return
+	}
+
+	rw.WriteHeader(201)
+}
+
+func (s *Server) ListCorporateActionsHandler(rw http.ResponseWriter, r *http.Request) {
+	actions, err := s.app.Queries.AllCorporateActions.Handle(
+		r.Context(),
+		query.AllCorporateActions{Asset: r.URL.Query().Get("asset")},
+	)
+	if err != nil {
+		log.Printf("list corporate actions: %v", err)
+		rw.WriteHeader(500)
+		return
+	}
+
+	ams := []corporateActionModel{}
+	for _, a := range actions {
+		ams = append(ams, corporateActionToCorporateActionModel(a))
+	}
+	bytes, err := json.Marshal(ams)
+	if err != nil {
+		log.Printf("list corporate actions: %v", err)
+		rw.WriteHeader(500)
+		return
+	}
+	if _, err := rw.Write(bytes); err != nil {
+		log.Printf("list corporate actions: %v", err)
+	}
+}
+
+func (s *Server) DeleteCorporateActionHandler(rw http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		log.Printf("delete corporate action: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	if err := s.app.Commands.DeleteCorporateAction.Handle(r.Context(), command.DeleteCorporateAction{ID: id}); err != nil {
+		log.Printf("delete corporate action: %v", err)
+		rw.WriteHeader(404)
+		return
+	}
+
+	rw.WriteHeader(200)
+}
+
+func corporateActionModelToCorporateAction(id uuid.UUID, am corporateActionModel) (*portfolio.CorporateAction, error) {
+	kind, err := portfolio.ParseCorporateActionType(am.Type)
+	if err != nil {
+		return nil, err
+	}
+	switch kind {
+	case portfolio.Split, portfolio.ReverseSplit:
+		a, err := portfolio.NewSplit(id, am.Date, am.Asset, am.From, am.To, am.CashPrice)
+		if err != nil {
+			return nil, err
+		}
+		if a.Type() != kind {
+			return nil, fmt.Errorf("ratio %d:%d isn't a %s", am.To, am.From, kind)
+		}
+		return a, nil
+	case portfolio.SymbolChange:
+		return portfolio.NewSymbolChange(id, am.Date, am.Asset, am.NewAsset)
+	case portfolio.Merger:
+		return portfolio.NewMerger(id, am.Date, am.Asset, am.NewAsset, am.From, am.To, am.CashPrice)
+	}
+	return portfolio.NewSpinOff(id, am.Date, am.Asset, am.NewAsset, am.From, am.To, am.CostAllocation, am.CashPrice)
+}
+
+func corporateActionToCorporateActionModel(a *portfolio.CorporateAction) corporateActionModel {
+	from, to := a.Ratio()
+	return corporateActionModel{
+		ID:             a.ID().String(),
+		Type:           string(a.Type()),
+		Date:           a.Date(),
+		Asset:          a.Asset(),
+		NewAsset:       a.NewAsset(),
+		From:           from,
+		To:             to,
+		CostAllocation: a.CostAllocation(),
+		CashPrice:      a.CashPrice(),
+	}
+}
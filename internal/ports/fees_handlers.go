@@ -0,0 +1,152 @@
+package ports
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/invine/portfolio/internal/app/query"
+	"github.com/invine/portfolio/internal/domain/portfolio"
+	"github.com/shopspring/decimal"
+)
+
+type feeItemModel struct {
+	TransactionID string          `json:"transactionId"`
+	Date          time.Time       `json:"date"`
+	Kind          string          `json:"kind"`
+	Asset         string          `json:"asset,omitempty"`
+	Type          string          `json:"type"`
+	Amount        decimal.Decimal `json:"amount"`
+	Currency      string          `json:"currency,omitempty"`
+}
+
+type periodFeesModel struct {
+	Period string                     `json:"period"`
+	Start  time.Time                  `json:"start"`
+	End    time.Time                  `json:"end"`
+	Total  decimal.Decimal            `json:"total"`
+	ByType map[string]decimal.Decimal `json:"byType"`
+}
+
+type feesModel struct {
+	ID       string                     `json:"id"`
+	Name     string                     `json:"name"`
+	Currency string                     `json:"currency"`
+	From     time.Time                  `json:"from"`
+	To       time.Time                  `json:"to"`
+	Total    decimal.Decimal            `json:"total"`
+	ByType   map[string]decimal.Decimal `json:"byType"`
+	Periods  []periodFeesModel          `json:"periods"`
+	Items    []feeItemModel             `json:"items"`
+}
+
+func (s *Server) GetFeesHandler(rw http.ResponseWriter, r *http.Request) {
+	u, err := UserFromCtx(r.Context())
+	if err != nil {
+		log.Printf("get fees: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		log.Printf("get fees: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	to, err := dayFromQuery(r, "to", time.Now())
+	if err != nil {
+		log.Printf("get fees: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+	from, err := dayFromQuery(r, "from", to.AddDate(-1, 0, 0))
+	if err != nil {
+		log.Printf("get fees: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	period := portfolio.Month
+	if ps := r.URL.Query().Get("period"); ps != "" {
+		period, err = portfolio.ParsePeriod(ps)
+		if err != nil {
+			log.Printf("get fees: %v", err)
+			rw.WriteHeader(400)
+			return
+		}
+	}
+
+	report, err := s.app.Queries.Fees.Handle(
+		r.Context(),
+		query.Fees{
+			UserID: u.ID,
+			ID:     id,
+			From:   from,
+			To:     to,
+			Period: period,
+		},
+	)
+	if err != nil {
+		log.Printf("get fees: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	bytes, err := json.Marshal(feesReportToFeesModel(report))
+	if err != nil {
+		log.Printf("get fees: %v", err)
+		rw.WriteHeader(500)
+		return
+	}
+	if _, err := rw.Write(bytes); err != nil {
+		log.Printf("get fees: %v", err)
+	}
+}
+
+func feesReportToFeesModel(report *portfolio.FeesReport) feesModel {
+	fm := feesModel{
+		ID:       report.ID.String(),
+		Name:     report.Name,
+		Currency: report.Currency,
+		From:     report.From,
+		To:       report.To,
+		Total:    report.Total,
+		ByType:   byTypeToModel(report.ByType),
+		Periods:  []periodFeesModel{},
+		Items:    []feeItemModel{},
+	}
+	for _, p := range report.Periods {
+		fm.Periods = append(fm.Periods, periodFeesModel{
+			Period: p.Period,
+			Start:  p.Start,
+			End:    p.End,
+			Total:  p.Total,
+			ByType: byTypeToModel(p.ByType),
+		})
+	}
+	for _, i := range report.Items {
+		fm.Items = append(fm.Items, feeItemModel{
+			TransactionID: i.TransactionID.String(),
+			Date:          i.Date,
+			Kind:          string(i.Kind),
+			Asset:         i.Asset,
+			Type:          string(i.Type),
+			Amount:        i.Amount,
+			Currency:      i.Currency,
+		})
+	}
+	return fm
+}
+
+func byTypeToModel(byType map[portfolio.FeeType]decimal.Decimal) map[string]decimal.Decimal {
+	res := map[string]decimal.Decimal{}
+	for t, v := range byType {
+		res[string(t)] = v
+	}
+	return res
+}
@@ -0,0 +1,190 @@
+package ports
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/invine/portfolio/internal/app/query"
+	"github.com/invine/portfolio/internal/domain/portfolio"
+	"github.com/shopspring/decimal"
+)
+
+type disposalModel struct {
+	Asset         string          `json:"asset"`
+	Currency      string          `json:"currency"`
+	TransactionID string          `json:"transactionId"`
+	LotID         string          `json:"lotId"`
+	Quantity      decimal.Decimal `json:"quantity"`
+	Acquired      time.Time       `json:"acquired"`
+	Sold          time.Time       `json:"sold"`
+	Proceeds      decimal.Decimal `json:"proceeds"`
+	Cost          decimal.Decimal `json:"cost"`
+	Adjustment    decimal.Decimal `json:"washSaleAdjustment"`
+	Gain          decimal.Decimal `json:"gain"`
+	Term          string          `json:"term"`
+}
+
+type gainsTotalModel struct {
+	Proceeds   decimal.Decimal `json:"proceeds"`
+	Cost       decimal.Decimal `json:"cost"`
+	Adjustment decimal.Decimal `json:"washSaleAdjustment"`
+	Gain       decimal.Decimal `json:"gain"`
+}
+
+type gainsModel struct {
+	ID             string          `json:"id"`
+	Name           string          `json:"name"`
+	Currency       string          `json:"currency"`
+	Year           int             `json:"year"`
+	LongTermMonths int             `json:"longTermMonths"`
+	Proceeds       decimal.Decimal `json:"proceeds"`
+	Cost           decimal.Decimal `json:"cost"`
+	Adjustment     decimal.Decimal `json:"washSaleAdjustment"`
+	Gain           decimal.Decimal `json:"gain"`
+	ShortTerm      gainsTotalModel `json:"shortTerm"`
+	LongTerm       gainsTotalModel `json:"longTerm"`
+	Disposals      []disposalModel `json:"disposals"`
+}
+
+func (s *Server) GetGainsHandler(rw http.ResponseWriter, r *http.Request) {
+	u, err := UserFromCtx(r.Context())
+	if err != nil {
+		log.Printf("get gains: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		log.Printf("get gains: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	year := time.Now().Year()
+	if ys := r.URL.Query().Get("year"); ys != "" {
+		if year, err = strconv.Atoi(ys); err != nil {
+			log.Printf("get gains: %v", err)
+			rw.WriteHeader(400)
+			return
+		}
+	}
+	rule := portfolio.DefaultHoldingRule
+	if ms := r.URL.Query().Get("longTermMonths"); ms != "" {
+		if rule.Months, err = strconv.Atoi(ms); err != nil {
+			log.Printf("get gains: %v", err)
+			rw.WriteHeader(400)
+			return
+		}
+	}
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "csv" {
+		log.Printf("get gains: unknown format %q", format)
+		rw.WriteHeader(400)
+		return
+	}
+
+	report, err := s.app.Queries.Gains.Handle(
+		r.Context(),
+		query.Gains{
+			UserID: u.ID,
+			ID:     id,
+			From:   time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC),
+			To:     time.Date(year, 12, 31, 0, 0, 0, 0, time.UTC),
+			Rule:   rule,
+		},
+	)
+	if err != nil {
+		log.Printf("get gains: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	if format == "csv" {
+		rw.Header().Set("Content-Type", "text/csv")
+		rw.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"gains-%d.csv\"", year))
+		if err := writeDisposalsCSV(rw, report.Disposals); err != nil {
+			log.Printf("get gains: %v", err)
+		}
+		return
+	}
+
+	bytes, err := json.Marshal(gainsReportToGainsModel(report, year))
+	if err != nil {
+		log.Printf("get gains: %v", err)
+		rw.WriteHeader(500)
+		return
+	}
+	rw.Header().Set("Content-Type", "application/json")
+	if _, err := rw.Write(bytes); err != nil {
+		log.Printf("get gains: %v", err)
+	}
+}
+
+func writeDisposalsCSV(rw http.ResponseWriter, disposals []portfolio.Disposal) error {
+	w := csv.NewWriter(rw)
+	if err := w.Write([]string{"asset", "currency", "transaction_id", "lot_id", "quantity", "acquired", "sold", "proceeds", "cost", "wash_sale_adjustment", "gain", "term"}); err != nil {
+		return err
+	}
+	for _, d := range disposals {
+		if err := w.Write([]string{
+			d.Asset,
+			d.Currency,
+			d.TransactionID.String(),
+			d.LotID.String(),
+			d.Quantity.String(),
+			d.Acquired.Format("2006-01-02"),
+			d.Sold.Format("2006-01-02"),
+			d.Proceeds.String(),
+			d.Cost.String(),
+			d.Adjustment.String(),
+			d.Gain.String(),
+			string(d.Term),
+		}); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+func gainsReportToGainsModel(report *portfolio.GainsReport, year int) gainsModel {
+	gm := gainsModel{
+		ID:             report.ID.String(),
+		Name:           report.Name,
+		Currency:       report.Currency,
+		Year:           year,
+		LongTermMonths: report.Rule.Months,
+		Proceeds:       report.Proceeds,
+		Cost:           report.Cost,
+		Adjustment:     report.Adjustment,
+		Gain:           report.Gain,
+		ShortTerm:      gainsTotalModel(report.ShortTerm),
+		LongTerm:       gainsTotalModel(report.LongTerm),
+		Disposals:      []disposalModel{},
+	}
+	for _, d := range report.Disposals {
+		gm.Disposals = append(gm.Disposals, disposalModel{
+			Asset:         d.Asset,
+			Currency:      d.Currency,
+			TransactionID: d.TransactionID.String(),
+			LotID:         d.LotID.String(),
+			Quantity:      d.Quantity,
+			Acquired:      d.Acquired,
+			Sold:          d.Sold,
+			Proceeds:      d.Proceeds,
+			Cost:          d.Cost,
+			Adjustment:    d.Adjustment,
+			Gain:          d.Gain,
+			Term:          string(d.Term),
+		})
+	}
+	return gm
+}
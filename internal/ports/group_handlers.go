@@ -0,0 +1,296 @@
+package ports
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/invine/portfolio/internal/app/command"
+	"github.com/invine/portfolio/internal/app/query"
+	"github.com/invine/portfolio/internal/domain/fx"
+	"github.com/invine/portfolio/internal/domain/portfolio"
+)
+
+type groupModel struct {
+	ID           string             `json:"id,omitempty"`
+	Name         string             `json:"name"`
+	ParentID     *string            `json:"parentId,omitempty"`
+	Portfolios   []string           `json:"portfolios"`
+	Groups       []groupModel       `json:"groups,omitempty"`
+	Consolidated *consolidatedModel `json:"consolidated,omitempty"`
+}
+
+func (s *Server) ListGroupsHandler(rw http.ResponseWriter, r *http.Request) {
+	u, err := UserFromCtx(r.Context())
+	if err != nil {
+		log.Printf("list groups: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	groups, err := s.app.Queries.AllGroups.Handle(r.Context(), query.AllGroups{UserID: u.ID})
+	if err != nil {
+		log.Printf("list groups: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	gms := []groupModel{}
+	for _, g := range groups {
+		if g.ParentID() == uuid.Nil {
+			gms = append(gms, groupToGroupModel(g, groups))
+		}
+	}
+	bytes, err := json.Marshal(gms)
+	if err != nil {
+		log.Printf("list groups: %v", err)
+		rw.WriteHeader(500)
+		return
+	}
+	if _, err := rw.Write(bytes); err != nil {
+		log.Printf("list groups: %v", err)
+	}
+}
+
+func (s *Server) AddGroupHandler(rw http.ResponseWriter, r *http.Request) {
+	u, err := UserFromCtx(r.Context())
+	if err != nil {
+		log.Printf("create group: %v", err)
+		rw.WriteHeader(401)
+		return
+	}
+
+	bytes, err := io.ReadAll(r.Body)
+	if err != nil {
+		log.Printf("create group: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+	var gm groupModel
+	if err := json.Unmarshal(bytes, &gm); err != nil {
+		log.Printf("create group: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	parentID := uuid.Nil
+	if gm.ParentID != nil && *gm.ParentID != "" {
+		if parentID, err = uuid.Parse(*gm.ParentID); err != nil {
+			log.Printf("create group: %v", err)
+			rw.WriteHeader(400)
+			return
+		}
+	}
+	portfolios, err := parseIDs(gm.Portfolios)
+	if err != nil {
+		log.Printf("create group: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	cmd := command.CreateGroup{ID: uuid.New(), UserID: u.ID, Name: gm.Name, ParentID: parentID, Portfolios: portfolios}
+	if err := s.app.Commands.CreateGroup.Handle(r.Context(), cmd); err != nil {
+		log.Printf("create group: %v", err)
+		rw.WriteHeader(groupErrorStatus(err, 400))
+		return
+	}
+
+	rw.WriteHeader(201)
+}
+
+func (s *Server) GetGroupHandler(rw http.ResponseWriter, r *http.Request) {
+	u, err := UserFromCtx(r.Context())
+	if err != nil {
+		log.Printf("get group: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		log.Printf("get group: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	currency := r.URL.Query().Get("currency")
+	if currency != "" {
+		if currency, err = fx.ParseCurrency(currency); err != nil {
+			log.Printf("get group: %v", err)
+			rw.WriteHeader(400)
+			return
+		}
+	}
+	t, err := dateFromQuery(r)
+	if err != nil {
+		log.Printf("get group: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+	to, err := dayFromQuery(r, "to", time.Now())
+	if err != nil {
+		log.Printf("get group: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+	from, err := dayFromQuery(r, "from", to.AddDate(-1, 0, 0))
+	if err != nil {
+		log.Printf("get group: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	report, err := s.app.Queries.Group.Handle(
+		r.Context(),
+		query.Group{
+			ID:       id,
+			UserID:   u.ID,
+			Date:     t,
+			From:     from,
+			To:       to,
+			Currency: currency,
+		},
+	)
+	if err != nil {
+		log.Printf("get group: %v", err)
+		rw.WriteHeader(groupErrorStatus(err, 400))
+		return
+	}
+
+	gm := groupToGroupModel(report.Group, report.Subgroups)
+	if report.Consolidated != nil {
+		cm := consolidatedToConsolidatedModel(report.Consolidated)
+		gm.Consolidated = &cm
+	}
+	bytes, err := json.Marshal(gm)
+	if err != nil {
+		log.Printf("get group: %v", err)
+		rw.WriteHeader(500)
+		return
+	}
+	if _, err := rw.Write(bytes); err != nil {
+		log.Printf("get group: %v", err)
+	}
+}
+
+func (s *Server) UpdateGroupHandler(rw http.ResponseWriter, r *http.Request) {
+	u, err := UserFromCtx(r.Context())
+	if err != nil {
+		log.Printf("update group: %v", err)
+		rw.WriteHeader(401)
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		log.Printf("update group: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	bytes, err := io.ReadAll(r.Body)
+	if err != nil {
+		log.Printf("update group: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+	var gm groupModel
+	if err := json.Unmarshal(bytes, &gm); err != nil {
+		log.Printf("update group: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	cmd := command.UpdateGroup{UserID: u.ID, GroupID: id, Name: gm.Name}
+	if gm.ParentID != nil {
+		parentID := uuid.Nil
+		if *gm.ParentID != "" {
+			if parentID, err = uuid.Parse(*gm.ParentID); err != nil {
+				log.Printf("update group: %v", err)
+				rw.WriteHeader(400)
+				return
+			}
+		}
+		cmd.ParentID = &parentID
+	}
+	if gm.Portfolios != nil {
+		if cmd.Portfolios, err = parseIDs(gm.Portfolios); err != nil {
+			log.Printf("update group: %v", err)
+			rw.WriteHeader(400)
+			return
+		}
+	}
+
+	if err := s.app.Commands.UpdateGroup.Handle(r.Context(), cmd); err != nil {
+		log.Printf("update group: %v", err)
+		rw.WriteHeader(groupErrorStatus(err, 400))
+		return
+	}
+
+	rw.WriteHeader(200)
+}
+
+func (s *Server) DeleteGroupHandler(rw http.ResponseWriter, r *http.Request) {
+	u, err := UserFromCtx(r.Context())
+	if err != nil {
+		log.Printf("delete group: %v", err)
+		rw.WriteHeader(401)
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		log.Printf("delete group: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	if err := s.app.Commands.DeleteGroup.Handle(r.Context(), command.DeleteGroup{UserID: u.ID, GroupID: id}); err != nil {
+		log.Printf("delete group: %v", err)
+		rw.WriteHeader(groupErrorStatus(err, 500))
+		return
+	}
+
+	rw.WriteHeader(200)
+}
+
+func groupErrorStatus(err error, fallback int) int {
+	if errors.Is(err, portfolio.ErrGroupNotFound) {
+		return 404
+	}
+	return fallback
+}
+
+func groupToGroupModel(g *portfolio.Group, groups []*portfolio.Group) groupModel {
+	gm := groupModel{ID: g.ID().String(), Name: g.Name(), Portfolios: []string{}}
+	if g.ParentID() != uuid.Nil {
+		parentID := g.ParentID().String()
+		gm.ParentID = &parentID
+	}
+	for _, id := range g.Portfolios() {
+		gm.Portfolios = append(gm.Portfolios, id.String())
+	}
+	for _, child := range groups {
+		if child.ParentID() == g.ID() {
+			gm.Groups = append(gm.Groups, groupToGroupModel(child, groups))
+		}
+	}
+	return gm
+}
+
+func parseIDs(ids []string) ([]uuid.UUID, error) {
+	res := []uuid.UUID{}
+	for _, s := range ids {
+		id, err := uuid.Parse(s)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, id)
+	}
+	return res, nil
+}
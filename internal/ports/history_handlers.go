@@ -0,0 +1,124 @@
+package ports
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/invine/portfolio/internal/app/query"
+	"github.com/invine/portfolio/internal/domain/portfolio"
+	"github.com/shopspring/decimal"
+)
+
+type historyAssetModel struct {
+	Asset    string          `json:"asset"`
+	Quantity decimal.Decimal `json:"quantity"`
+}
+
+type historyPointModel struct {
+	Date        time.Time           `json:"date"`
+	Assets      []historyAssetModel `json:"assets"`
+	Balance     decimal.Decimal     `json:"balance"`
+	Invested    decimal.Decimal     `json:"invested"`
+	MarketValue decimal.Decimal     `json:"marketValue"`
+}
+
+type historyModel struct {
+	ID       string              `json:"id"`
+	Name     string              `json:"name"`
+	Currency string              `json:"currency"`
+	Interval string              `json:"interval"`
+	Points   []historyPointModel `json:"points"`
+}
+
+func (s *Server) GetPortfolioHistoryHandler(rw http.ResponseWriter, r *http.Request) {
+	u, err := UserFromCtx(r.Context())
+	if err != nil {
+		log.Printf("get portfolio history: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		log.Printf("get portfolio history: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	to, err := dayFromQuery(r, "to", time.Now())
+	if err != nil {
+		log.Printf("get portfolio history: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+	from, err := dayFromQuery(r, "from", to.AddDate(-1, 0, 0))
+	if err != nil {
+		log.Printf("get portfolio history: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	interval := portfolio.Day
+	if is := r.URL.Query().Get("interval"); is != "" {
+		interval, err = portfolio.ParsePeriod(is)
+		if err != nil || (interval != portfolio.Day && interval != portfolio.Week && interval != portfolio.Month) {
+			log.Printf("get portfolio history: unsupported interval %s", is)
+			rw.WriteHeader(400)
+			return
+		}
+	}
+
+	h, err := s.app.Queries.History.Handle(
+		r.Context(),
+		query.PortfolioHistory{
+			UserID:   u.ID,
+			ID:       id,
+			From:     from,
+			To:       to,
+			Interval: interval,
+		},
+	)
+	if err != nil {
+		log.Printf("get portfolio history: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	bytes, err := json.Marshal(historyToHistoryModel(h))
+	if err != nil {
+		log.Printf("get portfolio history: %v", err)
+		rw.WriteHeader(500)
+		return
+	}
+	if _, err := rw.Write(bytes); err != nil {
+		log.Printf("get portfolio history: %v", err)
+	}
+}
+
+func historyToHistoryModel(h *portfolio.History) historyModel {
+	hm := historyModel{
+		ID:       h.ID.String(),
+		Name:     h.Name,
+		Currency: h.Currency,
+		Interval: string(h.Interval),
+		Points:   []historyPointModel{},
+	}
+	for _, p := range h.Points {
+		pm := historyPointModel{
+			Date:        p.Date,
+			Assets:      []historyAssetModel{},
+			Balance:     p.Balance,
+			Invested:    p.Invested,
+			MarketValue: p.MarketValue,
+		}
+		for asset, quantity := range p.Assets {
+			pm.Assets = append(pm.Assets, historyAssetModel{Asset: asset, Quantity: quantity})
+		}
+		hm.Points = append(hm.Points, pm)
+	}
+	return hm
+}
@@ -0,0 +1,200 @@
+package ports
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/invine/portfolio/internal/adapters"
+	"github.com/invine/portfolio/internal/app/command"
+	"github.com/invine/portfolio/internal/app/query"
+	"github.com/invine/portfolio/internal/domain/portfolio"
+	"github.com/shopspring/decimal"
+)
+
+const maxImportSize = 10 << 20
+
+type importRowModel struct {
+	Line        int                   `json:"line"`
+	Status      string                `json:"status"`
+	Transaction *transactionModel     `json:"transaction,omitempty"`
+	Action      *corporateActionModel `json:"action,omitempty"`
+	DuplicateOf string                `json:"duplicateOf,omitempty"`
+	Error       string                `json:"error,omitempty"`
+}
+
+type positionCheckModel struct {
+	Asset     string          `json:"asset"`
+	Date      time.Time       `json:"date"`
+	Statement decimal.Decimal `json:"statement"`
+	Holds     decimal.Decimal `json:"holds"`
+	Matches   bool            `json:"matches"`
+}
+
+type importModel struct {
+	ID         string               `json:"id"`
+	Name       string               `json:"name"`
+	Accepted   int                  `json:"accepted"`
+	Duplicates int                  `json:"duplicates"`
+	Rejected   int                  `json:"rejected"`
+	Rows       []importRowModel     `json:"rows"`
+	Positions  []positionCheckModel `json:"positions"`
+}
+
+func (s *Server) PreviewImportHandler(rw http.ResponseWriter, r *http.Request) {
+	u, err := UserFromCtx(r.Context())
+	if err != nil {
+		log.Printf("preview import: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		log.Printf("preview import: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	st, err := readStatement(r)
+	if err != nil {
+		log.Printf("preview import: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	preview, err := s.app.Queries.ImportPreview.Handle(r.Context(), query.ImportPreview{UserID: u.ID, ID: id, Rows: st.Rows, Positions: st.Positions})
+	if err != nil {
+		log.Printf("preview import: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	bytes, err := json.Marshal(importPreviewToImportModel(preview))
+	if err != nil {
+		log.Printf("preview import: %v", err)
+		rw.WriteHeader(500)
+		return
+	}
+	if _, err := rw.Write(bytes); err != nil {
+		log.Printf("preview import: %v", err)
+	}
+}
+
+func (s *Server) ImportHandler(rw http.ResponseWriter, r *http.Request) {
+	u, err := UserFromCtx(r.Context())
+	if err != nil {
+		log.Printf("import transactions: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		log.Printf("import transactions: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	st, err := readStatement(r)
+	if err != nil {
+		log.Printf("import transactions: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	preview, err := s.app.Queries.ImportPreview.Handle(r.Context(), query.ImportPreview{UserID: u.ID, ID: id, Rows: st.Rows, Positions: st.Positions})
+	if err != nil {
+		log.Printf("import transactions: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	cmd := command.ImportTransactions{UserID: u.ID, PortfolioID: id, Transactions: preview.Transactions(), Actions: preview.Actions()}
+	if err := s.app.Commands.ImportTransactions.Handle(r.Context(), cmd); err != nil {
+		log.Printf("import transactions: %v", err)
+		rw.WriteHeader(instrumentErrorStatus(err, 400))
+		return
+	}
+
+	bytes, err := json.Marshal(importPreviewToImportModel(preview))
+	if err != nil {
+		log.Printf("import transactions: %v", err)
+		rw.WriteHeader(500)
+		return
+	}
+	rw.WriteHeader(201)
+	if _, err := rw.Write(bytes); err != nil {
+		log.Printf("import transactions: %v", err)
+	}
+}
+
+func readStatement(r *http.Request) (*portfolio.Statement, error) {
+	if err := r.ParseMultipartForm(maxImportSize); err != nil {
+		return nil, err
+	}
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	format := r.FormValue("format")
+	if format == "" {
+		format = adapters.ImportFormatOf(header.Filename)
+	}
+	if format == "" {
+		return nil, fmt.Errorf("format of %s is unknown", header.Filename)
+	}
+	options := adapters.ImportOptions{DateFormat: r.FormValue("dateFormat")}
+	if m := r.FormValue("mapping"); m != "" {
+		if err := json.Unmarshal([]byte(m), &options.Mapping); err != nil {
+			return nil, fmt.Errorf("incorrect mapping: %w", err)
+		}
+	}
+	return adapters.ReadStatement(file, format, options)
+}
+
+func importPreviewToImportModel(ip *portfolio.ImportPreview) importModel {
+	im := importModel{
+		ID:         ip.ID.String(),
+		Name:       ip.Name,
+		Accepted:   ip.Accepted,
+		Duplicates: ip.Duplicates,
+		Rejected:   ip.Rejected,
+		Rows:       []importRowModel{},
+		Positions:  []positionCheckModel{},
+	}
+	for _, r := range ip.Rows {
+		rm := importRowModel{Line: r.Line, Status: string(r.Status)}
+		if r.Transaction != nil {
+			trm := transactionToTransactionModel(r.Transaction)
+			rm.Transaction = &trm
+		}
+		if r.Action != nil {
+			am := corporateActionToCorporateActionModel(r.Action)
+			rm.Action = &am
+		}
+		if r.DuplicateOf != uuid.Nil {
+			rm.DuplicateOf = r.DuplicateOf.String()
+		}
+		if r.Err != nil {
+			rm.Error = r.Err.Error()
+		}
+		im.Rows = append(im.Rows, rm)
+	}
+	for _, pc := range ip.Positions {
+		im.Positions = append(im.Positions, positionCheckModel{
+			Asset:     pc.Asset,
+			Date:      pc.Date,
+			Statement: pc.Quantity,
+			Holds:     pc.Holds,
+			Matches:   pc.Matches(),
+		})
+	}
+	return im
+}
@@ -0,0 +1,165 @@
+package ports
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/invine/portfolio/internal/app/query"
+	"github.com/invine/portfolio/internal/domain/portfolio"
+	"github.com/shopspring/decimal"
+)
+
+type incomeItemModel struct {
+	TransactionID string          `json:"transactionId"`
+	Date          time.Time       `json:"date"`
+	Kind          string          `json:"kind"`
+	Asset         string          `json:"asset"`
+	Amount        decimal.Decimal `json:"amount"`
+	Currency      string          `json:"currency"`
+}
+
+type assetIncomeModel struct {
+	Asset  string                     `json:"asset"`
+	Total  decimal.Decimal            `json:"total"`
+	ByKind map[string]decimal.Decimal `json:"byKind"`
+}
+
+type periodIncomeModel struct {
+	Period string                     `json:"period"`
+	Start  time.Time                  `json:"start"`
+	End    time.Time                  `json:"end"`
+	Total  decimal.Decimal            `json:"total"`
+	ByKind map[string]decimal.Decimal `json:"byKind"`
+}
+
+type incomeModel struct {
+	ID       string                     `json:"id"`
+	Name     string                     `json:"name"`
+	Currency string                     `json:"currency"`
+	From     time.Time                  `json:"from"`
+	To       time.Time                  `json:"to"`
+	Total    decimal.Decimal            `json:"total"`
+	ByKind   map[string]decimal.Decimal `json:"byKind"`
+	Assets   []assetIncomeModel         `json:"assets"`
+	Periods  []periodIncomeModel        `json:"periods"`
+	Items    []incomeItemModel          `json:"items"`
+}
+
+func (s *Server) GetIncomeHandler(rw http.ResponseWriter, r *http.Request) {
+	u, err := UserFromCtx(r.Context())
+	if err != nil {
+		log.Printf("get income: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		log.Printf("get income: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	to, err := dayFromQuery(r, "to", time.Now())
+	if err != nil {
+		log.Printf("get income: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+	from, err := dayFromQuery(r, "from", to.AddDate(-1, 0, 0))
+	if err != nil {
+		log.Printf("get income: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	period := portfolio.Month
+	if ps := r.URL.Query().Get("period"); ps != "" {
+		period, err = portfolio.ParsePeriod(ps)
+		if err != nil {
+			log.Printf("get income: %v", err)
+			rw.WriteHeader(400)
+			return
+		}
+	}
+
+	report, err := s.app.Queries.Income.Handle(
+		r.Context(),
+		query.Income{
+			UserID: u.ID,
+			ID:     id,
+			From:   from,
+			To:     to,
+			Period: period,
+		},
+	)
+	if err != nil {
+		log.Printf("get income: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	bytes, err := json.Marshal(incomeReportToIncomeModel(report))
+	if err != nil {
+		log.Printf("get income: %v", err)
+		rw.WriteHeader(500)
+		return
+	}
+	if _, err := rw.Write(bytes); err != nil {
+		log.Printf("get income: %v", err)
+	}
+}
+
+func incomeReportToIncomeModel(report *portfolio.IncomeReport) incomeModel {
+	im := incomeModel{
+		ID:       report.ID.String(),
+		Name:     report.Name,
+		Currency: report.Currency,
+		From:     report.From,
+		To:       report.To,
+		Total:    report.Total,
+		ByKind:   byKindToModel(report.ByKind),
+		Assets:   []assetIncomeModel{},
+		Periods:  []periodIncomeModel{},
+		Items:    []incomeItemModel{},
+	}
+	for _, a := range report.Assets {
+		im.Assets = append(im.Assets, assetIncomeModel{
+			Asset:  a.Asset,
+			Total:  a.Total,
+			ByKind: byKindToModel(a.ByKind),
+		})
+	}
+	for _, p := range report.Periods {
+		im.Periods = append(im.Periods, periodIncomeModel{
+			Period: p.Period,
+			Start:  p.Start,
+			End:    p.End,
+			Total:  p.Total,
+			ByKind: byKindToModel(p.ByKind),
+		})
+	}
+	for _, i := range report.Items {
+		im.Items = append(im.Items, incomeItemModel{
+			TransactionID: i.TransactionID.String(),
+			Date:          i.Date,
+			Kind:          string(i.Kind),
+			Asset:         i.Asset,
+			Amount:        i.Amount,
+			Currency:      i.Currency,
+		})
+	}
+	return im
+}
+
+func byKindToModel(byKind map[portfolio.Kind]decimal.Decimal) map[string]decimal.Decimal {
+	res := map[string]decimal.Decimal{}
+	for k, v := range byKind {
+		res[string(k)] = v
+	}
+	return res
+}
@@ -0,0 +1,276 @@
+package ports
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/invine/portfolio/internal/app/command"
+	"github.com/invine/portfolio/internal/app/query"
+	"github.com/invine/portfolio/internal/domain/instrument"
+	"github.com/invine/portfolio/internal/domain/portfolio"
+	"github.com/shopspring/decimal"
+)
+
+type instrumentModel struct {
+	ID        string            `json:"id,omitempty"`
+	Symbol    string            `json:"symbol"`
+	ISIN      string            `json:"isin,omitempty"`
+	Name      string            `json:"name"`
+	Class     string            `json:"class"`
+	Exchange  string            `json:"exchange,omitempty"`
+	Currency  string            `json:"currency,omitempty"`
+	Sector    string            `json:"sector,omitempty"`
+	Country   string            `json:"country,omitempty"`
+	Precision int32             `json:"precision"`
+	Aliases   []string          `json:"aliases"`
+	Tags      map[string]string `json:"tags"`
+	Contract  *contractModel    `json:"contract,omitempty"`
+	Bond      *bondModel        `json:"bond,omitempty"`
+}
+
+type contractModel struct {
+	Underlying string           `json:"underlying"`
+	Multiplier decimal.Decimal  `json:"multiplier"`
+	Right      string           `json:"right,omitempty"`
+	Strike     *decimal.Decimal `json:"strike,omitempty"`
+	Expiry     time.Time        `json:"expiry"`
+}
+
+type bondModel struct {
+	FaceValue  decimal.Decimal `json:"faceValue"`
+	CouponRate decimal.Decimal `json:"couponRate"`
+	Frequency  int             `json:"frequency"`
+	DayCount   string          `json:"dayCount"`
+	Issue      time.Time       `json:"issue"`
+	Maturity   time.Time       `json:"maturity"`
+}
+
+func (s *Server) ListInstrumentsHandler(rw http.ResponseWriter, r *http.Request) {
+	instruments, err := s.app.Queries.AllInstruments.Handle(
+		r.Context(),
+		query.AllInstruments{Code: r.URL.Query().Get("code")},
+	)
+	if err != nil {
+		log.Printf("list instruments: %v", err)
+		rw.WriteHeader(500)
+		return
+	}
+
+	ims := []instrumentModel{}
+	for _, i := range instruments {
+		ims = append(ims, instrumentToInstrumentModel(i))
+	}
+	bytes, err := json.Marshal(ims)
+	if err != nil {
+		log.Printf("list instruments: %v", err)
+		rw.WriteHeader(500)
+		return
+	}
+	if _, err := rw.Write(bytes); err != nil {
+		log.Printf("list instruments: %v", err)
+	}
+}
+
+func (s *Server) GetInstrumentHandler(rw http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		log.Printf("get instrument: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	i, err := s.app.Queries.Instrument.Handle(r.Context(), query.Instrument{ID: id})
+	if err != nil {
+		log.Printf("get instrument: %v", err)
+		rw.WriteHeader(instrumentErrorStatus(err, 500))
+		return
+	}
+
+	bytes, err := json.Marshal(instrumentToInstrumentModel(i))
+	if err != nil {
+		log.Printf("get instrument: %v", err)
+		rw.WriteHeader(500)
+		return
+	}
+	if _, err := rw.Write(bytes); err != nil {
+		log.Printf("get instrument: %v", err)
+	}
+}
+
+func (s *Server) AddInstrumentHandler(rw http.ResponseWriter, r *http.Request) {
+	bytes, err := io.ReadAll(r.Body)
+	if err != nil {
+		log.Printf("add instrument: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	var im instrumentModel
+	if err := json.Unmarshal(bytes, &im); err != nil {
+		log.Printf("add instrument: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	cmd := command.AddInstrument{
+		ID:      uuid.New(),
+		Symbol:  im.Symbol,
+		Name:    im.Name,
+		Class:   instrument.AssetClass(im.Class),
+		Details: instrumentModelToDetails(im),
+	}
+	if err := s.app.Commands.AddInstrument.Handle(r.Context(), cmd); err != nil {
+		log.Printf("add instrument: %v", err)
+		rw.WriteHeader(instrumentErrorStatus(err, 400))
+		return
+	}
+
+	rw.WriteHeader(201)
+}
+
+func (s *Server) UpdateInstrumentHandler(rw http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		log.Printf("update instrument: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	bytes, err := io.ReadAll(r.Body)
+	if err != nil {
+		log.Printf("update instrument: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	var im instrumentModel
+	if err := json.Unmarshal(bytes, &im); err != nil {
+		log.Printf("update instrument: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	cmd := command.UpdateInstrument{
+		ID:      id,
+		Symbol:  im.Symbol,
+		Name:    im.Name,
+		Class:   instrument.AssetClass(im.Class),
+		Details: instrumentModelToDetails(im),
+	}
+	if err := s.app.Commands.UpdateInstrument.Handle(r.Context(), cmd); err != nil {
+		log.Printf("update instrument: %v", err)
+		rw.WriteHeader(instrumentErrorStatus(err, 400))
+		return
+	}
+
+	rw.WriteHeader(200)
+}
+
+func (s *Server) DeleteInstrumentHandler(rw http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		log.Printf("delete instrument: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	if err := s.app.Commands.DeleteInstrument.Handle(r.Context(), command.DeleteInstrument{ID: id}); err != nil {
+		log.Printf("delete instrument: %v", err)
+		rw.WriteHeader(instrumentErrorStatus(err, 500))
+		return
+	}
+
+	rw.WriteHeader(200)
+}
+
+func instrumentErrorStatus(err error, fallback int) int {
+	switch {
+	case errors.Is(err, instrument.ErrInstrumentNotFound):
+		return 404
+	case errors.Is(err, instrument.ErrCodeTaken):
+		return 409
+	}
+	return fallback
+}
+
+func instrumentModelToDetails(im instrumentModel) instrument.Details {
+	d := instrument.Details{
+		ISIN:      im.ISIN,
+		Exchange:  im.Exchange,
+		Currency:  im.Currency,
+		Sector:    im.Sector,
+		Country:   im.Country,
+		Precision: im.Precision,
+		Aliases:   im.Aliases,
+		Tags:      im.Tags,
+	}
+	if im.Contract != nil {
+		d.Contract = portfolio.Contract{
+			Underlying: im.Contract.Underlying,
+			Multiplier: im.Contract.Multiplier,
+			Right:      portfolio.OptionRight(im.Contract.Right),
+			Expiry:     im.Contract.Expiry,
+		}
+		if im.Contract.Strike != nil {
+			d.Contract.Strike = *im.Contract.Strike
+		}
+	}
+	if im.Bond != nil {
+		d.Bond = portfolio.Bond{
+			FaceValue:  im.Bond.FaceValue,
+			CouponRate: im.Bond.CouponRate,
+			Frequency:  im.Bond.Frequency,
+			DayCount:   portfolio.DayCount(im.Bond.DayCount),
+			Issue:      im.Bond.Issue,
+			Maturity:   im.Bond.Maturity,
+		}
+	}
+	return d
+}
+
+func instrumentToInstrumentModel(i *instrument.Instrument) instrumentModel {
+	d := i.Details()
+	im := instrumentModel{
+		ID:        i.ID().String(),
+		Symbol:    i.Symbol(),
+		ISIN:      d.ISIN,
+		Name:      i.Name(),
+		Class:     string(i.Class()),
+		Exchange:  d.Exchange,
+		Currency:  d.Currency,
+		Sector:    d.Sector,
+		Country:   d.Country,
+		Precision: d.Precision,
+		Aliases:   d.Aliases,
+		Tags:      d.Tags,
+	}
+	if i.IsDerivative() {
+		c := d.Contract
+		im.Contract = &contractModel{
+			Underlying: c.Underlying,
+			Multiplier: c.Multiplier,
+			Right:      string(c.Right),
+			Expiry:     c.Expiry,
+		}
+		if c.IsOption() {
+			im.Contract.Strike = &c.Strike
+		}
+	}
+	if b := d.Bond; !b.IsZero() {
+		im.Bond = &bondModel{
+			FaceValue:  b.FaceValue,
+			CouponRate: b.CouponRate,
+			Frequency:  b.Frequency,
+			DayCount:   string(b.DayCount),
+			Issue:      b.Issue,
+			Maturity:   b.Maturity,
+		}
+	}
+	return im
+}
@@ -0,0 +1,95 @@
+package ports
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/invine/portfolio/internal/app/command"
+	"github.com/invine/portfolio/internal/domain/portfolio"
+	"github.com/shopspring/decimal"
+)
+
+type optionSettlementModel struct {
+	Kind     string          `json:"kind"`
+	Symbol   string          `json:"symbol"`
+	Quantity decimal.Decimal `json:"quantity"`
+	Date     time.Time       `json:"date"`
+}
+
+type optionSettlementResultModel struct {
+	SettlementID string `json:"settlementId"`
+	TradeID      string `json:"tradeId,omitempty"`
+}
+
+func (s *Server) SettleOptionHandler(rw http.ResponseWriter, r *http.Request) {
+	u, err := UserFromCtx(r.Context())
+	if err != nil {
+		log.Printf("settle option: %v", err)
+		rw.WriteHeader(401)
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		log.Printf("settle option: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	bytes, err := io.ReadAll(r.Body)
+	if err != nil {
+		log.Printf("settle option: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+	var sm optionSettlementModel
+	if err := json.Unmarshal(bytes, &sm); err != nil {
+		log.Printf("settle option: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+	kind, err := portfolio.ParseKind(sm.Kind)
+	if err != nil {
+		log.Printf("settle option: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	cmd := command.SettleOption{
+		UserID:       u.ID,
+		PortfolioID:  id,
+		SettlementID: uuid.New(),
+		TradeID:      uuid.New(),
+		Settlement: portfolio.OptionSettlement{
+			Date:     sm.Date,
+			Kind:     kind,
+			Asset:    sm.Symbol,
+			Quantity: sm.Quantity,
+		},
+	}
+	if err := s.app.Commands.SettleOption.Handle(r.Context(), cmd); err != nil {
+		log.Printf("settle option: %v", err)
+		rw.WriteHeader(instrumentErrorStatus(err, 400))
+		return
+	}
+
+	res := optionSettlementResultModel{SettlementID: cmd.SettlementID.String()}
+	if kind != portfolio.Expiry {
+		res.TradeID = cmd.TradeID.String()
+	}
+	bytes, err = json.Marshal(res)
+	if err != nil {
+		log.Printf("settle option: %v", err)
+		rw.WriteHeader(500)
+		return
+	}
+	rw.WriteHeader(201)
+	if _, err := rw.Write(bytes); err != nil {
+		log.Printf("settle option: %v", err)
+	}
+}
@@ -0,0 +1,107 @@
+package ports
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/invine/portfolio/internal/app/query"
+	"github.com/invine/portfolio/internal/domain/portfolio"
+	"github.com/shopspring/decimal"
+)
+
+type returnModel struct {
+	Cumulative *float64 `json:"cumulative,omitempty"`
+	Annualized *float64 `json:"annualized,omitempty"`
+}
+
+type performanceModel struct {
+	ID            string          `json:"id,omitempty"`
+	Name          string          `json:"name,omitempty"`
+	Currency      string          `json:"currency"`
+	From          time.Time       `json:"from"`
+	To            time.Time       `json:"to"`
+	StartValue    decimal.Decimal `json:"startValue"`
+	EndValue      decimal.Decimal `json:"endValue"`
+	NetCashFlow   decimal.Decimal `json:"netCashFlow"`
+	TimeWeighted  returnModel     `json:"timeWeighted"`
+	MoneyWeighted returnModel     `json:"moneyWeighted"`
+}
+
+func (s *Server) GetPerformanceHandler(rw http.ResponseWriter, r *http.Request) {
+	u, err := UserFromCtx(r.Context())
+	if err != nil {
+		log.Printf("get performance: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		log.Printf("get performance: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	to, err := dayFromQuery(r, "to", time.Now())
+	if err != nil {
+		log.Printf("get performance: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+	from, err := dayFromQuery(r, "from", to.AddDate(-1, 0, 0))
+	if err != nil {
+		log.Printf("get performance: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	perf, err := s.app.Queries.Performance.Handle(
+		r.Context(),
+		query.Performance{
+			UserID: u.ID,
+			ID:     id,
+			From:   from,
+			To:     to,
+		},
+	)
+	if err != nil {
+		log.Printf("get performance: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	bytes, err := json.Marshal(performanceToPerformanceModel(perf))
+	if err != nil {
+		log.Printf("get performance: %v", err)
+		rw.WriteHeader(500)
+		return
+	}
+	if _, err := rw.Write(bytes); err != nil {
+		log.Printf("get performance: %v", err)
+	}
+}
+
+func performanceToPerformanceModel(p *portfolio.Performance) performanceModel {
+	return performanceModel{
+		ID:          p.ID.String(),
+		Name:        p.Name,
+		Currency:    p.Currency,
+		From:        p.From,
+		To:          p.To,
+		StartValue:  p.StartValue,
+		EndValue:    p.EndValue,
+		NetCashFlow: p.NetCashFlow,
+		TimeWeighted: returnModel{
+			Cumulative: &p.TimeWeighted,
+			Annualized: p.TimeWeightedAnnualized,
+		},
+		MoneyWeighted: returnModel{
+			Cumulative: p.MoneyWeightedCumulative,
+			Annualized: p.MoneyWeighted,
+		},
+	}
+}
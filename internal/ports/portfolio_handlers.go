@@ -2,9 +2,11 @@ package ports
 
 import (
 	"encoding/json"
+	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
@@ -12,25 +14,97 @@ import (
 	"github.com/invine/portfolio/internal/app/command"
 	"github.com/invine/portfolio/internal/app/query"
 	"github.com/invine/portfolio/internal/domain/portfolio"
+	"github.com/shopspring/decimal"
 )
 
 type assetModel struct {
-	Asset    string `json:"asset"`
-	Quantity int    `json:"quantity"`
+	Asset           string           `json:"asset"`
+	Currency        string           `json:"currency,omitempty"`
+	Quantity        decimal.Decimal  `json:"quantity"`
+	AverageCost     decimal.Decimal  `json:"averageCost"`
+	TotalCost       decimal.Decimal  `json:"totalCost"`
+	Price           decimal.Decimal  `json:"price"`
+	MarketValue     decimal.Decimal  `json:"marketValue"`
+	Weight          float64          `json:"weight"`
+	DayChange       decimal.Decimal  `json:"dayChange"`
+	Maturity        *time.Time       `json:"maturity,omitempty"`
+	AccruedInterest *decimal.Decimal `json:"accruedInterest,omitempty"`
+	YieldToMaturity *float64         `json:"yieldToMaturity,omitempty"`
+	// WashSaleAdjustment is included in TotalCost
+	WashSaleAdjustment *decimal.Decimal `json:"washSaleAdjustment,omitempty"`
+	Lots               []lotModel       `json:"lots"`
+}
+
+type derivativeModel struct {
+	assetModel
+	Underlying string           `json:"underlying"`
+	Multiplier decimal.Decimal  `json:"multiplier"`
+	Right      string           `json:"right,omitempty"`
+	Strike     *decimal.Decimal `json:"strike,omitempty"`
+	Expiry     time.Time        `json:"expiry"`
+	Notional   decimal.Decimal  `json:"notional"`
+}
+
+type lotModel struct {
+	TransactionID      string           `json:"transactionId"`
+	Date               time.Time        `json:"date"`
+	Quantity           decimal.Decimal  `json:"quantity"`
+	Price              decimal.Decimal  `json:"price"`
+	WashSaleAdjustment *decimal.Decimal `json:"washSaleAdjustment,omitempty"`
 }
 
 type portfolioModel struct {
-	ID      string       `json:"id"`
-	Name    string       `json:"name"`
-	Assets  []assetModel `json:"assets"`
-	Balance float64      `json:"balance"`
+	ID              string                     `json:"id"`
+	Name            string                     `json:"name"`
+	CostBasisMethod string                     `json:"costBasisMethod,omitempty"`
+	Overdraft       *bool                      `json:"overdraft,omitempty"`
+	ShortSelling    *bool                      `json:"shortSelling,omitempty"`
+	WashSaleRule    *bool                      `json:"washSaleRule,omitempty"`
+	Currency        string                     `json:"currency,omitempty"`
+	Assets          []assetModel               `json:"assets"`
+	Derivatives     []derivativeModel          `json:"derivatives,omitempty"`
+	Notional        *decimal.Decimal           `json:"notional,omitempty"`
+	AccruedInterest *decimal.Decimal           `json:"accruedInterest,omitempty"`
+	Cash            map[string]decimal.Decimal `json:"cash,omitempty"`
+	Balance         decimal.Decimal            `json:"balance"`
+	MarketValue     decimal.Decimal            `json:"marketValue"`
+	DayChange       decimal.Decimal            `json:"dayChange"`
 }
 
 type transactionModel struct {
-	Symbol string    `json:"symbol"`
-	Amount int       `json:"amount"`
-	Date   time.Time `json:"date"`
-	Price  float64   `json:"price"`
+	ID           string              `json:"id,omitempty"`
+	Kind         string              `json:"kind,omitempty"`
+	Symbol       string              `json:"symbol"`
+	Amount       decimal.Decimal     `json:"amount"`
+	Date         time.Time           `json:"date"`
+	Price        decimal.Decimal     `json:"price"`
+	Value        *decimal.Decimal    `json:"value,omitempty"`
+	Currency     string              `json:"currency,omitempty"`
+	InstrumentID string              `json:"instrumentId,omitempty"`
+	Lots         []lotSelectionModel `json:"lots,omitempty"`
+	Fees         []feeModel          `json:"fees,omitempty"`
+	Transfer     *transferLinkModel  `json:"transfer,omitempty"`
+	ReceivedLots []lotModel          `json:"receivedLots,omitempty"`
+	Multiplier   *decimal.Decimal    `json:"multiplier,omitempty"`
+	Premium      *decimal.Decimal    `json:"premium,omitempty"`
+	Accrued      *decimal.Decimal    `json:"accrued,omitempty"`
+	Pending      bool                `json:"pending,omitempty"`
+}
+
+type transferLinkModel struct {
+	PortfolioID   string `json:"portfolioId"`
+	TransactionID string `json:"transactionId"`
+}
+
+type lotSelectionModel struct {
+	TransactionID string          `json:"transactionId"`
+	Quantity      decimal.Decimal `json:"quantity"`
+}
+
+type feeModel struct {
+	Type     string          `json:"type"`
+	Amount   decimal.Decimal `json:"amount"`
+	Currency string          `json:"currency,omitempty"`
 }
 
 func (s *Server) ListPortfoliosHandler(rw http.ResponseWriter, r *http.Request) {
@@ -94,9 +168,14 @@ func (s *Server) AddPortfolioHandler(rw http.ResponseWriter, r *http.Request) {
 	err = s.app.Commands.CreatePortfolio.Handle(
 		r.Context(),
 		command.CreatePortfolio{
-			ID:     uuid.New(),
-			UserID: u.ID,
-			Name:   pm.Name,
+			ID:              uuid.New(),
+			UserID:          u.ID,
+			Name:            pm.Name,
+			CostBasisMethod: portfolio.CostBasisMethod(pm.CostBasisMethod),
+			Overdraft:       pm.Overdraft,
+			ShortSelling:    pm.ShortSelling,
+			WashSaleRule:    pm.WashSaleRule,
+			Currency:        pm.Currency,
 		},
 	)
 	if err != nil {
@@ -123,18 +202,11 @@ func (s *Server) GetPortfolioHandler(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	timeString := r.URL.Query().Get("date")
-	var t time.Time
-	if timeString == "" {
-		t = time.Now()
-	} else {
-		t, err = time.Parse("20060102", timeString)
-		if err != nil {
-			log.Printf("get portfolio: can't parse date %s: %v", timeString, err)
-			rw.WriteHeader(400)
-			return
-		}
-		t = t.AddDate(0, 0, 1)
+	t, err := dateFromQuery(r)
+	if err != nil {
+		log.Printf("get portfolio: %v", err)
+		rw.WriteHeader(400)
+		return
 	}
 
 	snapshot, err := s.app.Queries.Portfolio.Handle(
@@ -152,10 +224,23 @@ func (s *Server) GetPortfolioHandler(rw http.ResponseWriter, r *http.Request) {
 	}
 
 	pm := portfolioModel{
-		ID:      snapshot.ID.String(),
-		Name:    snapshot.Name,
-		Assets:  assetsToAssetsModel(snapshot.Assets),
-		Balance: snapshot.Balance,
+		ID:          snapshot.ID.String(),
+		Name:        snapshot.Name,
+		Currency:    snapshot.Currency,
+		Assets:      assetsToAssetsModel(snapshot.Assets, snapshot.Positions),
+		Cash:        snapshot.Cash,
+		Balance:     snapshot.Balance,
+		MarketValue: snapshot.MarketValue,
+		DayChange:   snapshot.DayChange,
+	}
+	if pm.Derivatives = derivativesToDerivativeModels(snapshot.Assets, snapshot.Positions); len(pm.Derivatives) > 0 {
+		pm.Notional = &snapshot.Notional
+	}
+	for _, p := range snapshot.Positions {
+		if p.Bond != nil && !p.Quantity.IsZero() {
+			pm.AccruedInterest = &snapshot.Accrued
+			break
+		}
 	}
 	bytes, err := json.Marshal(pm)
 	if err != nil {
@@ -169,6 +254,20 @@ func (s *Server) GetPortfolioHandler(rw http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) UpdatePortfolioHandler(rw http.ResponseWriter, r *http.Request) {
+	u, err := UserFromCtx(r.Context())
+	if err != nil {
+		log.Printf("update portfolio: %v", err)
+		rw.WriteHeader(401)
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		log.Printf("update portfolio: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
 	bytes, err := io.ReadAll(r.Body)
 	if err != nil {
 		log.Printf("update portfolio: %v", err)
@@ -184,10 +283,26 @@ func (s *Server) UpdatePortfolioHandler(rw http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	log.Printf("update portfolio: %v", pm)
-	//TODO add command
+	err = s.app.Commands.UpdatePortfolio.Handle(
+		r.Context(),
+		command.UpdatePortfolio{
+			UserID:          u.ID,
+			PortfolioID:     id,
+			Name:            pm.Name,
+			CostBasisMethod: portfolio.CostBasisMethod(pm.CostBasisMethod),
+			Overdraft:       pm.Overdraft,
+			ShortSelling:    pm.ShortSelling,
+			WashSaleRule:    pm.WashSaleRule,
+			Currency:        pm.Currency,
+		},
+	)
+	if err != nil {
+		log.Printf("update portfolio: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
 
-	rw.WriteHeader(501)
+	rw.WriteHeader(200)
 }
 
 func (s *Server) DeletePortfolioHandler(rw http.ResponseWriter, r *http.Request) {
@@ -224,13 +339,7 @@ func (s *Server) AddTransactionHandler(rw http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	tr, err := portfolio.NewTransaction(
-		uuid.New(),
-		trm.Date,
-		trm.Symbol,
-		trm.Amount,
-		trm.Price,
-	)
+	tr, err := transactionModelToTransaction(uuid.New(), trm)
 	if err != nil {
 		log.Printf("add transaction: %v", err)
 		rw.WriteHeader(400)
@@ -247,7 +356,7 @@ func (s *Server) AddTransactionHandler(rw http.ResponseWriter, r *http.Request)
 	)
 	if err != nil {
 		log.Printf("add transaction: %v", err)
-		rw.WriteHeader(500)
+		rw.WriteHeader(instrumentErrorStatus(err, 500))
 		return
 	}
 
@@ -284,12 +393,7 @@ func (s *Server) ListTransactionsHandler(rw http.ResponseWriter, r *http.Request
 	// TODO implement
 	trms := []transactionModel{}
 	for _, t := range trs {
-		trms = append(trms, transactionModel{
-			Symbol: t.Asset(),
-			Amount: t.Quantity(),
-			Date:   t.Date(),
-			Price:  t.Price(),
-		})
+		trms = append(trms, transactionToTransactionModel(t))
 	}
 	bytes, err := json.Marshal(trms)
 	if err != nil {
@@ -313,22 +417,311 @@ func (s *Server) DeleteTransactionHandler(rw http.ResponseWriter, r *http.Reques
 }
 
 func portfolioToPortfolioModel(p *portfolio.Portfolio) portfolioModel {
+	overdraft, shortSelling, washSaleRule := p.Overdraft(), p.ShortSelling(), p.WashSaleRule()
 	pm := portfolioModel{
-		ID:   p.ID().String(),
-		Name: p.Name(),
+		ID:              p.ID().String(),
+		Name:            p.Name(),
+		CostBasisMethod: string(p.CostBasisMethod()),
+		Overdraft:       &overdraft,
+		ShortSelling:    &shortSelling,
+		WashSaleRule:    &washSaleRule,
+		Currency:        p.Currency(),
 	}
 	return pm
 }
 
-func assetsToAssetsModel(assets portfolio.Assets) []assetModel {
+func assetsToAssetsModel(assets portfolio.Assets, positions map[string]*portfolio.Position) []assetModel {
 	res := []assetModel{}
 	for k, v := range assets {
-		if v > 0 {
-			res = append(res, assetModel{
-				Asset:    k,
-				Quantity: v,
-			})
+		if v.IsZero() {
+			continue
+		}
+		p := positions[k]
+		if p != nil && p.Contract != nil {
+			continue
+		}
+		res = append(res, positionToAssetModel(k, v, p))
+	}
+	return res
+}
+
+func derivativesToDerivativeModels(assets portfolio.Assets, positions map[string]*portfolio.Position) []derivativeModel {
+	res := []derivativeModel{}
+	for k, v := range assets {
+		p := positions[k]
+		if v.IsZero() || p == nil || p.Contract == nil {
+			continue
 		}
+		c := p.Contract
+		dm := derivativeModel{
+			assetModel: positionToAssetModel(k, v, p),
+			Underlying: c.Underlying,
+			Multiplier: p.Multiplier,
+			Right:      string(c.Right),
+			Expiry:     c.Expiry,
+			Notional:   p.Notional,
+		}
+		if c.IsOption() {
+			dm.Strike = &c.Strike
+		}
+		res = append(res, dm)
 	}
 	return res
 }
+
+func positionToAssetModel(asset string, quantity decimal.Decimal, p *portfolio.Position) assetModel {
+	am := assetModel{
+		Asset:    asset,
+		Quantity: quantity,
+		Lots:     []lotModel{},
+	}
+	if p == nil {
+		return am
+	}
+	am.Currency = p.Currency
+	am.AverageCost = p.AverageCost
+	am.TotalCost = p.TotalCost
+	am.Price = p.Price
+	am.MarketValue = p.MarketValue
+	am.Weight = p.Weight
+	am.DayChange = p.DayChange
+	if p.Bond != nil {
+		am.Maturity = &p.Bond.Maturity
+		am.AccruedInterest = &p.Accrued
+		am.YieldToMaturity = p.Yield
+	}
+	if !p.WashSaleAdjustment.IsZero() {
+		am.WashSaleAdjustment = &p.WashSaleAdjustment
+	}
+	for _, l := range p.Lots {
+		lm := lotModel{
+			TransactionID: l.TransactionID.String(),
+			Date:          l.Date,
+			Quantity:      l.Quantity,
+			Price:         l.Price,
+		}
+		if !l.Adjustment.IsZero() {
+			adjustment := l.Adjustment
+			lm.WashSaleAdjustment = &adjustment
+		}
+		am.Lots = append(am.Lots, lm)
+	}
+	return am
+}
+
+func dateFromQuery(r *http.Request) (time.Time, error) {
+	timeString := r.URL.Query().Get("date")
+	if timeString == "" {
+		return time.Now(), nil
+	}
+	t, err := time.Parse("20060102", timeString)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("can't parse date %s: %w", timeString, err)
+	}
+	return t.AddDate(0, 0, 1), nil
+}
+
+func dayFromQuery(r *http.Request, name string, fallback time.Time) (time.Time, error) {
+	dayString := r.URL.Query().Get(name)
+	if dayString == "" {
+		return time.Date(fallback.Year(), fallback.Month(), fallback.Day(), 0, 0, 0, 0, time.UTC), nil
+	}
+	t, err := time.Parse("20060102", dayString)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("can't parse %s %s: %w", name, dayString, err)
+	}
+	return t, nil
+}
+
+func pricesFromQuery(r *http.Request) (map[string]decimal.Decimal, error) {
+	prices := map[string]decimal.Decimal{}
+	pricesString := r.URL.Query().Get("prices")
+	if pricesString == "" {
+		return prices, nil
+	}
+	for _, ps := range strings.Split(pricesString, ",") {
+		parts := strings.SplitN(ps, ":", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("can't parse price %s", ps)
+		}
+		price, err := decimal.NewFromString(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("can't parse price %s: %w", ps, err)
+		}
+		prices[parts[0]] = price
+	}
+	return prices, nil
+}
+
+func transactionToTransactionModel(t *portfolio.Transaction) transactionModel {
+	lms := []lotSelectionModel{}
+	for _, l := range t.Lots() {
+		lms = append(lms, lotSelectionModel{TransactionID: l.TransactionID.String(), Quantity: l.Quantity})
+	}
+	trm := transactionModel{
+		ID:       t.ID().String(),
+		Kind:     string(t.Kind()),
+		Symbol:   t.Asset(),
+		Amount:   t.Quantity(),
+		Date:     t.Date(),
+		Price:    t.Price(),
+		Lots:     lms,
+		Fees:     feesToFeeModels(t.Fees()),
+		Currency: t.Currency(),
+		Pending:  t.Pending(),
+	}
+	if t.InstrumentID() != uuid.Nil {
+		trm.InstrumentID = t.InstrumentID().String()
+	}
+	if !t.Kind().IsTrade() && !t.Kind().IsOptionSettlement() && (!t.Kind().IsTransfer() || t.Asset() == "") {
+		value := t.Amount()
+		trm.Value = &value
+	}
+	if multiplier := t.Multiplier(); !multiplier.Equal(decimal.NewFromInt(1)) {
+		trm.Multiplier = &multiplier
+	}
+	if premium := t.Premium(); !premium.IsZero() {
+		trm.Premium = &premium
+	}
+	if accrued := t.Accrued(); !accrued.IsZero() {
+		trm.Accrued = &accrued
+	}
+	if link := t.Transfer(); link.TransactionID != uuid.Nil {
+		trm.Transfer = &transferLinkModel{PortfolioID: link.PortfolioID.String(), TransactionID: link.TransactionID.String()}
+	}
+	for _, l := range t.ReceivedLots() {
+		trm.ReceivedLots = append(trm.ReceivedLots, lotModel{
+			TransactionID: l.TransactionID.String(),
+			Date:          l.Date,
+			Quantity:      l.Quantity,
+			Price:         l.Price,
+		})
+	}
+	return trm
+}
+
+func transactionModelToTransaction(id uuid.UUID, trm transactionModel) (*portfolio.Transaction, error) {
+	// the instrument is resolved by the id as well as by any of its codes
+	if trm.Symbol == "" {
+		trm.Symbol = trm.InstrumentID
+	}
+	kind := portfolio.Buy
+	if trm.Amount.IsNegative() {
+		kind = portfolio.Sell
+	}
+	if trm.Kind != "" {
+		k, err := portfolio.ParseKind(trm.Kind)
+		if err != nil {
+			return nil, err
+		}
+		kind = k
+	}
+	// both sides of a transfer are recorded at once by TransferHandler
+	if kind.IsTransfer() {
+		return nil, fmt.Errorf("transfers can't be added as transactions, use /transfer")
+	}
+	// a settlement records the trade of the underlying along with it
+	if kind.IsOptionSettlement() {
+		return nil, fmt.Errorf("option settlements can't be added as transactions, use /portfolio/{id}/option-settlement")
+	}
+	// redemptions of bonds with known terms are paid at maturity without being recorded
+	if kind == portfolio.Redemption {
+		return nil, fmt.Errorf("redemptions can't be added as transactions, they follow from the terms of the bond")
+	}
+
+	fees, err := feeModelsToFees(trm.Fees)
+	if err != nil {
+		return nil, err
+	}
+
+	if !kind.IsTrade() {
+		value := decimal.Zero
+		if trm.Value != nil {
+			value = *trm.Value
+		}
+		var tr *portfolio.Transaction
+		switch {
+		case kind.IsIncome():
+			tr, err = portfolio.NewIncomeTransaction(id, trm.Date, kind, trm.Symbol, value)
+		case kind == portfolio.BorrowFee:
+			tr, err = portfolio.NewBorrowFee(id, trm.Date, trm.Symbol, value)
+		case kind == portfolio.AccountFee:
+			tr, err = portfolio.NewAccountFee(id, trm.Date, trm.Symbol, value)
+		default:
+			tr, err = portfolio.NewCashTransaction(id, trm.Date, kind, value)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := tr.SetFees(fees); err != nil {
+			return nil, err
+		}
+		if err := tr.SetCurrency(trm.Currency); err != nil {
+			return nil, err
+		}
+		return tr, nil
+	}
+
+	quantity := trm.Amount
+	switch {
+	case kind == portfolio.Sell && quantity.IsPositive():
+		quantity = quantity.Neg()
+	case kind == portfolio.Buy && quantity.IsNegative():
+		return nil, fmt.Errorf("buy amount can't be negative")
+	}
+	tr, err := portfolio.NewTransaction(id, trm.Date, trm.Symbol, quantity, trm.Price)
+	if err != nil {
+		return nil, err
+	}
+	lots, err := lotSelectionModelsToLotSelections(trm.Lots)
+	if err != nil {
+		return nil, err
+	}
+	if err := tr.SelectLots(lots); err != nil {
+		return nil, err
+	}
+	if trm.Accrued != nil {
+		if err := tr.SetAccrued(*trm.Accrued); err != nil {
+			return nil, err
+		}
+	}
+	if err := tr.SetFees(fees); err != nil {
+		return nil, err
+	}
+	if err := tr.SetCurrency(trm.Currency); err != nil {
+		return nil, err
+	}
+	return tr, nil
+}
+
+func feeModelsToFees(fms []feeModel) ([]portfolio.Fee, error) {
+	res := []portfolio.Fee{}
+	for _, fm := range fms {
+		t, err := portfolio.ParseFeeType(fm.Type)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, portfolio.Fee{Type: t, Amount: fm.Amount, Currency: fm.Currency})
+	}
+	return res, nil
+}
+
+func feesToFeeModels(fees []portfolio.Fee) []feeModel {
+	var res []feeModel
+	for _, f := range fees {
+		res = append(res, feeModel{Type: string(f.Type), Amount: f.Amount, Currency: f.Currency})
+	}
+	return res
+}
+
+func lotSelectionModelsToLotSelections(lms []lotSelectionModel) ([]portfolio.LotSelection, error) {
+	res := []portfolio.LotSelection{}
+	for _, lm := range lms {
+		id, err := uuid.Parse(lm.TransactionID)
+		if err != nil {
+			return nil, fmt.Errorf("incorrect lot id %s: %w", lm.TransactionID, err)
+		}
+		res = append(res, portfolio.LotSelection{TransactionID: id, Quantity: lm.Quantity})
+	}
+	return res, nil
+}
@@ -0,0 +1,69 @@
+package ports
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/invine/portfolio/internal/app/command"
+	"github.com/invine/portfolio/internal/app/query"
+)
+
+type precisionModel struct {
+	Asset  string `json:"asset,omitempty"`
+	Places int32  `json:"places"`
+}
+
+func (s *Server) ListPrecisionsHandler(rw http.ResponseWriter, r *http.Request) {
+	precisions, err := s.app.Queries.AllPrecisions.Handle(r.Context(), query.AllPrecisions{})
+	if err != nil {
+		log.Printf("list precisions: %v", err)
+		rw.WriteHeader(500)
+		return
+	}
+
+	pms := []precisionModel{}
+	for asset, places := range precisions {
+		pms = append(pms, precisionModel{Asset: asset, Places: places})
+	}
+	sort.Slice(pms, func(i, j int) bool {
+		return pms[i].Asset < pms[j].Asset
+	})
+	bytes, err := json.Marshal(pms)
+	if err != nil {
+		log.Printf("list precisions: %v", err)
+		rw.WriteHeader(500)
+		return
+	}
+	if _, err := rw.Write(bytes); err != nil {
+		log.Printf("list precisions: %v", err)
+	}
+}
+
+func (s *Server) SetPrecisionHandler(rw http.ResponseWriter, r *http.Request) {
+	bytes, err := io.ReadAll(r.Body)
+	if err != nil {
+		log.Printf("set precision: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	var pm precisionModel
+	if err := json.Unmarshal(bytes, &pm); err != nil {
+		log.Printf("set precision: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	cmd := command.SetPrecision{Asset: chi.URLParam(r, "asset"), Places: pm.Places}
+	if err := s.app.Commands.SetPrecision.Handle(r.Context(), cmd); err != nil {
+		log.Printf("set precision: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	rw.WriteHeader(200)
+}
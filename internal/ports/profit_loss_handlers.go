@@ -0,0 +1,163 @@
+package ports
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/invine/portfolio/internal/app/query"
+	"github.com/invine/portfolio/internal/domain/portfolio"
+	"github.com/shopspring/decimal"
+)
+
+type assetProfitLossModel struct {
+	Asset        string          `json:"asset"`
+	Currency     string          `json:"currency"`
+	Quantity     decimal.Decimal `json:"quantity"`
+	Cost         decimal.Decimal `json:"cost"`
+	Price        decimal.Decimal `json:"price"`
+	MarketValue  decimal.Decimal `json:"marketValue"`
+	Realized     decimal.Decimal `json:"realized"`
+	Unrealized   decimal.Decimal `json:"unrealized"`
+	RealizedFX   decimal.Decimal `json:"realizedFx"`
+	UnrealizedFX decimal.Decimal `json:"unrealizedFx"`
+	Income       decimal.Decimal `json:"income"`
+	BorrowFees   decimal.Decimal `json:"borrowFees"`
+}
+
+type periodProfitLossModel struct {
+	Period     string          `json:"period"`
+	Start      time.Time       `json:"start"`
+	End        time.Time       `json:"end"`
+	Realized   decimal.Decimal `json:"realized"`
+	RealizedFX decimal.Decimal `json:"realizedFx"`
+	Income     decimal.Decimal `json:"income"`
+	BorrowFees decimal.Decimal `json:"borrowFees"`
+}
+
+type profitLossModel struct {
+	ID           string                  `json:"id"`
+	Name         string                  `json:"name"`
+	Currency     string                  `json:"currency"`
+	Realized     decimal.Decimal         `json:"realized"`
+	Unrealized   decimal.Decimal         `json:"unrealized"`
+	RealizedFX   decimal.Decimal         `json:"realizedFx"`
+	UnrealizedFX decimal.Decimal         `json:"unrealizedFx"`
+	Income       decimal.Decimal         `json:"income"`
+	BorrowFees   decimal.Decimal         `json:"borrowFees"`
+	Assets       []assetProfitLossModel  `json:"assets"`
+	Periods      []periodProfitLossModel `json:"periods"`
+}
+
+func (s *Server) GetProfitLossHandler(rw http.ResponseWriter, r *http.Request) {
+	u, err := UserFromCtx(r.Context())
+	if err != nil {
+		log.Printf("get profit and loss: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		log.Printf("get profit and loss: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	t, err := dateFromQuery(r)
+	if err != nil {
+		log.Printf("get profit and loss: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	period := portfolio.Month
+	if ps := r.URL.Query().Get("period"); ps != "" {
+		period, err = portfolio.ParsePeriod(ps)
+		if err != nil {
+			log.Printf("get profit and loss: %v", err)
+			rw.WriteHeader(400)
+			return
+		}
+	}
+
+	prices, err := pricesFromQuery(r)
+	if err != nil {
+		log.Printf("get profit and loss: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	pl, err := s.app.Queries.ProfitLoss.Handle(
+		r.Context(),
+		query.ProfitLoss{
+			UserID: u.ID,
+			ID:     id,
+			Date:   t,
+			Period: period,
+			Prices: prices,
+		},
+	)
+	if err != nil {
+		log.Printf("get profit and loss: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	bytes, err := json.Marshal(profitLossToProfitLossModel(pl))
+	if err != nil {
+		log.Printf("get profit and loss: %v", err)
+		rw.WriteHeader(500)
+		return
+	}
+	if _, err := rw.Write(bytes); err != nil {
+		log.Printf("get profit and loss: %v", err)
+	}
+}
+
+func profitLossToProfitLossModel(pl *portfolio.ProfitLoss) profitLossModel {
+	plm := profitLossModel{
+		ID:           pl.ID.String(),
+		Name:         pl.Name,
+		Currency:     pl.Currency,
+		Realized:     pl.Realized,
+		Unrealized:   pl.Unrealized,
+		RealizedFX:   pl.RealizedFX,
+		UnrealizedFX: pl.UnrealizedFX,
+		Income:       pl.Income,
+		BorrowFees:   pl.BorrowFees,
+		Assets:       []assetProfitLossModel{},
+		Periods:      []periodProfitLossModel{},
+	}
+	for _, a := range pl.Assets {
+		plm.Assets = append(plm.Assets, assetProfitLossModel{
+			Asset:        a.Asset,
+			Currency:     a.Currency,
+			Quantity:     a.Quantity,
+			Cost:         a.Cost,
+			Price:        a.Price,
+			MarketValue:  a.MarketValue,
+			Realized:     a.Realized,
+			Unrealized:   a.Unrealized,
+			RealizedFX:   a.RealizedFX,
+			UnrealizedFX: a.UnrealizedFX,
+			Income:       a.Income,
+			BorrowFees:   a.BorrowFees,
+		})
+	}
+	for _, p := range pl.Periods {
+		plm.Periods = append(plm.Periods, periodProfitLossModel{
+			Period:     p.Period,
+			Start:      p.Start,
+			End:        p.End,
+			Realized:   p.Realized,
+			RealizedFX: p.RealizedFX,
+			Income:     p.Income,
+			BorrowFees: p.BorrowFees,
+		})
+	}
+	return plm
+}
@@ -0,0 +1,380 @@
+package ports
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/invine/portfolio/internal/app/command"
+	"github.com/invine/portfolio/internal/app/query"
+	"github.com/invine/portfolio/internal/domain/portfolio"
+	"github.com/shopspring/decimal"
+)
+
+type targetModel struct {
+	Key    string          `json:"key"`
+	Name   string          `json:"name"`
+	Weight decimal.Decimal `json:"weight"`
+}
+
+type orderFillModel struct {
+	Date     time.Time       `json:"date"`
+	Quantity decimal.Decimal `json:"quantity"`
+	Price    decimal.Decimal `json:"price"`
+	Fees     []feeModel      `json:"fees,omitempty"`
+}
+
+type rebalancePolicyModel struct {
+	Targets   []targetModel   `json:"targets"`
+	Tolerance decimal.Decimal `json:"tolerance"`
+	MinTrade  decimal.Decimal `json:"minTrade"`
+}
+
+type targetDriftModel struct {
+	Key       string          `json:"key"`
+	Name      string          `json:"name"`
+	Target    decimal.Decimal `json:"target"`
+	Value     decimal.Decimal `json:"value"`
+	Weight    float64         `json:"weight"`
+	Drift     float64         `json:"drift"`
+	Rebalance bool            `json:"rebalance"`
+	Unfilled  decimal.Decimal `json:"unfilled"`
+}
+
+type orderModel struct {
+	Kind          string          `json:"kind"`
+	Asset         string          `json:"asset"`
+	Quantity      decimal.Decimal `json:"quantity"`
+	Price         decimal.Decimal `json:"price"`
+	Currency      string          `json:"currency"`
+	Value         decimal.Decimal `json:"value"`
+	TransactionID string          `json:"transactionId,omitempty"`
+}
+
+type rebalanceModel struct {
+	ID        string             `json:"id"`
+	Name      string             `json:"name"`
+	Date      time.Time          `json:"date"`
+	Currency  string             `json:"currency"`
+	Value     decimal.Decimal    `json:"value"`
+	Cash      decimal.Decimal    `json:"cash"`
+	CashAfter decimal.Decimal    `json:"cashAfter"`
+	Tolerance decimal.Decimal    `json:"tolerance"`
+	Targets   []targetDriftModel `json:"targets"`
+	Orders    []orderModel       `json:"orders"`
+}
+
+func (s *Server) GetRebalancePolicyHandler(rw http.ResponseWriter, r *http.Request) {
+	u, err := UserFromCtx(r.Context())
+	if err != nil {
+		log.Printf("get targets: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		log.Printf("get targets: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	policy, err := s.app.Queries.RebalancePolicy.Handle(r.Context(), query.RebalancePolicy{UserID: u.ID, ID: id})
+	if err != nil {
+		log.Printf("get targets: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	pm := rebalancePolicyModel{Targets: []targetModel{}, Tolerance: policy.Tolerance, MinTrade: policy.MinTrade}
+	for _, t := range policy.Targets {
+		pm.Targets = append(pm.Targets, targetModel{Key: string(t.Key), Name: t.Name, Weight: t.Weight})
+	}
+	bytes, err := json.Marshal(pm)
+	if err != nil {
+		log.Printf("get targets: %v", err)
+		rw.WriteHeader(500)
+		return
+	}
+	if _, err := rw.Write(bytes); err != nil {
+		log.Printf("get targets: %v", err)
+	}
+}
+
+func (s *Server) SetRebalancePolicyHandler(rw http.ResponseWriter, r *http.Request) {
+	u, err := UserFromCtx(r.Context())
+	if err != nil {
+		log.Printf("set targets: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		log.Printf("set targets: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	bytes, err := io.ReadAll(r.Body)
+	if err != nil {
+		log.Printf("set targets: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	var pm rebalancePolicyModel
+	if err := json.Unmarshal(bytes, &pm); err != nil {
+		log.Printf("set targets: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	policy := portfolio.RebalancePolicy{Tolerance: pm.Tolerance, MinTrade: pm.MinTrade}
+	for _, tm := range pm.Targets {
+		key, err := portfolio.ParseAllocationKey(tm.Key)
+		if err != nil {
+			log.Printf("set targets: %v", err)
+			rw.WriteHeader(400)
+			return
+		}
+		policy.Targets = append(policy.Targets, portfolio.Target{Key: key, Name: tm.Name, Weight: tm.Weight})
+	}
+
+	cmd := command.SetRebalancePolicy{UserID: u.ID, PortfolioID: id, Policy: policy}
+	if err := s.app.Commands.SetRebalancePolicy.Handle(r.Context(), cmd); err != nil {
+		log.Printf("set targets: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	rw.WriteHeader(200)
+}
+
+func (s *Server) GetRebalanceHandler(rw http.ResponseWriter, r *http.Request) {
+	u, err := UserFromCtx(r.Context())
+	if err != nil {
+		log.Printf("get rebalance: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		log.Printf("get rebalance: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	t, err := dateFromQuery(r)
+	if err != nil {
+		log.Printf("get rebalance: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	rebalance, err := s.app.Queries.Rebalance.Handle(r.Context(), query.Rebalance{UserID: u.ID, ID: id, Date: t})
+	if err != nil {
+		log.Printf("get rebalance: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	bytes, err := json.Marshal(rebalanceToRebalanceModel(rebalance, nil))
+	if err != nil {
+		log.Printf("get rebalance: %v", err)
+		rw.WriteHeader(500)
+		return
+	}
+	if _, err := rw.Write(bytes); err != nil {
+		log.Printf("get rebalance: %v", err)
+	}
+}
+
+func (s *Server) ApplyRebalanceHandler(rw http.ResponseWriter, r *http.Request) {
+	u, err := UserFromCtx(r.Context())
+	if err != nil {
+		log.Printf("apply rebalance: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		log.Printf("apply rebalance: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	now := time.Now()
+	rebalance, err := s.app.Queries.Rebalance.Handle(r.Context(), query.Rebalance{UserID: u.ID, ID: id, Date: now})
+	if err != nil {
+		log.Printf("apply rebalance: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	orders := []*portfolio.Transaction{}
+	transactionIDs := []uuid.UUID{}
+	for _, o := range rebalance.Orders {
+		t, err := o.Transaction(uuid.New(), now)
+		if err != nil {
+			log.Printf("apply rebalance: %v", err)
+			rw.WriteHeader(500)
+			return
+		}
+		orders = append(orders, t)
+		transactionIDs = append(transactionIDs, t.ID())
+	}
+	cmd := command.PlaceOrders{UserID: u.ID, PortfolioID: id, Orders: orders}
+	if err := s.app.Commands.PlaceOrders.Handle(r.Context(), cmd); err != nil {
+		log.Printf("apply rebalance: %v", err)
+		rw.WriteHeader(instrumentErrorStatus(err, 400))
+		return
+	}
+
+	bytes, err := json.Marshal(rebalanceToRebalanceModel(rebalance, transactionIDs))
+	if err != nil {
+		log.Printf("apply rebalance: %v", err)
+		rw.WriteHeader(500)
+		return
+	}
+	rw.WriteHeader(201)
+	if _, err := rw.Write(bytes); err != nil {
+		log.Printf("apply rebalance: %v", err)
+	}
+}
+
+func (s *Server) FillOrderHandler(rw http.ResponseWriter, r *http.Request) {
+	u, err := UserFromCtx(r.Context())
+	if err != nil {
+		log.Printf("fill order: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	var fm orderFillModel
+	bytes, err := io.ReadAll(r.Body)
+	if err != nil {
+		log.Printf("fill order: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+	err = json.Unmarshal(bytes, &fm)
+	if err != nil {
+		log.Printf("fill order: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	portfolioID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		log.Printf("fill order: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+	orderID, err := uuid.Parse(chi.URLParam(r, "orderid"))
+	if err != nil {
+		log.Printf("fill order: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	cmd := command.FillOrder{
+		UserID:      u.ID,
+		PortfolioID: portfolioID,
+		OrderID:     orderID,
+		Date:        fm.Date,
+		Quantity:    fm.Quantity,
+		Price:       fm.Price,
+	}
+	if fm.Fees != nil {
+		if cmd.Fees, err = feeModelsToFees(fm.Fees); err != nil {
+			log.Printf("fill order: %v", err)
+			rw.WriteHeader(400)
+			return
+		}
+	}
+	if err := s.app.Commands.FillOrder.Handle(r.Context(), cmd); err != nil {
+		log.Printf("fill order: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+	rw.WriteHeader(200)
+}
+
+func (s *Server) CancelOrderHandler(rw http.ResponseWriter, r *http.Request) {
+	u, err := UserFromCtx(r.Context())
+	if err != nil {
+		log.Printf("cancel order: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	portfolioID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		log.Printf("cancel order: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+	orderID, err := uuid.Parse(chi.URLParam(r, "orderid"))
+	if err != nil {
+		log.Printf("cancel order: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	cmd := command.CancelOrder{UserID: u.ID, PortfolioID: portfolioID, OrderID: orderID}
+	if err := s.app.Commands.CancelOrder.Handle(r.Context(), cmd); err != nil {
+		log.Printf("cancel order: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+	rw.WriteHeader(200)
+}
+
+func rebalanceToRebalanceModel(r *portfolio.Rebalance, transactionIDs []uuid.UUID) rebalanceModel {
+	rm := rebalanceModel{
+		ID:        r.ID.String(),
+		Name:      r.Name,
+		Date:      r.Date,
+		Currency:  r.Currency,
+		Value:     r.Value,
+		Cash:      r.Cash,
+		CashAfter: r.CashAfter,
+		Tolerance: r.Tolerance,
+		Targets:   []targetDriftModel{},
+		Orders:    []orderModel{},
+	}
+	for _, d := range r.Targets {
+		rm.Targets = append(rm.Targets, targetDriftModel{
+			Key:       string(d.Target.Key),
+			Name:      d.Target.Name,
+			Target:    d.Target.Weight,
+			Value:     d.Value,
+			Weight:    d.Weight,
+			Drift:     d.Drift,
+			Rebalance: d.Rebalance,
+			Unfilled:  d.Unfilled,
+		})
+	}
+	for i, o := range r.Orders {
+		om := orderModel{
+			Kind:     string(o.Kind),
+			Asset:    o.Asset,
+			Quantity: o.Quantity,
+			Price:    o.Price,
+			Currency: o.Currency,
+			Value:    o.Value,
+		}
+		if i < len(transactionIDs) {
+			om.TransactionID = transactionIDs[i].String()
+		}
+		rm.Orders = append(rm.Orders, om)
+	}
+	return rm
+}
@@ -19,10 +19,44 @@ func (s *Server) InitializeRoutes() {
 	s.r.With(s.AuthenticateMiddleware).With(s.SetContentTypeMiddleware).Get("/portfolio/{id}", s.GetPortfolioHandler)
 	s.r.With(s.AuthenticateMiddleware).Post("/portfolio/{id}", s.UpdatePortfolioHandler)
 	s.r.With(s.AuthenticateMiddleware).Delete("/portfolio/{id}", s.DeletePortfolioHandler)
+	s.r.With(s.AuthenticateMiddleware).With(s.SetContentTypeMiddleware).Get("/portfolio/{id}/pnl", s.GetProfitLossHandler)
+	s.r.With(s.AuthenticateMiddleware).With(s.SetContentTypeMiddleware).Get("/portfolio/{id}/history", s.GetPortfolioHistoryHandler)
+	s.r.With(s.AuthenticateMiddleware).With(s.SetContentTypeMiddleware).Get("/portfolio/{id}/performance", s.GetPerformanceHandler)
+	s.r.With(s.AuthenticateMiddleware).With(s.SetContentTypeMiddleware).Get("/portfolio/{id}/income", s.GetIncomeHandler)
+	s.r.With(s.AuthenticateMiddleware).With(s.SetContentTypeMiddleware).Get("/portfolio/{id}/cash", s.GetCashLedgerHandler)
+	s.r.With(s.AuthenticateMiddleware).Get("/portfolio/{id}/reports/gains", s.GetGainsHandler)
+	s.r.With(s.AuthenticateMiddleware).With(s.SetContentTypeMiddleware).Get("/portfolio/{id}/fees", s.GetFeesHandler)
+	s.r.With(s.AuthenticateMiddleware).With(s.SetContentTypeMiddleware).Get("/portfolio/{id}/allocation", s.GetAllocationHandler)
+	s.r.With(s.AuthenticateMiddleware).With(s.SetContentTypeMiddleware).Get("/portfolio/{id}/targets", s.GetRebalancePolicyHandler)
+	s.r.With(s.AuthenticateMiddleware).Post("/portfolio/{id}/targets", s.SetRebalancePolicyHandler)
+	s.r.With(s.AuthenticateMiddleware).With(s.SetContentTypeMiddleware).Get("/portfolio/{id}/rebalance", s.GetRebalanceHandler)
+	s.r.With(s.AuthenticateMiddleware).With(s.SetContentTypeMiddleware).Post("/portfolio/{id}/rebalance", s.ApplyRebalanceHandler)
+	s.r.With(s.AuthenticateMiddleware).Post("/portfolio/{id}/order/{orderid}/fill", s.FillOrderHandler)
+	s.r.With(s.AuthenticateMiddleware).Delete("/portfolio/{id}/order/{orderid}", s.CancelOrderHandler)
 	s.r.With(s.AuthenticateMiddleware).Post("/portfolio/{id}/transaction", s.AddTransactionHandler)
 	s.r.With(s.AuthenticateMiddleware).With(s.SetContentTypeMiddleware).Get("/portfolio/{id}/transaction", s.ListTransactionsHandler)
 	s.r.With(s.AuthenticateMiddleware).Post("/portfolio/{id}/transaction/{transactionid}", s.UpdateTransactionHandler)
 	s.r.With(s.AuthenticateMiddleware).Delete("/portfolio/{id}/transaction/{transactionid}", s.DeleteTransactionHandler)
+	s.r.With(s.AuthenticateMiddleware).With(s.SetContentTypeMiddleware).Post("/portfolio/{id}/import/preview", s.PreviewImportHandler)
+	s.r.With(s.AuthenticateMiddleware).With(s.SetContentTypeMiddleware).Post("/portfolio/{id}/import", s.ImportHandler)
+	s.r.With(s.AuthenticateMiddleware).With(s.SetContentTypeMiddleware).Post("/portfolio/{id}/option-settlement", s.SettleOptionHandler)
+	s.r.With(s.AuthenticateMiddleware).With(s.SetContentTypeMiddleware).Post("/transfer", s.TransferHandler)
+	s.r.With(s.AuthenticateMiddleware).With(s.SetContentTypeMiddleware).Get("/consolidated", s.GetConsolidatedHandler)
+	s.r.With(s.AuthenticateMiddleware).With(s.SetContentTypeMiddleware).Get("/group", s.ListGroupsHandler)
+	s.r.With(s.AuthenticateMiddleware).Post("/group", s.AddGroupHandler)
+	s.r.With(s.AuthenticateMiddleware).With(s.SetContentTypeMiddleware).Get("/group/{id}", s.GetGroupHandler)
+	s.r.With(s.AuthenticateMiddleware).Post("/group/{id}", s.UpdateGroupHandler)
+	s.r.With(s.AuthenticateMiddleware).Delete("/group/{id}", s.DeleteGroupHandler)
+	s.r.With(s.AuthenticateMiddleware).With(s.SetContentTypeMiddleware).Get("/corporate-action", s.ListCorporateActionsHandler)
+	s.r.With(s.AuthenticateMiddleware).With(s.AdminMiddleware).Post("/corporate-action", s.AddCorporateActionHandler)
+	s.r.With(s.AuthenticateMiddleware).With(s.AdminMiddleware).Delete("/corporate-action/{id}", s.DeleteCorporateActionHandler)
+	s.r.With(s.AuthenticateMiddleware).With(s.SetContentTypeMiddleware).Get("/asset-precision", s.ListPrecisionsHandler)
+	s.r.With(s.AuthenticateMiddleware).With(s.AdminMiddleware).Post("/asset-precision/{asset}", s.SetPrecisionHandler)
+	s.r.With(s.AuthenticateMiddleware).With(s.SetContentTypeMiddleware).Get("/instrument", s.ListInstrumentsHandler)
+	s.r.With(s.AuthenticateMiddleware).With(s.AdminMiddleware).Post("/instrument", s.AddInstrumentHandler)
+	s.r.With(s.AuthenticateMiddleware).With(s.SetContentTypeMiddleware).Get("/instrument/{id}", s.GetInstrumentHandler)
+	s.r.With(s.AuthenticateMiddleware).With(s.AdminMiddleware).Post("/instrument/{id}", s.UpdateInstrumentHandler)
+	s.r.With(s.AuthenticateMiddleware).With(s.AdminMiddleware).Delete("/instrument/{id}", s.DeleteInstrumentHandler)
 	s.r.Post("/signin", s.UserSignInHandler)
 	s.r.Post("/signup", s.UserSignUpHandler)
 }
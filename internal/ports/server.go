@@ -12,14 +12,19 @@ type Server struct {
 	app     app.Application
 	userSvc *app.UserService
 	key     []byte
+	admins  map[string]bool
 }
 
-func NewServer(userSvc *app.UserService, app app.Application, key []byte) *Server {
+func NewServer(userSvc *app.UserService, app app.Application, key []byte, admins []string) *Server {
 	s := &Server{
 		r:       chi.NewRouter(),
 		app:     app,
 		userSvc: userSvc,
 		key:     key,
+		admins:  map[string]bool{},
+	}
+	for _, login := range admins {
+		s.admins[login] = true
 	}
 	return s
 }
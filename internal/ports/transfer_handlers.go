@@ -0,0 +1,105 @@
+package ports
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/invine/portfolio/internal/app/command"
+	"github.com/invine/portfolio/internal/domain/portfolio"
+	"github.com/shopspring/decimal"
+)
+
+type transferModel struct {
+	From     string              `json:"from"`
+	To       string              `json:"to"`
+	Date     time.Time           `json:"date"`
+	Symbol   string              `json:"symbol,omitempty"`
+	Quantity decimal.Decimal     `json:"quantity"`
+	Price    decimal.Decimal     `json:"price"`
+	Value    decimal.Decimal     `json:"value"`
+	Currency string              `json:"currency,omitempty"`
+	Lots     []lotSelectionModel `json:"lots,omitempty"`
+}
+
+type transferResultModel struct {
+	OutID string `json:"outId"`
+	InID  string `json:"inId"`
+}
+
+func (s *Server) TransferHandler(rw http.ResponseWriter, r *http.Request) {
+	u, err := UserFromCtx(r.Context())
+	if err != nil {
+		log.Printf("transfer: %v", err)
+		rw.WriteHeader(401)
+		return
+	}
+
+	bytes, err := io.ReadAll(r.Body)
+	if err != nil {
+		log.Printf("transfer: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+	var tm transferModel
+	if err := json.Unmarshal(bytes, &tm); err != nil {
+		log.Printf("transfer: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	from, err := uuid.Parse(tm.From)
+	if err != nil {
+		log.Printf("transfer: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+	to, err := uuid.Parse(tm.To)
+	if err != nil {
+		log.Printf("transfer: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+	lots, err := lotSelectionModelsToLotSelections(tm.Lots)
+	if err != nil {
+		log.Printf("transfer: %v", err)
+		rw.WriteHeader(400)
+		return
+	}
+
+	cmd := command.Transfer{
+		UserID:          u.ID,
+		FromPortfolioID: from,
+		ToPortfolioID:   to,
+		OutID:           uuid.New(),
+		InID:            uuid.New(),
+		Transfer: portfolio.Transfer{
+			Date:     tm.Date,
+			Asset:    tm.Symbol,
+			Quantity: tm.Quantity,
+			Price:    tm.Price,
+			Amount:   tm.Value,
+			Currency: tm.Currency,
+			Lots:     lots,
+		},
+	}
+	if err := s.app.Commands.Transfer.Handle(r.Context(), cmd); err != nil {
+		log.Printf("transfer: %v", err)
+		rw.WriteHeader(instrumentErrorStatus(err, 400))
+		return
+	}
+
+	bytes, err = json.Marshal(transferResultModel{OutID: cmd.OutID.String(), InID: cmd.InID.String()})
+	if err != nil {
+		log.Printf("transfer: %v", err)
+		rw.WriteHeader(500)
+		return
+	}
+	rw.WriteHeader(201)
+	if _, err := rw.Write(bytes); err != nil {
+		log.Printf("transfer: %v", err)
+	}
+}
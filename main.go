@@ -5,11 +5,13 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strings"
 
 	"github.com/invine/portfolio/internal/adapters"
 	"github.com/invine/portfolio/internal/app"
 	"github.com/invine/portfolio/internal/app/command"
 	"github.com/invine/portfolio/internal/app/query"
+	"github.com/invine/portfolio/internal/domain/price"
 	"github.com/invine/portfolio/internal/ports"
 	_ "github.com/mattn/go-sqlite3"
 )
@@ -26,12 +28,21 @@ func main() {
 	db_path := getenv("DB_PATH", ".")
 	port := getenv("PORT", "3001")
 	key := []byte(getenv("JWT_KEY", "34$FtGVP*8Uzhp"))
+	admins := strings.FieldsFunc(getenv("ADMINS", ""), func(r rune) bool { return r == ',' || r == ' ' })
 
 	db_conn := fmt.Sprintf("%s/db.sqlite3", db_path)
 	db, err := sql.Open("sqlite3", db_conn)
 	if err != nil {
 		panic(err)
 	}
+
+	if len(os.Args) > 1 {
+		if err := runCommand(db, os.Args[1], os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	userRepo, err := adapters.NewSQLiteUsersRepository(db)
 	if err != nil {
 		panic(err)
@@ -46,7 +57,12 @@ func main() {
 		panic(err)
 	}
 
-	applyTransactionHandler, err := command.NewApplyTransactionHandler(portfolioRepo)
+	instrumentRepo, err := adapters.NewSQLiteInstrumentRepository(db)
+	if err != nil {
+		panic(err)
+	}
+
+	applyTransactionHandler, err := command.NewApplyTransactionHandler(portfolioRepo, instrumentRepo)
 	if err != nil {
 		panic(err)
 	}
@@ -58,6 +74,30 @@ func main() {
 	if err != nil {
 		panic(err)
 	}
+	updatePortfolioHandler, err := command.NewUpdatePortfolioHandler(portfolioRepo)
+	if err != nil {
+		panic(err)
+	}
+	transferHandler, err := command.NewTransferHandler(portfolioRepo, instrumentRepo)
+	if err != nil {
+		panic(err)
+	}
+	settleOptionHandler, err := command.NewSettleOptionHandler(portfolioRepo, instrumentRepo)
+	if err != nil {
+		panic(err)
+	}
+	placeOrdersHandler, err := command.NewPlaceOrdersHandler(portfolioRepo, instrumentRepo)
+	if err != nil {
+		panic(err)
+	}
+	fillOrderHandler, err := command.NewFillOrderHandler(portfolioRepo)
+	if err != nil {
+		panic(err)
+	}
+	cancelOrderHandler, err := command.NewCancelOrderHandler(portfolioRepo)
+	if err != nil {
+		panic(err)
+	}
 	allPortfoliosHandler, err := query.NewAllPortfoliosHandler(portfolioRepo)
 	if err != nil {
 		panic(err)
@@ -66,7 +106,179 @@ func main() {
 	if err != nil {
 		panic(err)
 	}
-	portfolioSnapshotHandler, err := query.NewPortfolioHandler(portfolioRepo)
+	priceRepo, err := adapters.NewSQLitePriceRepository(db)
+	if err != nil {
+		panic(err)
+	}
+	var priceProvider price.PriceProvider = priceRepo
+	if pricesPath := getenv("PRICES_PATH", ""); pricesPath != "" {
+		priceProvider, err = adapters.NewFilePriceProvider(pricesPath)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	rateRepo, err := adapters.NewSQLiteRateRepository(db)
+	if err != nil {
+		panic(err)
+	}
+
+	portfolioSnapshotHandler, err := query.NewPortfolioHandler(portfolioRepo, priceProvider, priceRepo, rateRepo)
+	if err != nil {
+		panic(err)
+	}
+	profitLossHandler, err := query.NewProfitLossHandler(portfolioRepo, rateRepo)
+	if err != nil {
+		panic(err)
+	}
+	historyHandler, err := query.NewPortfolioHistoryHandler(portfolioRepo, priceRepo, rateRepo)
+	if err != nil {
+		panic(err)
+	}
+	performanceHandler, err := query.NewPerformanceHandler(portfolioRepo, priceRepo, rateRepo)
+	if err != nil {
+		panic(err)
+	}
+	incomeHandler, err := query.NewIncomeHandler(portfolioRepo, rateRepo)
+	if err != nil {
+		panic(err)
+	}
+	gainsHandler, err := query.NewGainsHandler(portfolioRepo, rateRepo)
+	if err != nil {
+		panic(err)
+	}
+
+	cashLedgerHandler, err := query.NewCashLedgerHandler(portfolioRepo)
+	if err != nil {
+		panic(err)
+	}
+
+	feesHandler, err := query.NewFeesHandler(portfolioRepo, rateRepo)
+	if err != nil {
+		panic(err)
+	}
+
+	allocationHandler, err := query.NewAllocationHandler(portfolioRepo, priceProvider, priceRepo, rateRepo, instrumentRepo)
+	if err != nil {
+		panic(err)
+	}
+
+	consolidatedHandler, err := query.NewConsolidatedHandler(portfolioRepo, priceProvider, priceRepo, rateRepo)
+	if err != nil {
+		panic(err)
+	}
+
+	importPreviewHandler, err := query.NewImportPreviewHandler(portfolioRepo, instrumentRepo)
+	if err != nil {
+		panic(err)
+	}
+
+	setRebalancePolicyHandler, err := command.NewSetRebalancePolicyHandler(portfolioRepo, instrumentRepo)
+	if err != nil {
+		panic(err)
+	}
+
+	rebalancePolicyHandler, err := query.NewRebalancePolicyHandler(portfolioRepo)
+	if err != nil {
+		panic(err)
+	}
+
+	rebalanceHandler, err := query.NewRebalanceHandler(portfolioRepo, priceProvider, priceRepo, rateRepo, instrumentRepo)
+	if err != nil {
+		panic(err)
+	}
+
+	groupRepo, err := adapters.NewSQLiteGroupRepository(db)
+	if err != nil {
+		panic(err)
+	}
+
+	createGroupHandler, err := command.NewCreateGroupHandler(groupRepo, portfolioRepo)
+	if err != nil {
+		panic(err)
+	}
+
+	updateGroupHandler, err := command.NewUpdateGroupHandler(groupRepo, portfolioRepo)
+	if err != nil {
+		panic(err)
+	}
+
+	deleteGroupHandler, err := command.NewDeleteGroupHandler(groupRepo)
+	if err != nil {
+		panic(err)
+	}
+
+	allGroupsHandler, err := query.NewAllGroupsHandler(groupRepo)
+	if err != nil {
+		panic(err)
+	}
+
+	groupHandler, err := query.NewGroupHandler(groupRepo, portfolioRepo, priceProvider, priceRepo, rateRepo)
+	if err != nil {
+		panic(err)
+	}
+
+	corporateActionRepo, err := adapters.NewSQLiteCorporateActionRepository(db)
+	if err != nil {
+		panic(err)
+	}
+
+	addCorporateActionHandler, err := command.NewAddCorporateActionHandler(corporateActionRepo)
+	if err != nil {
+		panic(err)
+	}
+
+	deleteCorporateActionHandler, err := command.NewDeleteCorporateActionHandler(corporateActionRepo)
+	if err != nil {
+		panic(err)
+	}
+
+	importTransactionsHandler, err := command.NewImportTransactionsHandler(portfolioRepo, instrumentRepo)
+	if err != nil {
+		panic(err)
+	}
+
+	allCorporateActionsHandler, err := query.NewAllCorporateActionsHandler(corporateActionRepo)
+	if err != nil {
+		panic(err)
+	}
+
+	precisionRepo, err := adapters.NewSQLitePrecisionRepository(db)
+	if err != nil {
+		panic(err)
+	}
+
+	setPrecisionHandler, err := command.NewSetPrecisionHandler(precisionRepo)
+	if err != nil {
+		panic(err)
+	}
+
+	allPrecisionsHandler, err := query.NewAllPrecisionsHandler(precisionRepo)
+	if err != nil {
+		panic(err)
+	}
+
+	addInstrumentHandler, err := command.NewAddInstrumentHandler(instrumentRepo)
+	if err != nil {
+		panic(err)
+	}
+
+	updateInstrumentHandler, err := command.NewUpdateInstrumentHandler(instrumentRepo)
+	if err != nil {
+		panic(err)
+	}
+
+	deleteInstrumentHandler, err := command.NewDeleteInstrumentHandler(instrumentRepo)
+	if err != nil {
+		panic(err)
+	}
+
+	allInstrumentsHandler, err := query.NewAllInstrumentsHandler(instrumentRepo)
+	if err != nil {
+		panic(err)
+	}
+
+	instrumentHandler, err := query.NewInstrumentHandler(instrumentRepo)
 	if err != nil {
 		panic(err)
 	}
@@ -76,15 +288,61 @@ func main() {
 			ApplyTransaction: *applyTransactionHandler,
 			CreatePortfolio:  *createPortfolioHandler,
 			DeletePortfolio:  *deletePortfolioHandler,
+			UpdatePortfolio:  *updatePortfolioHandler,
+			Transfer:         *transferHandler,
+			SettleOption:     *settleOptionHandler,
+			PlaceOrders:      *placeOrdersHandler,
+			FillOrder:        *fillOrderHandler,
+			CancelOrder:      *cancelOrderHandler,
+
+			ImportTransactions: *importTransactionsHandler,
+
+			SetRebalancePolicy: *setRebalancePolicyHandler,
+
+			CreateGroup: *createGroupHandler,
+			UpdateGroup: *updateGroupHandler,
+			DeleteGroup: *deleteGroupHandler,
+
+			AddCorporateAction:    *addCorporateActionHandler,
+			DeleteCorporateAction: *deleteCorporateActionHandler,
+
+			SetPrecision: *setPrecisionHandler,
+
+			AddInstrument:    *addInstrumentHandler,
+			UpdateInstrument: *updateInstrumentHandler,
+			DeleteInstrument: *deleteInstrumentHandler,
 		},
 		Queries: app.Queries{
 			AllPortfolios:   *allPortfoliosHandler,
 			AllTransactions: *AllTransactionsHandler,
 			Portfolio:       *portfolioSnapshotHandler,
+			ProfitLoss:      *profitLossHandler,
+			History:         *historyHandler,
+			Performance:     *performanceHandler,
+			Income:          *incomeHandler,
+			Gains:           *gainsHandler,
+			CashLedger:      *cashLedgerHandler,
+			Fees:            *feesHandler,
+			Allocation:      *allocationHandler,
+			Consolidated:    *consolidatedHandler,
+			ImportPreview:   *importPreviewHandler,
+
+			RebalancePolicy: *rebalancePolicyHandler,
+			Rebalance:       *rebalanceHandler,
+
+			AllGroups: *allGroupsHandler,
+			Group:     *groupHandler,
+
+			AllCorporateActions: *allCorporateActionsHandler,
+
+			AllPrecisions: *allPrecisionsHandler,
+
+			AllInstruments: *allInstrumentsHandler,
+			Instrument:     *instrumentHandler,
 		},
 	}
 
-	s := ports.NewServer(userService, app, key)
+	s := ports.NewServer(userService, app, key, admins)
 	s.InitializeRoutes()
 
 	log.Printf("Starting server on %s...", port)
@@ -11,7 +11,36 @@ CREATE TABLE IF NOT EXISTS portfolios
 (
     id text not null primary key,
     userid text not null,
-    name text
+    name text,
+    costbasis text not null default 'fifo',
+    overdraft integer not null default 1,
+    shortselling integer not null default 0,
+    washsalerule integer not null default 0,
+    currency text not null default 'USD',
+    tolerance text not null default '0',
+    mintrade text not null default '0'
+);
+CREATE TABLE IF NOT EXISTS portfolio_targets
+(
+    portfolioid text not null,
+    key text not null,
+    name text not null,
+    weight text not null,
+    position integer not null,
+    primary key(portfolioid, key, name)
+);
+CREATE TABLE IF NOT EXISTS portfolio_groups
+(
+    id text not null primary key,
+    userid text not null,
+    name text not null,
+    parentid text not null default ''
+);
+CREATE TABLE IF NOT EXISTS portfolio_group_members
+(
+    portfolioid text not null primary key,
+    groupid text not null,
+    position integer not null
 );
 CREATE TABLE IF NOT EXISTS transactions
 (
@@ -19,7 +48,113 @@ CREATE TABLE IF NOT EXISTS transactions
     userid text not null,
     portfolioid text not null,
     date text not null,
+    kind text not null default '',
+    asset text not null,
+    price text not null,
+    quantity text not null,
+    amount text not null default '0',
+    currency text not null default '',
+    instrumentid text not null default '',
+    multiplier text not null default '1',
+    premium text not null default '0',
+    accrued text not null default '0',
+    transferportfolioid text not null default '',
+    transferid text not null default ''
+);
+
+CREATE TABLE IF NOT EXISTS transaction_lots
+(
+    transactionid text not null,
+    lotid text not null,
+    quantity text not null,
+    date text not null default '',
+    price text not null default '0',
+    primary key(transactionid, lotid)
+);
+CREATE TABLE IF NOT EXISTS transaction_fees
+(
+    transactionid text not null,
+    position integer not null,
+    type text not null,
+    amount text not null,
+    currency text not null default '',
+    primary key(transactionid, position)
+);
+CREATE TABLE IF NOT EXISTS prices
+(
+    asset text not null primary key,
+    date text not null,
+    close real not null,
+    previousclose real not null default 0
+);
+CREATE TABLE IF NOT EXISTS price_history
+(
+    asset text not null,
+    date text not null,
+    close real not null,
+    currency text not null default '',
+    source text not null default '',
+    primary key(asset, date)
+);
+CREATE TABLE IF NOT EXISTS corporate_actions
+(
+    id text not null primary key,
+    date text not null,
+    type text not null,
     asset text not null,
-    price real not null,
-    quantity integer not null
+    newasset text not null default '',
+    ratiofrom integer not null default 1,
+    ratioto integer not null default 1,
+    costallocation text not null default '0',
+    cashprice text not null default '0'
+);
+CREATE TABLE IF NOT EXISTS asset_precisions
+(
+    asset text not null primary key,
+    places integer not null
+);
+CREATE TABLE IF NOT EXISTS fx_rates
+(
+    base text not null,
+    quote text not null,
+    date text not null,
+    rate text not null,
+    source text not null default '',
+    primary key(base, quote, date)
+);
+CREATE TABLE IF NOT EXISTS instruments
+(
+    id text not null primary key,
+    symbol text not null unique,
+    isin text not null default '',
+    name text not null default '',
+    class text not null default 'other',
+    exchange text not null default '',
+    currency text not null default '',
+    sector text not null default '',
+    country text not null default '',
+    places integer not null default 0,
+    underlying text not null default '',
+    multiplier text not null default '0',
+    optionright text not null default '',
+    strike text not null default '0',
+    expiry text not null default '',
+    facevalue text not null default '0',
+    couponrate text not null default '0',
+    frequency integer not null default 0,
+    daycount text not null default '',
+    issue text not null default '',
+    maturity text not null default ''
+);
+CREATE TABLE IF NOT EXISTS instrument_aliases
+(
+    alias text not null primary key,
+    instrumentid text not null
+);
+CREATE TABLE IF NOT EXISTS instrument_tags
+(
+    instrumentid text not null,
+    name text not null,
+    value text not null,
+    primary key(instrumentid, name)
 );